@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/brandonyoungdev/tldx/internal/userconfig"
@@ -31,15 +35,131 @@ const configTemplate = `# tldx configuration
 # limit = 0
 # only_available = true
 
+# Stop checking a TLD, or a keyword, once it has this many available domains,
+# so one TLD cannot use up the whole limit.
+# limit_per_tld = 3
+# limit_per_keyword = 5
+
+# The order lookups start in, so that limit stops after the best names:
+# input, shortest-first, score-first, tld-priority or random.
+# order = "score-first"
+
 # Check taken domains for an RFC 10023 "_for-sale" record. Costs one extra DNS
 # query per taken domain. only_for_sale implies for_sale.
 # for_sale = true
 # only_for_sale = true
 
+# Ask each TLD's authoritative nameservers before RDAP. Delegated names are
+# marked taken straight away; only undelegated ones go on to RDAP.
+# dns_prescreen = true
+
+# Send DNS lookups to this server instead of the system resolver, e.g. when
+# the local one makes up answers for unregistered names. Accepts host[:port]
+# (UDP), tcp://host, tls://host (DNS-over-TLS) or an https:// DoH URL.
+# dns_server = "https://cloudflare-dns.com/dns-query"
+
+# Ask RDAP, DNS delegation and WHOIS in parallel for every domain and report
+# whether they agree. Slower, but flags names the sources disagree on.
+# verify = true
+
+# Ask the registrar below for the price of each available domain, and hide
+# available domains costing more than max_price. max_price implies pricing.
+# Prices quoted in a currency other than max_price_currency do not fit.
+# pricing = true
+# max_price = 20.0
+# max_price_currency = "USD"
+
 # show_stats = true
 # no_color = false
 # verbose = false
 
+# Availability backends tried for each domain, in order. The first one with
+# a verdict wins. "default" covers every TLD without a line of its own, and
+# --backend replaces all of these for one run.
+# Backends: rdap, dns, whois, dns-delegation, registrar, epp.
+# [backends]
+# default = ["rdap", "dns", "whois"]
+# de = ["whois", "dns"]
+
+# Registrar API used by pricing and the "registrar" backend.
+#   porkbun: api_key, api_secret
+#   namecom: username, api_key (an API token)
+#   godaddy: api_key, api_secret
+# endpoint replaces the API base URL, e.g. for a sandbox.
+# [registrar]
+# provider = "porkbun"
+# api_key = "pk1_..."
+# api_secret = "sk1_..."
+
+# Registry EPP server used by the "epp" backend. Needs registrar credentials
+# with the registry; most also require the client certificate. max_check is
+# the server's limit of names per <check> command (default 5).
+# [epp]
+# server = "epp.registry.example:700"
+# username = "registrar-id"
+# password = "..."
+# cert_file = "/path/to/client.crt"
+# key_file = "/path/to/client.key"
+# max_check = 5
+
+# Network settings shared by RDAP, its bootstrap, WHOIS, DoH and registrar
+# lookups. Without proxy, HTTP_PROXY/HTTPS_PROXY/NO_PROXY are honored; --proxy
+# wins over proxy. WHOIS cannot go through an HTTP proxy: it uses whois_proxy,
+# else a socks5:// proxy, else ALL_PROXY. ca_file is trusted on top of the
+# system roots. Durations are strings such as "90s".
+# [transport]
+# proxy = "http://proxy.corp.example:3128"
+# whois_proxy = "socks5://proxy.corp.example:1080"
+# ca_file = "/etc/ssl/corp-root.pem"
+# user_agent = "tldx (ops@example.com)"
+# max_idle_conns = 100
+# max_idle_conns_per_host = 10
+# max_conns_per_host = 0
+# idle_conn_timeout = "90s"
+# http2_keepalive = "30s"
+
+# Retries, timeouts and parallelism. initial_backoff is the upper bound of the
+# first randomized wait; each retry multiplies it by backoff_factor, up to
+# max_backoff. timeout covers one domain, retries included.
+# adaptive_concurrency halves the parallel lookups while more than a fifth
+# fail, then adds them back one at a time. [resolver.tld.<tld>] overrides any
+# of the keys for one TLD; its concurrency caps that TLD within the total.
+# [resolver]
+# retries = 3
+# initial_backoff = "1.5s"
+# max_backoff = "5s"
+# backoff_factor = 1.5
+# timeout = "15s"
+# concurrency = 15
+# adaptive_concurrency = false
+#
+# [resolver.tld.de]
+# concurrency = 2
+# timeout = "30s"
+
+# Names RDAP reports as free that a registry will not sell. tldx ships lists
+# of ICANN-reserved names, name collision blocks and country names; files add
+# your own, one glob per line (see the README for the format).
+# [reserved]
+# files = ["/etc/tldx/reserved.txt"]
+# builtin = true
+# disabled = false
+
+# Imported zone files, for the "zone" backend (see "tldx zone import").
+# confirm asks RDAP about names missing from a zone before calling them free.
+# [zone]
+# dir = "/var/lib/tldx/zones"
+# confirm = false
+
+# Remember domains found taken, and skip them in later runs (--prefilter).
+# defer checks them after everything else instead. A new size or rate starts
+# an empty filter.
+# [prefilter]
+# enabled = false
+# mode = "skip"
+# size = 1000000
+# false_positive_rate = 0.01
+
 # Custom presets, usable via --tld-preset <name>.
 # Add them here by hand or with "tldx preset add <name> <tld>...".
 # [presets.nordic]
@@ -109,6 +229,43 @@ func newConfigShowCmd() *cobra.Command {
 				}
 			}
 
+			if backends := describeBackends(cfg.Backends); len(backends) > 0 {
+				cmd.Println("\nBackends:")
+				for _, line := range backends {
+					cmd.Printf("  %s\n", line)
+				}
+			}
+
+			if resolverLines := describeResolver(cfg.Resolver); len(resolverLines) > 0 {
+				cmd.Println("\nResolver:")
+				for _, line := range resolverLines {
+					cmd.Printf("  %s\n", line)
+				}
+			}
+
+			if reservedLine := describeReserved(cfg.Reserved); reservedLine != "" {
+				cmd.Printf("\nReserved lists: %s\n", reservedLine)
+			}
+
+			if cfg.Zone.Dir != "" {
+				cmd.Printf("\nZone directory: %s\n", cfg.Zone.Dir)
+			}
+			if cfg.Prefilter.Enabled {
+				cmd.Printf("\nPrefilter: on (%s)\n", cmp.Or(cfg.Prefilter.Mode, "defer"))
+			}
+
+			if cfg.Registrar.Provider != "" {
+				// Never print the credentials themselves.
+				cmd.Printf("\nRegistrar: %s\n", cfg.Registrar.Provider)
+			}
+			if cfg.Transport.Proxy != "" || cfg.Transport.WhoisProxy != "" {
+				// Proxy URLs may carry credentials.
+				cmd.Printf("Proxy: %s\n", describeProxy(cfg.Transport))
+			}
+			if cfg.EPP.Server != "" {
+				cmd.Printf("EPP server: %s (user %s)\n", cfg.EPP.Server, cfg.EPP.Username)
+			}
+
 			cmd.Printf("\nCustom presets: %d (run \"tldx preset list\" to see them)\n", len(cfg.Presets))
 			return nil
 		},
@@ -175,6 +332,15 @@ func describeDefaults(d userconfig.Defaults) []string {
 	if d.Limit != nil {
 		add("limit", fmt.Sprintf("%d", *d.Limit))
 	}
+	if d.LimitPerTLD != nil {
+		add("limit_per_tld", fmt.Sprintf("%d", *d.LimitPerTLD))
+	}
+	if d.LimitPerKeyword != nil {
+		add("limit_per_keyword", fmt.Sprintf("%d", *d.LimitPerKeyword))
+	}
+	if d.Order != "" {
+		add("order", d.Order)
+	}
 	if d.OnlyAvailable {
 		add("only_available", "true")
 	}
@@ -184,6 +350,24 @@ func describeDefaults(d userconfig.Defaults) []string {
 	if d.OnlyForSale {
 		add("only_for_sale", "true")
 	}
+	if d.DNSPrescreen {
+		add("dns_prescreen", "true")
+	}
+	if d.Verify {
+		add("verify", "true")
+	}
+	if d.DNSServer != "" {
+		add("dns_server", d.DNSServer)
+	}
+	if d.Pricing {
+		add("pricing", "true")
+	}
+	if d.MaxPrice != nil {
+		add("max_price", strconv.FormatFloat(*d.MaxPrice, 'f', -1, 64))
+	}
+	if d.MaxPriceCurrency != "" {
+		add("max_price_currency", d.MaxPriceCurrency)
+	}
 	if d.ShowStats {
 		add("show_stats", "true")
 	}
@@ -196,3 +380,111 @@ func describeDefaults(d userconfig.Defaults) []string {
 
 	return out
 }
+
+// describeBackends lists the default chain first, then TLDs alphabetically.
+func describeBackends(b userconfig.Backends) []string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		if key != userconfig.DefaultBackendsKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := b[userconfig.DefaultBackendsKey]; ok {
+		keys = append([]string{userconfig.DefaultBackendsKey}, keys...)
+	}
+
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		label := key
+		if key != userconfig.DefaultBackendsKey && !strings.HasPrefix(key, ".") {
+			label = "." + key
+		}
+		out = append(out, fmt.Sprintf("%-18s %s", label, strings.Join(b[key], ", ")))
+	}
+	return out
+}
+
+// describeResolver lists the global tuning, then per-TLD overrides
+// alphabetically.
+func describeResolver(r userconfig.Resolver) []string {
+	var out []string
+	add := func(name string, t userconfig.ResolverTuning) {
+		var parts []string
+		if t.Retries != nil {
+			parts = append(parts, fmt.Sprintf("retries=%d", *t.Retries))
+		}
+		if t.InitialBackoff != nil {
+			parts = append(parts, "initial_backoff="+t.InitialBackoff.String())
+		}
+		if t.MaxBackoff != nil {
+			parts = append(parts, "max_backoff="+t.MaxBackoff.String())
+		}
+		if t.BackoffFactor != nil {
+			parts = append(parts, "backoff_factor="+strconv.FormatFloat(*t.BackoffFactor, 'f', -1, 64))
+		}
+		if t.Timeout != nil {
+			parts = append(parts, "timeout="+t.Timeout.String())
+		}
+		if t.Concurrency != nil {
+			parts = append(parts, fmt.Sprintf("concurrency=%d", *t.Concurrency))
+		}
+		if len(parts) > 0 {
+			out = append(out, fmt.Sprintf("%-18s %s", name, strings.Join(parts, ", ")))
+		}
+	}
+
+	add("default", r.ResolverTuning)
+	if r.AdaptiveConcurrency {
+		out = append(out, fmt.Sprintf("%-18s %s", "adaptive", "true"))
+	}
+
+	tlds := make([]string, 0, len(r.TLD))
+	for tld := range r.TLD {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+	for _, tld := range tlds {
+		add("."+strings.TrimPrefix(tld, "."), r.TLD[tld])
+	}
+	return out
+}
+
+// describeReserved names the lists that will be checked, or "" when the
+// section only repeats the defaults.
+func describeReserved(r userconfig.Reserved) string {
+	if r.Disabled {
+		return "disabled"
+	}
+	var lists []string
+	if r.Builtin == nil || *r.Builtin {
+		if len(r.Files) == 0 {
+			return ""
+		}
+		lists = append(lists, "built-in")
+	}
+	lists = append(lists, r.Files...)
+	if len(lists) == 0 {
+		return "none"
+	}
+	return strings.Join(lists, ", ")
+}
+
+// describeProxy names the configured proxies with any password redacted.
+func describeProxy(t userconfig.Transport) string {
+	redact := func(spec string) string {
+		if u, err := url.Parse(spec); err == nil {
+			return u.Redacted()
+		}
+		return spec
+	}
+
+	var parts []string
+	if t.Proxy != "" {
+		parts = append(parts, redact(t.Proxy))
+	}
+	if t.WhoisProxy != "" {
+		parts = append(parts, "WHOIS via "+redact(t.WhoisProxy))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -240,3 +241,81 @@ func TestDefaults_ForSaleOffByDefault(t *testing.T) {
 		t.Errorf("for-sale must stay off unless configured, got %+v", app.Config)
 	}
 }
+
+func TestDefaults_BackendChainsApplyToRun(t *testing.T) {
+	app := configuredApp(t, "[backends]\ndefault = [\"rdap\", \"whois\"]\n\".DE\" = [\"whois\", \"dns\"]\n", "acme", "--dry-run")
+
+	if !reflect.DeepEqual(app.Config.Backends, []string{"rdap", "whois"}) {
+		t.Errorf("expected the default chain to apply, got %v", app.Config.Backends)
+	}
+	if !reflect.DeepEqual(app.Config.TLDBackends, map[string][]string{"de": {"whois", "dns"}}) {
+		t.Errorf("expected a normalized .de chain, got %v", app.Config.TLDBackends)
+	}
+}
+
+func TestDefaults_BackendFlagReplacesConfiguredChains(t *testing.T) {
+	app := configuredApp(t, "[backends]\ndefault = [\"rdap\"]\nde = [\"whois\"]\n", "acme", "--dry-run", "--backend", "dns,rdap")
+
+	if !reflect.DeepEqual(app.Config.Backends, []string{"dns", "rdap"}) {
+		t.Errorf("expected --backend to win, got %v", app.Config.Backends)
+	}
+	if len(app.Config.TLDBackends) != 0 {
+		t.Errorf("expected --backend to drop per-TLD chains, got %v", app.Config.TLDBackends)
+	}
+}
+
+func TestDefaults_UnknownBackendIsRejected(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		args    []string
+	}{
+		"flag":    {"", []string{"acme", "--dry-run", "--backend", "rdap,carrier-pigeon"}},
+		"per-tld": {"[backends]\nde = [\"carrier-pigeon\"]\n", []string{"acme", "--dry-run"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			t.Setenv("TLDX_CONFIG", path)
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			root := cmd.NewRootCmd(config.NewTldxContext())
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+			root.SetArgs(tc.args)
+
+			err := root.ExecuteContext(context.Background())
+			if err == nil || !strings.Contains(err.Error(), "carrier-pigeon") {
+				t.Errorf("expected the unknown backend to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDefaults_MaxPriceImpliesPricing(t *testing.T) {
+	app := configuredApp(t, "[defaults]\nmax_price = 20.0\n\n[registrar]\nprovider = \"porkbun\"\napi_key = \"pk\"\napi_secret = \"sk\"\n",
+		"acme", "--dry-run")
+
+	if app.Config.MaxPrice != 20 || !app.Config.CheckPricing {
+		t.Errorf("expected max_price to apply and imply pricing, got %+v", app.Config)
+	}
+	if app.Config.Registrar.Provider != "porkbun" || app.Config.Registrar.APISecret != "sk" {
+		t.Errorf("expected the registrar section to apply, got %+v", app.Config.Registrar)
+	}
+}
+
+func TestDefaults_PricingWithoutARegistrarIsRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("TLDX_CONFIG", path)
+
+	root := cmd.NewRootCmd(config.NewTldxContext())
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"acme", "--dry-run", "--pricing"})
+
+	if err := root.ExecuteContext(context.Background()); err == nil {
+		t.Fatal("expected --pricing without a [registrar] section to be rejected")
+	}
+}
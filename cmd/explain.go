@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/brandonyoungdev/tldx/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+func NewExplainCmd() *cobra.Command {
+	app := config.NewTldxContext()
+	// Built in PreRunE from what validateLookups parsed, used in RunE.
+	var lookupOpts []resolver.ResolverOption
+
+	cmd := &cobra.Command{
+		Use:   "explain <domain>",
+		Short: "Show step by step how the verdict for one domain is reached",
+		Long: "Check one domain and print every step the lookup took: the backend chain, the RDAP server\n" +
+			"bootstrap chose, each request with its status and latency, retries and their backoff, DNS and\n" +
+			"WHOIS fallbacks, the for-sale lookup, and which source decided the verdict.\n\n" +
+			"The config file applies as it does to a normal run.",
+		Example: "  tldx explain acme.com\n  tldx explain acme.io --verify --format json",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			userCfg, err := userconfig.Load()
+			if err != nil {
+				slog.Warn("Could not load user config", "error", err)
+				userCfg = &userconfig.UserConfig{}
+			}
+			applyUserConfig(app.Config, userCfg, cmd.Flags().Changed)
+			if app.Config.MaxPrice > 0 {
+				app.Config.CheckPricing = true
+			}
+			// A default format from the config file is for result lists.
+			if !cmd.Flags().Changed("format") && app.Config.OutputFormat != "json" {
+				app.Config.OutputFormat = "text"
+			}
+
+			switch app.Config.OutputFormat {
+			case "text", "json":
+			default:
+				return fmt.Errorf("invalid format %q for explain: use text or json", app.Config.OutputFormat)
+			}
+			lookupOpts, err = validateLookups(app.Config)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fixtureOpts, finish, err := fixtureOptions(app.Config)
+			if err != nil {
+				slog.Error("Failed to set up fixtures", "error", err)
+				return err
+			}
+			opts := append(lookupOpts, fixtureOpts...)
+
+			domain := strings.ToLower(strings.TrimSpace(args[0]))
+			result, trace := explainDomain(cmd.Context(), app, domain, opts...)
+
+			if app.Config.OutputFormat == "json" {
+				err = writeExplainJSON(cmd.OutOrStdout(), result, trace)
+			} else {
+				writeExplainText(cmd.OutOrStdout(), app, result, trace)
+			}
+			if err != nil {
+				return err
+			}
+			return finish()
+		},
+	}
+
+	cfg := app.Config
+	cmd.Flags().StringVarP(&cfg.OutputFormat, "format", "f", "text", "Format of the trace (text, json)")
+	cmd.Flags().StringSliceVar(&cfg.Backends, "backend", nil, "Availability backends to try in order (e.g. rdap,dns,whois)")
+	cmd.Flags().BoolVar(&cfg.Verify, "verify", false, "Ask RDAP, DNS delegation and WHOIS in parallel and report how far they agree")
+	cmd.Flags().BoolVar(&cfg.CheckForSale, "for-sale", false, "Check a taken domain for an RFC 10023 _for-sale TXT record")
+	cmd.Flags().BoolVar(&cfg.CheckPricing, "pricing", false, "Look up the registration price if the domain is available")
+	cmd.Flags().StringVar(&cfg.DNSServer, "dns-server", "", "DNS server for all lookups: host[:port], tcp://host, tls://host or an https:// DoH URL")
+	cmd.Flags().StringSliceVar(&cfg.Reserved.Files, "reserved-list", nil, "Extra reserved-name list files, checked after the built-in lists")
+	cmd.Flags().BoolVar(&cfg.Reserved.Disabled, "no-reserved", false, "Do not check a free domain against reserved-name lists")
+	cmd.Flags().StringVar(&cfg.ReplayDir, "replay", "", "Answer lookups from a --record directory instead of the network")
+	cmd.Flags().BoolVar(&cfg.NoColor, "no-color", false, "Disable colored output")
+	return cmd
+}
+
+func explainDomain(ctx context.Context, app *config.TldxContext, domain string, opts ...resolver.ResolverOption) (resolver.DomainResult, *resolver.Trace) {
+	resolverService := resolver.NewResolverService(app, opts...)
+	defer resolverService.Close()
+
+	trace := resolver.NewTrace()
+	// The domain's TLD may be tuned, as it is for a normal run.
+	ctx, cancel := context.WithTimeout(resolver.WithTrace(ctx, trace), resolverService.LookupTimeout(domain))
+	defer cancel()
+
+	checkResult, err := resolverService.CheckDomain(ctx, domain)
+	return resolver.DomainResult{
+		Domain:     domain,
+		Available:  checkResult.Available(),
+		Details:    checkResult.Details,
+		Error:      err,
+		ForSale:    checkResult.ForSale,
+		Source:     checkResult.Source,
+		Pricing:    checkResult.Pricing,
+		Reserved:   checkResult.Reserved,
+		Confidence: checkResult.Confidence,
+		Verdicts:   checkResult.Verdicts,
+	}, trace
+}
+
+func writeExplainText(w io.Writer, app *config.TldxContext, result resolver.DomainResult, trace *resolver.Trace) {
+	fmt.Fprintf(w, "Explaining %s\n\n", result.Domain)
+
+	for _, step := range trace.Steps() {
+		source := step.Source
+		if source == "" {
+			source = "-"
+		}
+		line := fmt.Sprintf("%8s  %-15s %-11s %s", formatMillis(step.At), source, step.Event, step.Detail)
+		if step.Duration > 0 {
+			line += fmt.Sprintf(" (%s)", formatMillis(step.Duration))
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if step.Err != nil {
+			fmt.Fprintf(w, "%8s  %-15s %-11s %s\n", "", "", "", "error: "+step.Err.Error())
+		}
+	}
+
+	// Verbose, so the verdict line carries its details.
+	verbose := *app
+	cfg := *app.Config
+	cfg.Verbose = true
+	verbose.Config = &cfg
+
+	line, _ := output.NewStyleService(&verbose).Render(result)
+	source := result.Source
+	if source == "" {
+		source = "no source"
+	}
+	fmt.Fprintf(w, "\nVerdict (%s):\n%s\n", source, line)
+}
+
+// explainStep is a trace step in the JSON output, with times in milliseconds.
+type explainStep struct {
+	AtMS       float64 `json:"at_ms"`
+	Source     string  `json:"source,omitempty"`
+	Event      string  `json:"event"`
+	Detail     string  `json:"detail,omitempty"`
+	DurationMS float64 `json:"duration_ms,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type explainReport struct {
+	resolver.EncodableDomainResult
+	Steps []explainStep `json:"steps"`
+}
+
+func writeExplainJSON(w io.Writer, result resolver.DomainResult, trace *resolver.Trace) error {
+	report := explainReport{EncodableDomainResult: result.AsEncodable(), Steps: []explainStep{}}
+	for _, step := range trace.Steps() {
+		s := explainStep{
+			AtMS:       millis(step.At),
+			Source:     step.Source,
+			Event:      step.Event,
+			Detail:     step.Detail,
+			DurationMS: millis(step.Duration),
+		}
+		if step.Err != nil {
+			s.Error = step.Err.Error()
+		}
+		report.Steps = append(report.Steps, s)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Round(10*time.Microsecond)) / float64(time.Millisecond)
+}
+
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
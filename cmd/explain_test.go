@@ -0,0 +1,88 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/cmd"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/fixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWhoisFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	fixtures := `{"acme.com": {"response": "Domain Name: ACME.COM\r\nRegistrar: Example Registrar, Inc.\r\n"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "whois.json"), []byte(fixtures), 0o644))
+	return dir
+}
+
+func TestExplainCommand_JSON(t *testing.T) {
+	dir := writeWhoisFixture(t)
+
+	var out bytes.Buffer
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"explain", "acme.com", "--backend", "whois", "--replay", dir, "-f", "json"})
+
+	require.NoError(t, rootCmd.Execute())
+
+	var report struct {
+		Domain    string `json:"domain"`
+		Available bool   `json:"available"`
+		Source    string `json:"source"`
+		Steps     []struct {
+			Source string `json:"source"`
+			Event  string `json:"event"`
+			Detail string `json:"detail"`
+		} `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "acme.com", report.Domain)
+	assert.False(t, report.Available)
+	assert.Equal(t, "whois", report.Source)
+
+	require.NotEmpty(t, report.Steps)
+	assert.Equal(t, "chain", report.Steps[0].Event)
+	assert.Equal(t, "whois", report.Steps[0].Detail)
+	last := report.Steps[len(report.Steps)-1]
+	assert.Equal(t, "verdict", last.Event)
+	assert.Equal(t, "whois", last.Source)
+}
+
+func TestExplainCommand_Text(t *testing.T) {
+	dir := writeWhoisFixture(t)
+
+	var out bytes.Buffer
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"explain", "acme.com", "--backend", "whois", "--replay", dir, "--no-color"})
+
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, out.String(), "Explaining acme.com")
+	assert.Contains(t, out.String(), "Verdict (whois)")
+}
+
+func TestExplainCommand_RejectsListFormats(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"explain", "acme.com", "-f", "csv"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "use text or json")
+}
+
+func TestExplainCommand_ReplayMiss(t *testing.T) {
+	dir := writeWhoisFixture(t)
+
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"explain", "other.com", "--backend", "whois", "--replay", dir})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorIs(t, rootCmd.Execute(), fixture.ErrNotRecorded)
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"os"
 
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/logging"
 	"github.com/brandonyoungdev/tldx/internal/mcpserver"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 )
 
-func NewMCPCmd(version string) *cobra.Command {
-	return &cobra.Command{
+// NewMCPCmd returns the mcp command. Its logs go to a file at the level and
+// in the format of logOpts, since stdout carries the protocol.
+func NewMCPCmd(version string, logOpts *config.LogOptions) *cobra.Command {
+	var telemetryOpts config.TelemetryOptions
+	var logFile string
+	cmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "Start an MCP (Model Context Protocol) server over stdio",
 		Long: `Start a Model Context Protocol (MCP) server that exposes tldx
@@ -31,11 +37,51 @@ once enough available domains are found.
 
 Your custom TLD presets and [defaults] from the tldx config file apply here too.
 
-Configure your MCP client to run: tldx mcp`,
+Configure your MCP client to run: tldx mcp
+
+--metrics-addr and --otlp-endpoint report the lookups of every call, for
+monitoring a long-lived server.
+
+Logs are appended to --log-file, by default mcp.log in tldx's cache directory
+or $TLDX_MCP_LOG, at the --log-level and in the --log-format given.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			closeLog, err := startMCPLog(logFile, *logOpts)
+			if err != nil {
+				return err
+			}
+			defer closeLog()
+
+			stopTelemetry, err := startTelemetry(cmd.Context(), telemetryOpts)
+			if err != nil {
+				return err
+			}
+			defer stopTelemetry()
 			return runMCPServer(cmd.Context(), version)
 		},
 	}
+	bindTelemetryFlags(cmd.Flags(), &telemetryOpts)
+	cmd.Flags().StringVar(&logFile, "log-file", "", "File to append the server's logs to (default: mcp.log in the cache directory, or $TLDX_MCP_LOG)")
+	return cmd
+}
+
+// startMCPLog points the default logger at path, or logging.DefaultMCPPath
+// when it is empty, and returns the function that closes the file.
+func startMCPLog(path string, opts config.LogOptions) (func(), error) {
+	if path == "" {
+		var err error
+		if path, err = logging.DefaultMCPPath(); err != nil {
+			return nil, err
+		}
+	}
+	f, err := logging.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.Setup(f, opts); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() { f.Close() }, nil
 }
 
 func runMCPServer(ctx context.Context, version string) error {
@@ -3,22 +3,29 @@ package cmd_test
 import (
 	"context"
 	"io"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/brandonyoungdev/tldx/cmd"
+	"github.com/brandonyoungdev/tldx/internal/config"
 )
 
 // The stdio server reads os.Stdin, so this only asserts that running the
 // command hands off to it and returns once the context is done.
 func TestMCPCmd_StopsWithTheContext(t *testing.T) {
-	t.Setenv("TLDX_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	dir := t.TempDir()
+	t.Setenv("TLDX_CONFIG", filepath.Join(dir, "config.toml"))
+	t.Setenv("TLDX_MCP_LOG", filepath.Join(dir, "logs", "mcp.log"))
+
+	defer slog.SetDefault(slog.Default())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	mcpCmd := cmd.NewMCPCmd("test")
+	mcpCmd := cmd.NewMCPCmd("test", &config.LogOptions{})
 	mcpCmd.SetArgs([]string{})
 	mcpCmd.SetOut(io.Discard)
 	mcpCmd.SetErr(io.Discard)
@@ -31,4 +38,7 @@ func TestMCPCmd_StopsWithTheContext(t *testing.T) {
 	case <-time.After(10 * time.Second):
 		t.Fatal("mcp command did not return after its context was cancelled")
 	}
+	if _, err := os.Stat(filepath.Join(dir, "logs", "mcp.log")); err != nil {
+		t.Errorf("expected the server to log to $TLDX_MCP_LOG: %v", err)
+	}
 }
@@ -13,7 +13,7 @@ import (
 // The server itself is tested in internal/mcpserver; this covers the cobra wiring.
 
 func TestNewMCPCmd_Structure(t *testing.T) {
-	mcpCmd := cmd.NewMCPCmd("v1.0.0")
+	mcpCmd := cmd.NewMCPCmd("v1.0.0", &config.LogOptions{})
 	require.NotNil(t, mcpCmd)
 	assert.Equal(t, "mcp", mcpCmd.Use)
 	assert.NotEmpty(t, mcpCmd.Short)
@@ -21,7 +21,7 @@ func TestNewMCPCmd_Structure(t *testing.T) {
 }
 
 func TestNewMCPCmd_LongDescribesBothTools(t *testing.T) {
-	long := cmd.NewMCPCmd("v1.0.0").Long
+	long := cmd.NewMCPCmd("v1.0.0", &config.LogOptions{}).Long
 
 	assert.Contains(t, long, "check_domains")
 	assert.Contains(t, long, "generate_and_check")
@@ -4,11 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"slices"
+	"sort"
 
+	"github.com/brandonyoungdev/tldx/internal/composer"
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/domain"
+	"github.com/brandonyoungdev/tldx/internal/epp"
+	"github.com/brandonyoungdev/tldx/internal/fixture"
 	"github.com/brandonyoungdev/tldx/internal/input"
+	"github.com/brandonyoungdev/tldx/internal/logging"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/prefilter"
 	"github.com/brandonyoungdev/tldx/internal/presets"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/brandonyoungdev/tldx/internal/transport"
+	"github.com/brandonyoungdev/tldx/internal/tui"
 	"github.com/brandonyoungdev/tldx/internal/userconfig"
 	"github.com/spf13/cobra"
 )
@@ -30,6 +44,8 @@ func NewRootCmd(app *config.TldxContext) *cobra.Command {
 `
 	// Loaded in PersistentPreRunE, read back in PreRunE.
 	userCfg := &userconfig.UserConfig{}
+	// Built in PreRunE from what validateLookups parsed, used in RunE.
+	var lookupOpts []resolver.ResolverOption
 
 	cmd := &cobra.Command{
 		Use:          "tldx [keywords]",
@@ -39,6 +55,14 @@ func NewRootCmd(app *config.TldxContext) *cobra.Command {
 		Args:         cobra.MinimumNArgs(0),
 		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			logOpts := app.Config.Log
+			if app.Config.Verbose && !cmd.Flags().Changed("log-level") {
+				logOpts.Level = "debug"
+			}
+			if err := logging.Setup(os.Stderr, logOpts); err != nil {
+				return err
+			}
+
 			cfg, err := userconfig.Load()
 			if err != nil {
 				slog.Warn("Could not load user config", "error", err)
@@ -51,22 +75,66 @@ func NewRootCmd(app *config.TldxContext) *cobra.Command {
 			return nil
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			userCfg.Defaults.ApplyTo(app.Config, cmd.Flags().Changed)
+			applyUserConfig(app.Config, userCfg, cmd.Flags().Changed)
 
 			if app.Config.MaxDomainLength <= 0 {
 				slog.Error("Invalid max-domain-length provided. Pick a positive number please.")
 				return fmt.Errorf("invalid max-domain-length: must be a positive number")
 			}
 			if app.Config.OutputFormat == "" {
-				if app.Config.Verbose {
-					fmt.Println("Unknown output format. Defaulting to text.")
-				}
+				slog.Debug("No output format given, defaulting to text")
 				app.Config.OutputFormat = "text"
 			}
 			if app.Config.OnlyForSale {
 				app.Config.CheckForSale = true
 			}
-			return nil
+			if app.Config.Prefilter.Reset {
+				app.Config.Prefilter.Enabled = true
+			}
+			if app.Config.LimitPerTLD < 0 || app.Config.LimitPerKeyword < 0 {
+				return fmt.Errorf("invalid per-TLD or per-keyword limit: must not be negative")
+			}
+			if app.Config.MaxPrice < 0 {
+				return fmt.Errorf("invalid max-price: must not be negative")
+			}
+			if app.Config.MaxPrice > 0 {
+				app.Config.CheckPricing = true
+			}
+			if err := composer.ValidateOrder(app.Config.Order); err != nil {
+				return err
+			}
+			if app.Config.OutputFormat == output.FormatTemplate {
+				if _, err := output.ParseTemplate(app.Config); err != nil {
+					return err
+				}
+			}
+			if app.Config.StatsFormat != output.StatsFormatText && app.Config.StatsFormat != output.StatsFormatJSON {
+				return fmt.Errorf("invalid --stats-format %q: use text or json", app.Config.StatsFormat)
+			}
+			if app.Config.OutputFile != "" {
+				if err := output.ValidateFile(app.Config.OutputFormat, app.Config.OutputFile); err != nil {
+					return fmt.Errorf("invalid --output: %w", err)
+				}
+			}
+			for _, spec := range app.Config.Tee {
+				if _, err := output.ParseTee(spec); err != nil {
+					return err
+				}
+			}
+			if app.Config.Progress && app.Config.NoProgress {
+				return fmt.Errorf("--progress and --no-progress cannot be used together")
+			}
+			if app.Config.RecordDir != "" && app.Config.ReplayDir != "" {
+				return fmt.Errorf("--record and --replay cannot be used together")
+			}
+			if app.Config.TUI {
+				if err := validateTUIFlags(cmd.Flags().Changed); err != nil {
+					return err
+				}
+			}
+			var err error
+			lookupOpts, err = validateLookups(app.Config)
+			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if app.Config.InputFile != "" {
@@ -83,7 +151,30 @@ func NewRootCmd(app *config.TldxContext) *cobra.Command {
 				return nil
 			}
 
-			found := domain.Exec(cmd.Context(), app, args)
+			fixtureOpts, finish, err := fixtureOptions(app.Config)
+			if err != nil {
+				slog.Error("Failed to set up fixtures", "error", err)
+				return err
+			}
+			opts := append(lookupOpts, fixtureOpts...)
+			if !app.Config.DryRun {
+				stopTelemetry, err := startTelemetry(cmd.Context(), app.Config.Telemetry)
+				if err != nil {
+					slog.Error("Failed to set up telemetry", "error", err)
+					return errors.Join(err, finish())
+				}
+				defer stopTelemetry()
+			}
+			if app.Config.TUI && !app.Config.DryRun {
+				// Anything written to stderr would draw over the table.
+				logging.Discard()
+				_, err := tui.Run(cmd.Context(), app, args, opts...)
+				return errors.Join(err, finish())
+			}
+			found, err := domain.Exec(cmd.Context(), app, args, opts...)
+			if err := errors.Join(err, finish()); err != nil {
+				return err
+			}
 
 			if (app.Config.OnlyAvailable || app.Config.OnlyForSale) && !found && !app.Config.DryRun {
 				if app.Config.OnlyForSale && !app.Config.OnlyAvailable {
@@ -96,9 +187,11 @@ func NewRootCmd(app *config.TldxContext) *cobra.Command {
 	}
 
 	bindFlags(cmd, app)
-	cmd.AddCommand(NewMCPCmd(Version))
+	cmd.AddCommand(NewMCPCmd(Version, &app.Config.Log))
 	cmd.AddCommand(NewPresetCmd())
 	cmd.AddCommand(NewConfigCmd())
+	cmd.AddCommand(NewExplainCmd())
+	cmd.AddCommand(NewZoneCmd())
 	return cmd
 }
 
@@ -112,12 +205,188 @@ func bindFlags(cmd *cobra.Command, app *config.TldxContext) {
 	cmd.Flags().BoolVarP(&cfg.OnlyAvailable, "only-available", "a", false, "Show only available domains")
 	cmd.Flags().IntVarP(&cfg.MaxDomainLength, "max-domain-length", "m", 64, "Maximum length of domain name")
 	cmd.Flags().BoolVar(&cfg.ShowStats, "show-stats", false, "Show statistics at the end of execution")
+	cmd.Flags().StringVar(&cfg.StatsFormat, "stats-format", output.StatsFormatText, "How --show-stats prints: text (a summary after text output) or json (on stderr, after any format)")
 	cmd.Flags().StringVar(&cfg.TLDPreset, "tld-preset", "", "Use a tld preset (e.g. popular, tech)")
-	cmd.Flags().StringVarP(&cfg.OutputFormat, "format", "f", "text", "Format of output (text, json, json-stream, json-array, csv, grouped, grouped-tld)")
+	cmd.Flags().StringVarP(&cfg.OutputFormat, "format", "f", "text", "Format of output (text, json, json-stream, json-array, csv, grouped, grouped-tld, matrix, matrix-csv, matrix-markdown, markdown, html, template, yaml, toml, ndjson-summary)")
+	cmd.Flags().StringVarP(&cfg.OutputFile, "output", "o", "", "Write results to this file instead of stdout, replaced in one step when the run ends")
+	cmd.Flags().StringArrayVar(&cfg.Tee, "tee", nil, "Also write results to a file in another format: format=path[,filter=all|available|for-sale] (repeatable)")
+	cmd.Flags().StringVar(&cfg.Template, "template", "", `Go text/template for --format template, rendered once per result (e.g. '{{.Domain}},{{.Available}}')`)
+	cmd.Flags().StringVar(&cfg.TemplateFile, "template-file", "", "File holding the template for --format template, optionally defining \"header\" and \"footer\" templates")
 	cmd.Flags().BoolVar(&cfg.NoColor, "no-color", false, "Disable colored output")
 	cmd.Flags().BoolVarP(&cfg.Regex, "regex", "r", false, "Enable regex pattern matching for domain keywords")
 	cmd.Flags().IntVarP(&cfg.Limit, "limit", "l", 0, "Stop after finding this many available domains (0 = no limit)")
+	cmd.Flags().IntVar(&cfg.LimitPerTLD, "limit-per-tld", 0, "Stop checking a TLD after finding this many available domains on it (0 = no limit)")
+	cmd.Flags().IntVar(&cfg.LimitPerKeyword, "limit-per-keyword", 0, "Stop checking a keyword after finding this many available domains for it (0 = no limit)")
+	cmd.Flags().StringVar(&cfg.Order, "order", composer.OrderInput, "Order lookups start in: input, shortest-first, score-first, tld-priority or random")
+	cmd.Flags().BoolVar(&cfg.Progress, "progress", false, "Show a progress line with rate and ETA on stderr, even when it is not a terminal")
+	cmd.Flags().BoolVar(&cfg.NoProgress, "no-progress", false, "Never show the progress line")
+	cmd.Flags().BoolVar(&cfg.TUI, "tui", false, "Browse results in an interactive table, then print the shortlist")
 	cmd.Flags().BoolVar(&cfg.DryRun, "dry-run", false, "Print domains that would be checked without making network calls")
+	cmd.Flags().StringVar(&cfg.RecordDir, "record", "", "Save every RDAP, WHOIS and DNS answer to this directory, for --replay")
+	cmd.Flags().StringVar(&cfg.ReplayDir, "replay", "", "Answer lookups from a --record directory instead of the network; unrecorded queries fail")
 	cmd.Flags().BoolVar(&cfg.CheckForSale, "for-sale", false, "Check taken domains for an RFC 10023 _for-sale TXT record")
 	cmd.Flags().BoolVar(&cfg.OnlyForSale, "only-for-sale", false, "Show only taken domains that are for sale (implies --for-sale)")
+	cmd.Flags().BoolVar(&cfg.DNSPrescreen, "dns-prescreen", false, "Ask the TLD's nameservers first and skip RDAP for delegated domains")
+	cmd.Flags().StringVar(&cfg.Transport.Proxy, "proxy", "", "Proxy for RDAP and other HTTP lookups (http://, https:// or socks5://; socks5 also carries WHOIS). Defaults to HTTP(S)_PROXY")
+	cmd.Flags().StringVar(&cfg.DNSServer, "dns-server", "", "DNS server for all lookups: host[:port], tcp://host, tls://host or an https:// DoH URL")
+	cmd.Flags().BoolVar(&cfg.Verify, "verify", false, "Ask RDAP, DNS delegation and WHOIS in parallel and report how far they agree")
+	cmd.Flags().BoolVar(&cfg.CheckPricing, "pricing", false, "Look up registration prices for available domains via the configured registrar")
+	cmd.Flags().Float64Var(&cfg.MaxPrice, "max-price", 0, "Hide available domains costing more than this to register (implies --pricing)")
+	cmd.Flags().StringVar(&cfg.MaxPriceCurrency, "max-price-currency", cfg.MaxPriceCurrency, "Currency of --max-price; prices quoted in another currency do not fit")
+	cmd.Flags().StringSliceVar(&cfg.Backends, "backend", nil, "Availability backends to try in order, for every TLD (e.g. rdap,dns,whois)")
+	cmd.Flags().IntVar(&cfg.MaxRetries, "retries", cfg.MaxRetries, "Retries per lookup after a timeout or transient error")
+	cmd.Flags().DurationVar(&cfg.InitialBackoff, "initial-backoff", cfg.InitialBackoff, "Upper bound of the first, randomized wait before a retry")
+	cmd.Flags().DurationVar(&cfg.MaxBackoff, "max-backoff", cfg.MaxBackoff, "Longest wait between retries")
+	cmd.Flags().Float64Var(&cfg.BackoffFactor, "backoff-factor", cfg.BackoffFactor, "Multiplier applied to the wait after each retry")
+	cmd.Flags().DurationVar(&cfg.ContextTimeout, "timeout", cfg.ContextTimeout, "Time allowed for checking one domain, retries included")
+	cmd.Flags().IntVar(&cfg.ConcurrencyLimit, "concurrency", cfg.ConcurrencyLimit, "Domains checked in parallel")
+	cmd.Flags().BoolVar(&cfg.AdaptiveConcurrency, "adaptive-concurrency", false, "Check fewer domains in parallel while lookups are failing, then ramp back up")
+	cmd.Flags().StringSliceVar(&cfg.Reserved.Files, "reserved-list", nil, "Extra reserved-name list files, checked after the built-in lists (repeatable)")
+	cmd.Flags().BoolVar(&cfg.Reserved.Disabled, "no-reserved", false, "Do not check free domains against reserved-name lists")
+	cmd.Flags().BoolVar(&cfg.Zone.ConfirmNegatives, "zone-confirm", false, "Ask RDAP about names missing from an imported zone file before reporting them available")
+	cmd.Flags().BoolVar(&cfg.Prefilter.Enabled, "prefilter", false, "Check domains found taken in earlier runs last, remembered in a local Bloom filter")
+	cmd.Flags().StringVar(&cfg.Prefilter.Mode, "prefilter-mode", cfg.Prefilter.Mode, "What --prefilter does with probably-taken domains: defer them to the end of the run, or skip them unchecked")
+	cmd.Flags().IntVar(&cfg.Prefilter.Size, "prefilter-size", cfg.Prefilter.Size, "Taken domains the prefilter holds at its false positive rate; a new size starts an empty filter")
+	cmd.Flags().DurationVar(&cfg.Prefilter.MaxAge, "prefilter-max-age", cfg.Prefilter.MaxAge, "Start the prefilter afresh once it is this old, so dropped domains are seen again (0 = never)")
+	cmd.Flags().BoolVar(&cfg.Prefilter.Reset, "prefilter-reset", false, "Delete the prefilter before the run (implies --prefilter)")
+	bindTelemetryFlags(cmd.Flags(), &cfg.Telemetry)
+	cmd.PersistentFlags().StringVar(&cfg.Log.Level, "log-level", "info", "Least severe log messages to show on stderr: debug, info, warn or error (--verbose means debug)")
+	cmd.PersistentFlags().StringVar(&cfg.Log.Format, "log-format", logging.FormatText, "Format of log messages on stderr: text or json")
+}
+
+// applyUserConfig layers the config file under the command line. isSet
+// reports which flags were passed; those win.
+func applyUserConfig(cfg *config.TldxConfigOptions, userCfg *userconfig.UserConfig, isSet func(string) bool) {
+	userCfg.Defaults.ApplyTo(cfg, isSet)
+	userCfg.Backends.ApplyTo(cfg, isSet)
+	userCfg.Registrar.ApplyTo(cfg)
+	userCfg.EPP.ApplyTo(cfg)
+	userCfg.Transport.ApplyTo(cfg, isSet)
+	userCfg.Resolver.ApplyTo(cfg, isSet)
+	userCfg.Reserved.ApplyTo(cfg, isSet)
+	userCfg.Zone.ApplyTo(cfg, isSet)
+	userCfg.Prefilter.ApplyTo(cfg, isSet)
+}
+
+// validateTUIFlags rejects the flags --tui has no use for: the table shows
+// every result and the shortlist goes to stdout.
+func validateTUIFlags(isSet func(string) bool) error {
+	for _, name := range []string{"output", "tee", "limit", "limit-per-tld", "limit-per-keyword"} {
+		if isSet(name) {
+			return fmt.Errorf("--tui and --%s cannot be used together", name)
+		}
+	}
+	return nil
+}
+
+// validateLookups fails fast on settings a lookup would trip over later. It
+// returns resolver options carrying what it had to parse, so the lookups do
+// not parse it again.
+func validateLookups(cfg *config.TldxConfigOptions) ([]resolver.ResolverOption, error) {
+	var opts []resolver.ResolverOption
+	if err := validateBackends(cfg); err != nil {
+		slog.Error("Invalid backend chain", "error", err)
+		return nil, err
+	}
+	if cfg.CheckPricing || usesBackend(cfg, resolver.SourceRegistrar) {
+		if _, err := registrar.New(cfg.Registrar, nil); err != nil {
+			slog.Error("Registrar pricing needs a [registrar] section in the config file", "error", err)
+			return nil, err
+		}
+	}
+	if err := resolver.ValidateTuning(cfg); err != nil {
+		slog.Error("Invalid resolver tuning", "error", err)
+		return nil, err
+	}
+	if !cfg.Reserved.Disabled {
+		lists, err := reserved.Load(cfg.Reserved)
+		if err != nil {
+			slog.Error("Invalid reserved-name list", "error", err)
+			return nil, err
+		}
+		opts = append(opts, resolver.WithReservedLists(lists))
+	}
+	if err := transport.Validate(cfg.Transport); err != nil {
+		slog.Error("Invalid transport settings", "error", err)
+		return nil, err
+	}
+	if _, err := resolver.NewDNSResolver(cfg.DNSServer, nil); err != nil {
+		slog.Error("Invalid DNS server", "error", err)
+		return nil, err
+	}
+	if cfg.Prefilter.Enabled {
+		if err := prefilter.Validate(cfg.Prefilter); err != nil {
+			slog.Error("Invalid prefilter settings", "error", err)
+			return nil, err
+		}
+	}
+	if usesBackend(cfg, resolver.SourceEPP) {
+		if _, err := epp.NewSession(cfg.EPP, nil); err != nil {
+			slog.Error("The epp backend needs an [epp] section in the config file", "error", err)
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// fixtureOptions sets up --record or --replay. finish saves the recording,
+// or fails the run if the replay asked for anything not recorded.
+func fixtureOptions(cfg *config.TldxConfigOptions) ([]resolver.ResolverOption, func() error, error) {
+	switch {
+	case cfg.RecordDir != "":
+		rec, err := fixture.NewRecorder(cfg.RecordDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts, err := rec.Options(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return opts, rec.Save, nil
+	case cfg.ReplayDir != "":
+		rep, err := fixture.Load(cfg.ReplayDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return rep.Options(), rep.Err, nil
+	default:
+		return nil, func() error { return nil }, nil
+	}
+}
+
+// validateBackends checks every configured chain against the registered
+// backends, so a typo fails the run before any lookup.
+func validateBackends(cfg *config.TldxConfigOptions) error {
+	if len(cfg.Backends) > 0 {
+		if err := resolver.ValidateBackends(cfg.Backends); err != nil {
+			return err
+		}
+	}
+
+	tlds := make([]string, 0, len(cfg.TLDBackends))
+	for tld := range cfg.TLDBackends {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+
+	for _, tld := range tlds {
+		if err := resolver.ValidateBackends(cfg.TLDBackends[tld]); err != nil {
+			return fmt.Errorf(".%s: %w", tld, err)
+		}
+	}
+	return nil
+}
+
+// usesBackend reports whether name appears in the default chain or any
+// per-TLD chain.
+func usesBackend(cfg *config.TldxConfigOptions, name string) bool {
+	if slices.Contains(cfg.Backends, name) {
+		return true
+	}
+	for _, chain := range cfg.TLDBackends {
+		if slices.Contains(chain, name) {
+			return true
+		}
+	}
+	return false
 }
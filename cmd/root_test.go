@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/brandonyoungdev/tldx/cmd"
 	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/fixture"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -177,3 +179,135 @@ func TestRootCommand_EmptyFormatFallsBackToText(t *testing.T) {
 	require.NoError(t, rootCmd.Execute())
 	assert.Equal(t, "text", app.Config.OutputFormat)
 }
+
+func TestRootCommand_ReplayFailsOnUnrecordedQueries(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "whois.json"), []byte(`{}`), 0o644))
+
+	app := config.NewTldxContext()
+	rootCmd := cmd.NewRootCmd(app)
+	rootCmd.SetArgs([]string{"acme", "--tlds", "com", "--backend", "whois", "--replay", dir})
+	rootCmd.SilenceErrors = true
+
+	err := rootCmd.Execute()
+	require.ErrorIs(t, err, fixture.ErrNotRecorded)
+	assert.Contains(t, err.Error(), "whois acme.com")
+}
+
+func TestRootCommand_RecordAndReplayAreExclusive(t *testing.T) {
+	app := config.NewTldxContext()
+	rootCmd := cmd.NewRootCmd(app)
+	rootCmd.SetArgs([]string{"acme", "--record", t.TempDir(), "--replay", t.TempDir()})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "cannot be used together")
+}
+
+func TestRootCommand_PrefilterSkipsKnownTakenDomains(t *testing.T) {
+	t.Setenv("TLDX_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	t.Setenv("TLDX_PREFILTER", filepath.Join(t.TempDir(), "prefilter.bloom"))
+
+	run := func(replay string, extra ...string) error {
+		rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+		rootCmd.SetArgs(append([]string{"acme", "--tlds", "com", "--backend", "whois", "--replay", replay, "--prefilter", "--prefilter-mode", "skip", "--no-reserved"}, extra...))
+		rootCmd.SilenceErrors = true
+		return rootCmd.Execute()
+	}
+
+	require.NoError(t, run(writeWhoisFixture(t)))
+
+	// Nothing is recorded, so any lookup would fail the run.
+	empty := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(empty, "whois.json"), []byte(`{}`), 0o644))
+	require.NoError(t, run(empty))
+
+	require.Error(t, run(empty, "--prefilter-reset"), "a reset filter knows nothing, so the lookup is made")
+}
+
+func TestRootCommand_RejectsAnUnknownPrefilterMode(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--prefilter", "--prefilter-mode", "sometimes"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "unknown mode")
+}
+
+func TestRootCommand_RejectsAnUnknownOrder(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--order", "best"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "unknown order")
+}
+
+func TestRootCommand_RejectsProgressWithNoProgress(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--progress", "--no-progress"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "cannot be used together")
+}
+
+func TestRootCommand_TemplateFormatNeedsATemplate(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--format", "template"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "needs --template or --template-file")
+}
+
+func TestRootCommand_RejectsBadTee(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--tee", "csv=" + t.TempDir() + "/out.csv,filter=cheap"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), `unknown filter "cheap"`)
+}
+
+func TestRootCommand_RejectsUnknownStatsFormat(t *testing.T) {
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "--show-stats", "--stats-format", "yaml"})
+	rootCmd.SilenceErrors = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), `invalid --stats-format "yaml"`)
+}
+
+func TestRootCommand_RejectsBadLogOptions(t *testing.T) {
+	for _, args := range [][]string{
+		{"acme", "--log-level", "loud"},
+		{"acme", "--log-format", "xml"},
+	} {
+		rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+		rootCmd.SetArgs(args)
+		rootCmd.SilenceErrors = true
+
+		assert.ErrorContains(t, rootCmd.Execute(), "invalid "+args[1], args)
+	}
+}
+
+func TestRootCommand_FailsWhenTheMetricsAddressIsTaken(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetArgs([]string{"acme", "-t", "com", "--metrics-addr", ln.Addr().String()})
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	assert.ErrorContains(t, rootCmd.Execute(), "metrics endpoint")
+}
+
+func TestRootCommand_RejectsTUIWithOutputsAndLimits(t *testing.T) {
+	for _, flags := range [][]string{
+		{"--output", filepath.Join(t.TempDir(), "out.txt")},
+		{"--tee", "csv=" + filepath.Join(t.TempDir(), "out.csv")},
+		{"--limit", "5"},
+	} {
+		rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+		rootCmd.SetArgs(append([]string{"acme", "--tui"}, flags...))
+		rootCmd.SilenceErrors = true
+
+		assert.ErrorContains(t, rootCmd.Execute(), "--tui and "+flags[0]+" cannot be used together")
+	}
+}
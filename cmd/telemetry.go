@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/telemetry"
+	"github.com/spf13/pflag"
+)
+
+// telemetryFlushTimeout bounds the wait for the last spans and metrics to be
+// exported when a command ends.
+const telemetryFlushTimeout = 5 * time.Second
+
+// bindTelemetryFlags adds the flags of opts, shared by the root and mcp
+// commands.
+func bindTelemetryFlags(flags *pflag.FlagSet, opts *config.TelemetryOptions) {
+	flags.StringVar(&opts.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics of the lookups at this address's /metrics while running (e.g. :9464)")
+	flags.StringVar(&opts.OTLPEndpoint, "otlp-endpoint", "", "Export OpenTelemetry spans and metrics over OTLP/HTTP to this collector (e.g. http://localhost:4318); OTEL_EXPORTER_OTLP_* also apply")
+}
+
+// startTelemetry sets up the exporters in opts and returns the function
+// that flushes them, to be deferred.
+func startTelemetry(ctx context.Context, opts config.TelemetryOptions) (func(), error) {
+	shutdown, err := telemetry.Setup(ctx, opts, Version)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Warn("Failed to flush telemetry", "error", err)
+		}
+	}, nil
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/prefilter"
+	"github.com/brandonyoungdev/tldx/internal/userconfig"
+	"github.com/brandonyoungdev/tldx/internal/validate"
+	"github.com/brandonyoungdev/tldx/internal/zone"
+	"github.com/spf13/cobra"
+)
+
+func NewZoneCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "zone",
+		Short: "Manage zone files for the offline zone backend",
+		Long: "Import TLD zone files, such as ICANN CZDS downloads, into a local store. The zone backend\n" +
+			"then answers for those TLDs without a network round trip:\n\n" +
+			"  tldx zone import com com.txt.gz\n" +
+			"  tldx 'acme[a-z]{2}' -r -t com --backend zone",
+	}
+
+	cmd.PersistentFlags().StringVar(&dir, "dir", "", "Directory holding imported zones (default: dir under [zone], else the user cache)")
+
+	cmd.AddCommand(newZoneImportCmd(&dir))
+	cmd.AddCommand(newZoneListCmd(&dir))
+	cmd.AddCommand(newZoneRemoveCmd(&dir))
+	return cmd
+}
+
+// zoneDir resolves --dir, then dir under [zone], then zone.DefaultDir.
+func zoneDir(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	cfg, err := userconfig.Load()
+	if err != nil {
+		slog.Warn("Could not load user config", "error", err)
+	} else if cfg.Zone.Dir != "" {
+		return cfg.Zone.Dir, nil
+	}
+	return zone.DefaultDir()
+}
+
+func newZoneImportCmd(dir *string) *cobra.Command {
+	var (
+		date         string
+		addPrefilter bool
+	)
+
+	c := &cobra.Command{
+		Use:   "import <tld> <zone-file>",
+		Short: "Index the delegated names of a zone file",
+		Long: "Read a zone file, gzip-compressed or plain, and store the names it delegates under <tld>.\n" +
+			"Importing a TLD again replaces its snapshot. The snapshot date comes from --date, else\n" +
+			"the SOA serial when it encodes a date, else the file's modification time.",
+		Example: `  tldx zone import com com.txt.gz
+  tldx zone import xyz xyz.zone --date 2026-10-01`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tld := zone.Normalize(args[0])
+			if !validate.IsValidDomainOrKeyword(tld) {
+				return fmt.Errorf("invalid TLD %q", args[0])
+			}
+
+			var snapshot time.Time
+			if date != "" {
+				var err error
+				if snapshot, err = time.Parse(time.DateOnly, date); err != nil {
+					return fmt.Errorf("invalid --date %q: use YYYY-MM-DD", date)
+				}
+			}
+
+			path, err := zoneDir(*dir)
+			if err != nil {
+				return err
+			}
+
+			started := time.Now()
+			info, err := zone.ImportFile(path, tld, args[1], snapshot)
+			if err != nil {
+				slog.Error("Failed to import the zone file", "error", err)
+				return err
+			}
+
+			cmd.Printf("Imported %d names for .%s (snapshot %s) in %s → %s\n",
+				info.Names, info.Zone, info.SnapshotDate(), time.Since(started).Round(time.Millisecond), path)
+
+			if addPrefilter {
+				return seedPrefilter(cmd, path, info.Zone)
+			}
+			return nil
+		},
+	}
+
+	c.Flags().StringVar(&date, "date", "", "Snapshot date of the zone file (YYYY-MM-DD)")
+	c.Flags().BoolVar(&addPrefilter, "prefilter", false, "Also add the zone's names to the prefilter of known-taken domains")
+	return c
+}
+
+// seedPrefilter adds every name of an imported zone to the prefilter
+// configured under [prefilter].
+func seedPrefilter(cmd *cobra.Command, dir, name string) error {
+	cfg := config.NewTldxContext().Config
+	if userCfg, err := userconfig.Load(); err == nil {
+		userCfg.Prefilter.ApplyTo(cfg, nil)
+	}
+	if err := prefilter.Validate(cfg.Prefilter); err != nil {
+		return err
+	}
+	path := cfg.Prefilter.Path
+	if path == "" {
+		var err error
+		if path, err = prefilter.DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	store, err := zone.Open(dir, name)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	filter, _, err := prefilter.Open(path, cfg.Prefilter.Size, cfg.Prefilter.FalsePositiveRate, cfg.Prefilter.MaxAge)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	err = store.Each(func(label string) {
+		if filter.Add(label + "." + store.Zone) {
+			added++
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err := filter.Save(path); err != nil {
+		return err
+	}
+
+	cmd.Printf("Added %d names to the prefilter, which now holds %d of %d → %s\n", added, filter.Count(), filter.Capacity(), path)
+	if filter.Count() > filter.Capacity() {
+		slog.Warn("The prefilter holds more domains than it was sized for; raise size under [prefilter]",
+			"domains", filter.Count(), "size", filter.Capacity())
+	}
+	return nil
+}
+
+func newZoneListCmd(dir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List imported zones with their snapshot dates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := zoneDir(*dir)
+			if err != nil {
+				return err
+			}
+			infos, err := zone.List(path)
+			if err != nil {
+				return err
+			}
+			if len(infos) == 0 {
+				cmd.Printf("No zones imported (%s)\n", path)
+				cmd.Println("Run \"tldx zone import <tld> <zone-file>\" to add one.")
+				return nil
+			}
+
+			cmd.Printf("Zones in %s:\n\n", path)
+			for _, info := range infos {
+				cmd.Printf("  %-14s %12d names   snapshot %s   imported %s\n",
+					"."+info.Zone, info.Names, info.SnapshotDate(), info.Imported.Format(time.DateOnly))
+			}
+			return nil
+		},
+	}
+}
+
+func newZoneRemoveCmd(dir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <tld>",
+		Aliases: []string{"rm", "delete"},
+		Short:   "Remove an imported zone",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := zoneDir(*dir)
+			if err != nil {
+				return err
+			}
+			if err := zone.Remove(path, args[0]); err != nil {
+				return err
+			}
+			cmd.Printf("Removed zone .%s\n", zone.Normalize(args[0]))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/cmd"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runZone(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd := cmd.NewRootCmd(config.NewTldxContext())
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(append([]string{"zone"}, args...))
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestZoneCommand_ImportListRemove(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "xyz.zone")
+	require.NoError(t, os.WriteFile(file, []byte("acme.xyz. 3600 IN NS ns1.acme.net.\nbeta.xyz. 3600 IN NS ns1.beta.net.\n"), 0o644))
+
+	out, err := runZone(t, "import", "xyz", file, "--date", "2026-10-01", "--dir", dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Imported 2 names for .xyz (snapshot 2026-10-01)")
+
+	out, err = runZone(t, "list", "--dir", dir)
+	require.NoError(t, err)
+	assert.Regexp(t, `\.xyz\s+2 names\s+snapshot 2026-10-01`, out)
+
+	out, err = runZone(t, "rm", ".XYZ", "--dir", dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed zone .xyz")
+
+	out, err = runZone(t, "list", "--dir", dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "No zones imported")
+}
+
+func TestZoneCommand_RejectsABadDate(t *testing.T) {
+	_, err := runZone(t, "import", "xyz", "xyz.zone", "--date", "01/10/2026", "--dir", t.TempDir())
+	assert.ErrorContains(t, err, "use YYYY-MM-DD")
+}
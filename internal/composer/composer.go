@@ -53,6 +53,7 @@ func (s *ComposerService) Compile(domainsOrKeywords []string) ([]resolver.Domain
 		specs = filtered
 	}
 
+	Order(specs, s.app.Config.Order)
 	return specs, warnings
 }
 
@@ -64,7 +65,7 @@ func (s *ComposerService) GenerateDomainPermutations(keywords []string) ([]resol
 	for _, tld_candidate := range s.app.Config.TLDs {
 		tld, ok := publicsuffix.PublicSuffix(strings.ToLower(tld_candidate))
 		if !ok {
-			warnings = append(warnings, fmt.Errorf("%v: invalid TLD", tld_candidate))
+			warnings = append(warnings, newWarning("invalid TLD", "tld", tld_candidate))
 			continue
 		}
 		tlds = append(tlds, tld)
@@ -82,7 +83,7 @@ func (s *ComposerService) GenerateDomainPermutations(keywords []string) ([]resol
 		} else if tlds, ok := presets.TLDs.Get(tldPreset); ok {
 			additionalTlds = tlds
 		} else {
-			warnings = append(warnings, fmt.Errorf("Error: TLD preset not found"))
+			warnings = append(warnings, newWarning("TLD preset not found", "preset", tldPreset))
 		}
 		tlds = append(tlds, additionalTlds...)
 	}
@@ -158,7 +159,8 @@ func (s *ComposerService) validateAndExpandPattern(pattern string, maxCombinatio
 	}
 
 	if !safe {
-		s.logUnsafePattern(pattern, count)
+		slog.Warn("Skipping a regex pattern with too many combinations",
+			"pattern", pattern, "combinations", count, "limit", maxCombinations)
 		return nil // Skip unsafe patterns but don't error
 	}
 
@@ -172,15 +174,6 @@ func (s *ComposerService) validateAndExpandPattern(pattern string, maxCombinatio
 	return nil
 }
 
-func (s *ComposerService) logUnsafePattern(pattern string, count int) {
-	if !s.app.Config.Verbose {
-		return
-	}
-
-	slog.Warn(fmt.Sprintf("Pattern '%s' would generate more than 500,000 combinations (%d). Skipping for safety.", pattern, count))
-
-}
-
 func isRegexPattern(s string) bool {
 	return strings.Contains(s, "[") || strings.Contains(s, "{") || strings.Contains(s, "\\")
 }
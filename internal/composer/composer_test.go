@@ -225,3 +225,20 @@ func TestCompile_RegexMode_UnsafePattern_VerboseOff(t *testing.T) {
 	assert.Empty(t, warnings)
 	assert.Empty(t, specs)
 }
+
+func TestCompile_WarningsCarryTheirDetails(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "not a tld"}
+	app.Config.TLDPreset = "nope"
+	_, warnings := composer.NewComposerService(app).Compile([]string{"test"})
+
+	var details [][]any
+	for _, err := range warnings {
+		var w *composer.Warning
+		if assert.ErrorAs(t, err, &w) {
+			details = append(details, w.Attrs)
+		}
+	}
+	assert.Equal(t, [][]any{{"tld", "not a tld"}, {"preset", "nope"}}, details)
+	assert.EqualError(t, warnings[0], "invalid TLD: not a tld")
+}
@@ -0,0 +1,145 @@
+package composer
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// Lookup orders for --order. Lookups start in this order, so a --limit stops
+// after the first names in it that are available.
+const (
+	// OrderInput keeps composer order: every TLD of a name before the next
+	// name, names in the order the keywords expand to.
+	OrderInput = "input"
+	// OrderShortest checks the shortest names first, across all TLDs.
+	OrderShortest = "shortest-first"
+	// OrderScore checks the names Score rates highest first.
+	OrderScore = "score-first"
+	// OrderTLDPriority checks every name on the first TLD before moving to
+	// the next, in the order the TLDs were given, presets included.
+	OrderTLDPriority = "tld-priority"
+	OrderRandom      = "random"
+)
+
+// Orders lists the valid --order values.
+var Orders = []string{OrderInput, OrderShortest, OrderScore, OrderTLDPriority, OrderRandom}
+
+// ValidateOrder checks an --order value. Empty means OrderInput.
+func ValidateOrder(order string) error {
+	if order == "" || slices.Contains(Orders, order) {
+		return nil
+	}
+	return fmt.Errorf("unknown order %q: use one of %s", order, strings.Join(Orders, ", "))
+}
+
+// Order sorts specs in place for scheduling. Ties keep composer order.
+func Order(specs []resolver.DomainSpec, order string) {
+	switch order {
+	case OrderShortest:
+		rank := tldRanks(specs)
+		slices.SortStableFunc(specs, func(a, b resolver.DomainSpec) int {
+			return cmp.Or(
+				cmp.Compare(len(baseName(a)), len(baseName(b))),
+				cmp.Compare(rank[tldOf(a)], rank[tldOf(b)]),
+			)
+		})
+	case OrderScore:
+		rank := tldRanks(specs)
+		scores := make(map[string]float64, len(specs))
+		for _, spec := range specs {
+			scores[spec.Domain] = Score(spec, rank[tldOf(spec)])
+		}
+		slices.SortStableFunc(specs, func(a, b resolver.DomainSpec) int {
+			return cmp.Compare(scores[b.Domain], scores[a.Domain])
+		})
+	case OrderTLDPriority:
+		rank := tldRanks(specs)
+		slices.SortStableFunc(specs, func(a, b resolver.DomainSpec) int {
+			return cmp.Compare(rank[tldOf(a)], rank[tldOf(b)])
+		})
+	case OrderRandom:
+		rand.Shuffle(len(specs), func(i, j int) { specs[i], specs[j] = specs[j], specs[i] })
+	}
+}
+
+// Score rates how desirable a name is, higher being better: short, letters
+// only, easy to say, without a prefix or suffix, on a TLD given early
+// (tldRank 0 is the first). It orders lookups; it is not an appraisal.
+func Score(spec resolver.DomainSpec, tldRank int) float64 {
+	name := baseName(spec)
+	score := 100 - 4*float64(len(name))
+
+	for _, r := range name {
+		switch {
+		case r == '-':
+			score -= 15
+		case r >= '0' && r <= '9':
+			score -= 10
+		}
+	}
+	score -= 8 * float64(awkwardRuns(name))
+
+	if spec.Prefix != "" {
+		score -= 5
+	}
+	if spec.Suffix != "" {
+		score -= 5
+	}
+	return score - 3*float64(min(tldRank, 10))
+}
+
+// awkwardRuns counts runs of three or more consonants, or of three or more
+// vowels, which make a name hard to say or spell.
+func awkwardRuns(name string) int {
+	runs := 0
+	length := 0
+	prevVowel := false
+	for _, r := range name {
+		if r < 'a' || r > 'z' {
+			length = 0
+			continue
+		}
+		vowel := strings.ContainsRune("aeiouy", r)
+		if length > 0 && vowel == prevVowel {
+			length++
+		} else {
+			length = 1
+		}
+		prevVowel = vowel
+		if length == 3 {
+			runs++
+		}
+	}
+	return runs
+}
+
+// tldRanks numbers the TLDs in the order they first appear, which is the
+// order they were given in.
+func tldRanks(specs []resolver.DomainSpec) map[string]int {
+	rank := make(map[string]int)
+	for _, spec := range specs {
+		tld := tldOf(spec)
+		if _, ok := rank[tld]; !ok {
+			rank[tld] = len(rank)
+		}
+	}
+	return rank
+}
+
+func tldOf(spec resolver.DomainSpec) string {
+	if spec.TLD != "" {
+		return spec.TLD
+	}
+	_, tld, _ := strings.Cut(spec.Domain, ".")
+	return tld
+}
+
+// baseName is the domain without its TLD.
+func baseName(spec resolver.DomainSpec) string {
+	return strings.TrimSuffix(spec.Domain, "."+tldOf(spec))
+}
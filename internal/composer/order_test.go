@@ -0,0 +1,65 @@
+package composer_test
+
+import (
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/composer"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileOrdered(t *testing.T, order string, keywords ...string) []string {
+	t.Helper()
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"io", "com"}
+	app.Config.Order = order
+	specs, warnings := composer.NewComposerService(app).Compile(keywords)
+	require.Empty(t, warnings)
+	return specDomains(specs)
+}
+
+func TestCompile_Order(t *testing.T) {
+	keywords := []string{"zephyrlabs", "acme", "xq9"}
+
+	assert.Equal(t,
+		[]string{"zephyrlabs.io", "zephyrlabs.com", "acme.io", "acme.com", "xq9.io", "xq9.com"},
+		compileOrdered(t, composer.OrderInput, keywords...))
+
+	assert.Equal(t,
+		[]string{"xq9.io", "xq9.com", "acme.io", "acme.com", "zephyrlabs.io", "zephyrlabs.com"},
+		compileOrdered(t, composer.OrderShortest, keywords...))
+
+	assert.Equal(t,
+		[]string{"zephyrlabs.io", "acme.io", "xq9.io", "zephyrlabs.com", "acme.com", "xq9.com"},
+		compileOrdered(t, composer.OrderTLDPriority, keywords...))
+
+	// xq9 is shorter than acme, but the digit costs it more than a letter.
+	assert.Equal(t,
+		[]string{"acme.io", "acme.com", "xq9.io", "xq9.com", "zephyrlabs.io", "zephyrlabs.com"},
+		compileOrdered(t, composer.OrderScore, keywords...))
+
+	assert.ElementsMatch(t,
+		compileOrdered(t, composer.OrderInput, keywords...),
+		compileOrdered(t, composer.OrderRandom, keywords...))
+}
+
+func TestScore(t *testing.T) {
+	spec := func(domain, tld, prefix string) resolver.DomainSpec {
+		return resolver.DomainSpec{Domain: domain, TLD: tld, Prefix: prefix}
+	}
+
+	assert.Greater(t, composer.Score(spec("acme.com", "com", ""), 0), composer.Score(spec("acmes.com", "com", ""), 0), "shorter wins")
+	assert.Greater(t, composer.Score(spec("acme.com", "com", ""), 0), composer.Score(spec("acme.io", "io", ""), 1), "earlier TLD wins")
+	assert.Greater(t, composer.Score(spec("acme.com", "com", ""), 0), composer.Score(spec("ac-me.com", "com", ""), 0), "hyphens lose")
+	assert.Greater(t, composer.Score(spec("banana.com", "com", ""), 0), composer.Score(spec("bnnnaa.com", "com", ""), 0), "consonant runs lose")
+	assert.Greater(t, composer.Score(spec("acmes.com", "com", ""), 0), composer.Score(spec("getacme.com", "com", "get"), 0), "affixes lose")
+}
+
+func TestValidateOrder(t *testing.T) {
+	for _, order := range append([]string{""}, composer.Orders...) {
+		assert.NoError(t, composer.ValidateOrder(order))
+	}
+	assert.ErrorContains(t, composer.ValidateOrder("best"), "unknown order")
+}
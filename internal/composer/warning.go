@@ -0,0 +1,45 @@
+package composer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Warning is a problem with part of the input that the rest of a run can go
+// on without, such as an invalid TLD. Attrs are its details as slog
+// key-value pairs.
+type Warning struct {
+	Msg   string
+	Attrs []any
+}
+
+func newWarning(msg string, attrs ...any) *Warning {
+	return &Warning{Msg: msg, Attrs: attrs}
+}
+
+// Error is Msg followed by the values of Attrs, e.g. "invalid TLD: comx".
+func (w *Warning) Error() string {
+	var values []string
+	for i := 1; i < len(w.Attrs); i += 2 {
+		values = append(values, fmt.Sprint(w.Attrs[i]))
+	}
+	if len(values) == 0 {
+		return w.Msg
+	}
+	return w.Msg + ": " + strings.Join(values, ", ")
+}
+
+// LogWarnings logs the warnings Compile returned, with the details of each
+// *Warning as fields.
+func LogWarnings(warnings []error) {
+	for _, err := range warnings {
+		var w *Warning
+		if errors.As(err, &w) {
+			slog.Warn(w.Msg, w.Attrs...)
+			continue
+		}
+		slog.Warn("Could not use the input", "error", err)
+	}
+}
@@ -7,28 +7,198 @@ type TldxContext struct {
 }
 
 type TldxConfigOptions struct {
-	TLDs             []string
-	Prefixes         []string
-	TLDPreset        string
-	Suffixes         []string
-	InputFile        string
-	MaxDomainLength  int
-	Verbose          bool
-	OnlyAvailable    bool
-	ShowStats        bool
-	OutputFormat     string
-	NoColor          bool
-	Regex            bool
-	Limit            int
-	DryRun           bool
-	CheckForSale     bool
-	OnlyForSale      bool
+	TLDs            []string
+	Prefixes        []string
+	TLDPreset       string
+	Suffixes        []string
+	InputFile       string
+	MaxDomainLength int
+	Verbose         bool
+	OnlyAvailable   bool
+	ShowStats       bool
+	// StatsFormat is how --show-stats prints: "text" or "json".
+	StatsFormat  string
+	OutputFormat string
+	// OutputFile writes the results there instead of stdout; each Tee,
+	// "format=path[,filter=...]", writes them to one more file.
+	OutputFile string
+	Tee        []string
+	// Template and TemplateFile hold the text/template for --format
+	// template, inline or in a file.
+	Template     string
+	TemplateFile string
+	NoColor      bool
+	Regex        bool
+	Limit        int
+	// LimitPerTLD and LimitPerKeyword cap the available domains found for
+	// each TLD and each keyword; zero means no cap.
+	LimitPerTLD     int
+	LimitPerKeyword int
+	// Order is the order lookups start in, see composer.Orders.
+	Order  string
+	DryRun bool
+	// Progress and NoProgress force the stderr progress line on or off;
+	// by default it shows when stderr is a terminal.
+	Progress   bool
+	NoProgress bool
+	// TUI shows results in the interactive table instead of printing them.
+	TUI bool
+	// RecordDir saves every lookup's answer there; ReplayDir answers lookups
+	// from such a recording instead of the network.
+	RecordDir    string
+	ReplayDir    string
+	CheckForSale bool
+	OnlyForSale  bool
+	DNSPrescreen bool
+	// Backends overrides the default backend chain; TLDBackends holds
+	// per-TLD chains keyed by TLD without the leading dot.
+	Backends    []string
+	TLDBackends map[string][]string
+	// CheckPricing asks the registrar for a price on available domains;
+	// MaxPrice > 0 hides available domains that cost more, and implies it.
+	// MaxPriceCurrency is the currency of MaxPrice: a price quoted in any
+	// other cannot be compared, so it does not fit.
+	CheckPricing     bool
+	MaxPrice         float64
+	MaxPriceCurrency string
+	Registrar        RegistrarOptions
+	EPP              EPPOptions
+	Verify           bool
+	// DNSServer is where DNS lookups go, e.g. "1.1.1.1", "tls://dns.quad9.net"
+	// or "https://cloudflare-dns.com/dns-query". Empty means the system resolver.
+	DNSServer        string
+	Transport        TransportOptions
 	MaxRetries       int
 	InitialBackoff   time.Duration
 	MaxBackoff       time.Duration
 	BackoffFactor    float64
 	ContextTimeout   time.Duration
 	ConcurrencyLimit int
+	// AdaptiveConcurrency lowers the number of parallel lookups while errors
+	// and timeouts are frequent, and raises it back towards ConcurrencyLimit.
+	AdaptiveConcurrency bool
+	// TLDTuning overrides the settings above per TLD, keyed like TLDBackends.
+	TLDTuning map[string]Tuning
+	Reserved  ReservedOptions
+	Zone      ZoneOptions
+	Prefilter PrefilterOptions
+	Telemetry TelemetryOptions
+	Log       LogOptions
+}
+
+// LogOptions configures the diagnostics tldx logs through slog.
+type LogOptions struct {
+	// Level is the least severe level logged: debug, info, warn or error.
+	Level string
+	// Format is text or json.
+	Format string
+}
+
+// TelemetryOptions configures where the resolver's OpenTelemetry spans and
+// metrics go. Both empty, they go nowhere.
+type TelemetryOptions struct {
+	// MetricsAddr is where to serve Prometheus metrics at /metrics, e.g.
+	// ":9464".
+	MetricsAddr string
+	// OTLPEndpoint is an OTLP/HTTP collector URL, e.g.
+	// "http://localhost:4318", for spans and metrics. The standard
+	// OTEL_EXPORTER_OTLP_* variables apply too, and setting
+	// OTEL_EXPORTER_OTLP_ENDPOINT is enough to turn exporting on.
+	OTLPEndpoint string
+}
+
+// PrefilterOptions configures the filter of known-taken domains consulted
+// before each lookup.
+type PrefilterOptions struct {
+	Enabled bool
+	// Path is where the filter is saved. Empty means prefilter.DefaultPath.
+	Path string
+	// Mode is "skip" or "defer", see the prefilter package.
+	Mode string
+	// Size is the number of domains the filter holds at FalsePositiveRate.
+	// Changing either starts a new, empty filter.
+	Size              int
+	FalsePositiveRate float64
+	// MaxAge is how long a filter is used before it is started afresh, so
+	// domains that were dropped get looked up again. Zero keeps it forever.
+	MaxAge time.Duration
+	// Reset deletes the saved filter before the run.
+	Reset bool
+}
+
+// ZoneOptions configures the zone backend.
+type ZoneOptions struct {
+	// Dir holds the imported zones. Empty means zone.DefaultDir.
+	Dir string
+	// ConfirmNegatives asks RDAP about names missing from the zone, since a
+	// registered name without nameservers is not delegated.
+	ConfirmNegatives bool
+}
+
+// ReservedOptions controls the reserved-name lists consulted when a lookup
+// says a domain is free.
+type ReservedOptions struct {
+	Disabled    bool
+	SkipBuiltin bool
+	// Files are user lists, checked after the built-in ones.
+	Files []string
+}
+
+// Tuning overrides the resolver settings for one TLD. Nil fields inherit the
+// global value. ConcurrencyLimit caps lookups for the TLD within the global
+// limit.
+type Tuning struct {
+	MaxRetries       *int
+	InitialBackoff   *time.Duration
+	MaxBackoff       *time.Duration
+	BackoffFactor    *float64
+	ContextTimeout   *time.Duration
+	ConcurrencyLimit *int
+}
+
+// RegistrarOptions holds the credentials for a registrar pricing API.
+type RegistrarOptions struct {
+	Provider  string
+	APIKey    string
+	APISecret string
+	Username  string
+	// Endpoint replaces the provider's API base URL, e.g. for a sandbox.
+	Endpoint string
+}
+
+// TransportOptions configures the HTTP client shared by RDAP bootstrap, RDAP,
+// DoH and registrar lookups, and the dialer used for WHOIS.
+type TransportOptions struct {
+	// Proxy overrides HTTP(S)_PROXY, e.g. "http://proxy:3128" or
+	// "socks5://proxy:1080". A SOCKS5 proxy is used for WHOIS too.
+	Proxy string
+	// WhoisProxy is a socks5:// proxy for WHOIS only.
+	WhoisProxy string
+	// CAFile is a PEM bundle trusted on top of the system roots.
+	CAFile    string
+	UserAgent string
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	// HTTP2Keepalive is how long an idle HTTP/2 connection waits before it
+	// is health-checked with a ping. Zero disables the pings.
+	HTTP2Keepalive time.Duration
+}
+
+// EPPOptions holds the registry connection for the EPP backend.
+type EPPOptions struct {
+	// Server is host:port, usually port 700.
+	Server   string
+	Username string
+	Password string
+	// MaxCheck is the server's limit of names per <check> command.
+	MaxCheck int
+	CertFile string
+	KeyFile  string
+	CAFile   string
+	Timeout  time.Duration
 }
 
 func NewTldxContext() *TldxContext {
@@ -40,6 +210,13 @@ func NewTldxContext() *TldxContext {
 			BackoffFactor:    1.5,
 			ContextTimeout:   15 * time.Second,
 			ConcurrencyLimit: 15,
+			MaxPriceCurrency: "USD",
+			Prefilter: PrefilterOptions{
+				Mode:              "defer",
+				Size:              1_000_000,
+				FalsePositiveRate: 0.01,
+				MaxAge:            30 * 24 * time.Hour,
+			},
 		},
 	}
 }
@@ -3,6 +3,9 @@ package domain
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"strings"
 
 	"github.com/brandonyoungdev/tldx/internal/composer"
 	"github.com/brandonyoungdev/tldx/internal/config"
@@ -10,36 +13,60 @@ import (
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 )
 
-func Exec(ctx context.Context, app *config.TldxContext, domainsOrKeywords []string, opts ...resolver.ResolverOption) bool {
+// Exec checks the domains or keywords and writes the results. It reports
+// whether anything matched the run's filters, and fails only when the
+// results could not be written anywhere.
+func Exec(ctx context.Context, app *config.TldxContext, domainsOrKeywords []string, opts ...resolver.ResolverOption) (bool, error) {
 
 	composerService := composer.NewComposerService(app)
 	specs, warnings := composerService.Compile(domainsOrKeywords)
-	styleService := output.NewStyleService(app)
-	if warnings != nil && len(warnings) > 0 {
-		for _, warning := range warnings {
-			if !app.Config.OnlyAvailable && app.Config.OutputFormat == "text" {
-				fmt.Println(styleService.Styled(warning.Error(), "11")) // Yellow
-			}
-		}
-	}
+	composer.LogWarnings(warnings)
 
 	if app.Config.DryRun {
 		fmt.Printf("Would check %d domain(s):\n", len(specs))
 		for _, spec := range specs {
 			fmt.Printf("  %s\n", spec.Domain)
 		}
-		return false
+		return false, nil
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	quotas := NewQuotas(app.Config.LimitPerTLD, app.Config.LimitPerKeyword, specs)
+	if app.Config.Limit > 0 {
+		// --limit keeps the first available domains by --order, not the
+		// first lookups to finish.
+		opts = append(opts, resolver.WithOrderedResults())
+	}
+
+	stats := output.NewStats(len(specs))
+	stats.Keywords = domainsOrKeywords
+	for _, warning := range warnings {
+		stats.Warnings = append(stats.Warnings, warning.Error())
+	}
+	sinks, err := openSinks(app, stats)
+	if err != nil {
+		slog.Error("Failed to open output files", "error", err)
+		return false, err
+	}
+
+	progress := output.NewProgress(app.Config, stats.Total)
+	if quotas != nil {
+		opts = append(opts, resolver.WithSkip(func(spec resolver.DomainSpec) bool {
+			if quotas.Skip(spec) {
+				progress.Skip()
+				return true
+			}
+			return false
+		}))
+	}
+
 	resolverService := resolver.NewResolverService(app, opts...)
+	defer resolverService.Close()
 	resultChan := resolverService.CheckDomainsStreaming(ctx, specs)
 
-	outputWriter := output.GetOutputWriter(app)
-
-	output.Stat.Total = len(specs)
+	outputWriter := progress.Wrap(sinks)
 	foundAvailable := false
 	foundForSale := false
 	availableCount := 0
@@ -51,53 +78,69 @@ func Exec(ctx context.Context, app *config.TldxContext, domainsOrKeywords []stri
 	for result := range resultChan {
 		select {
 		case <-ctx.Done():
-			if app.Config.Verbose {
-				fmt.Println(styleService.Styled("\\nOperation cancelled", "11"))
-			}
+			progress.Done()
+			slog.Error("Operation cancelled")
+			stats.Stopped = output.StoppedCancelled
+			finishStats(stats, resolverService, quotas)
 			outputWriter.Flush()
-			if app.Config.ShowStats && app.Config.OutputFormat == "text" {
-				fmt.Println(output.RenderStatsSummary())
-			}
-			return foundMatch()
+			output.WriteStats(app.Config, stats, os.Stdout, os.Stderr)
+			return foundMatch(), nil
 		default:
 		}
-
-		if result.Error != nil {
-			output.Stat.Errored++
-		} else if result.Available {
-			output.Stat.Available++
+		counts := result.Error == nil && result.Available && WithinMaxPrice(app.Config, result)
+		if counts && !quotas.Take(result) {
+			// Its quota filled while it was being looked up. It is counted
+			// as skipped by the quota, not in the stats or the progress.
+			progress.Skip()
+			continue
+		}
+		progress.Update(result.Error == nil && result.Available, result.Error != nil)
+		stats.Add(result)
+		if counts {
 			foundAvailable = true
 			availableCount++
-		} else {
-			output.Stat.NotAvailable++
 		}
 		if result.ForSale != nil {
-			output.Stat.ForSale++
 			foundForSale = true
 		}
 
+		outputWriter.Write(result)
 		if !ShouldDisplay(app.Config, result) {
 			continue
 		}
-		outputWriter.Write(result)
 
-		if app.Config.Limit > 0 && availableCount >= app.Config.Limit {
+		if (app.Config.Limit > 0 && availableCount >= app.Config.Limit) || quotas.AllMet() {
+			stats.Stopped = output.StoppedLimit
 			cancel()
 			break
 		}
 	}
+	if stats.Stopped == "" && ctx.Err() != nil {
+		// Cancelled after the last result came in.
+		stats.Stopped = output.StoppedCancelled
+	}
 
+	// Finished before Flush, for the formats that include the stats.
+	finishStats(stats, resolverService, quotas)
 	outputWriter.Flush()
+	output.WriteStats(app.Config, stats, os.Stdout, os.Stderr)
 
-	if app.Config.ShowStats && app.Config.OutputFormat == "text" {
-		fmt.Println(output.RenderStatsSummary())
-	}
+	return foundMatch(), nil
+}
 
-	return foundMatch()
+// finishStats adds what the resolver and the quotas know to a run's stats.
+func finishStats(stats *output.Stats, resolverService *resolver.ResolverService, quotas *Quotas) {
+	stats.Prefiltered = resolverService.PrefilterStats().Saved
+	stats.Quotas, stats.QuotaSkipped = quotas.Report(), quotas.Skipped()
+	stats.Finish()
 }
 
-// ShouldDisplay applies the --only-* filters. Shared with the MCP server.
+// ShouldDisplay applies the --only-* and --max-price filters. Shared with the
+// MCP server.
 func ShouldDisplay(cfg *config.TldxConfigOptions, result resolver.DomainResult) bool {
+	if result.Available && !WithinMaxPrice(cfg, result) {
+		return false
+	}
 	if !cfg.OnlyAvailable && !cfg.OnlyForSale {
 		return true
 	}
@@ -109,3 +152,17 @@ func ShouldDisplay(cfg *config.TldxConfigOptions, result resolver.DomainResult)
 	}
 	return false
 }
+
+// WithinMaxPrice reports whether an available domain fits --max-price. With a
+// cap set, a domain without a quote does not fit: it may be a premium name.
+// Nor does one quoted in a currency other than the cap's, since the amounts
+// cannot be compared.
+func WithinMaxPrice(cfg *config.TldxConfigOptions, result resolver.DomainResult) bool {
+	if cfg.MaxPrice <= 0 {
+		return true
+	}
+	if result.Pricing == nil || !strings.EqualFold(result.Pricing.Currency, cfg.MaxPriceCurrency) {
+		return false
+	}
+	return result.Pricing.Registration <= cfg.MaxPrice
+}
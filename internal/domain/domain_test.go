@@ -3,8 +3,10 @@ package domain_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"sync/atomic"
@@ -13,6 +15,8 @@ import (
 
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/domain"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 	"github.com/openrdap/rdap"
 	"github.com/stretchr/testify/assert"
@@ -41,13 +45,36 @@ func captureStdout(fn func()) string {
 	return buf.String()
 }
 
+func captureStderr(fn func()) string {
+	old := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	fn()
+	w.Close()
+	os.Stderr = old
+	var buf bytes.Buffer
+	io.Copy(&buf, r) //nolint:errcheck
+	return buf.String()
+}
+
+// captureLogs returns what fn logs through the default slog logger.
+func captureLogs(fn func()) string {
+	old := slog.Default()
+	defer slog.SetDefault(old)
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	fn()
+	return buf.String()
+}
+
 func TestExec_DryRun(t *testing.T) {
 	app := config.NewTldxContext()
 	app.Config.DryRun = true
 	app.Config.TLDs = []string{"com", "io"}
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"test"})
+		result, err := domain.Exec(context.Background(), app, []string{"test"})
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -62,11 +89,15 @@ func TestExec_DryRun_WithWarnings(t *testing.T) {
 	app.Config.TLDs = []string{"notavalidtld!@#"}
 	app.Config.OutputFormat = "text"
 
-	out := captureStdout(func() {
-		domain.Exec(context.Background(), app, []string{"test"})
+	var out string
+	logs := captureLogs(func() {
+		out = captureStdout(func() {
+			domain.Exec(context.Background(), app, []string{"test"})
+		})
 	})
-	// Should produce some output even with invalid TLD warnings
-	_ = out
+	assert.Contains(t, out, "Would check")
+	assert.NotContains(t, out, "invalid TLD", "warnings belong on the log, not with the results")
+	assert.Contains(t, logs, `level=WARN msg="invalid TLD" tld=notavalidtld!@#`)
 }
 
 func TestExec_Available(t *testing.T) {
@@ -80,8 +111,9 @@ func TestExec_Available(t *testing.T) {
 	}
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"test"},
+		result, err := domain.Exec(context.Background(), app, []string{"test"},
 			resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
 		assert.True(t, result)
 	})
 
@@ -100,8 +132,9 @@ func TestExec_NotAvailable(t *testing.T) {
 	}
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"test"},
+		result, err := domain.Exec(context.Background(), app, []string{"test"},
 			resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -120,8 +153,9 @@ func TestExec_OnlyAvailable_FiltersNotAvailable(t *testing.T) {
 	}
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"taken"},
+		result, err := domain.Exec(context.Background(), app, []string{"taken"},
 			resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -140,12 +174,55 @@ func TestExec_Limit_StopsEarly(t *testing.T) {
 	}
 
 	captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"test"},
+		result, err := domain.Exec(context.Background(), app, []string{"test"},
 			resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
 		assert.True(t, result)
 	})
 }
 
+// slowFirstRDAP holds the lookup of first until every other lookup has
+// answered, all of them "not found".
+type slowFirstRDAP struct {
+	first   string
+	others  int32
+	calls   atomic.Int32
+	answers chan struct{}
+}
+
+func (m *slowFirstRDAP) Do(req *rdap.Request) (*rdap.Response, error) {
+	if req.Query == m.first {
+		select {
+		case <-m.answers:
+		case <-time.After(time.Second):
+		}
+	} else if m.calls.Add(1) == m.others {
+		close(m.answers)
+	}
+	return nil, fmt.Errorf("object does not exist.")
+}
+
+func TestExec_Limit_KeepsTheFirstByOrderUnderConcurrency(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io", "net", "dev"}
+	app.Config.MaxRetries = 0
+	app.Config.ConcurrencyLimit = 4
+	app.Config.OutputFormat = "json-stream"
+	app.Config.Reserved.Disabled = true
+	app.Config.Limit = 1
+
+	mock := &slowFirstRDAP{first: "test.com", others: 3, answers: make(chan struct{})}
+
+	out := captureStdout(func() {
+		_, err := domain.Exec(context.Background(), app, []string{"test"}, resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 1, out)
+	assert.Contains(t, lines[0], `"domain":"test.com"`, "the first candidate wins even though it answered last")
+}
+
 func TestExec_ShowStats_Text(t *testing.T) {
 	app := config.NewTldxContext()
 	app.Config.TLDs = []string{"com"}
@@ -335,9 +412,10 @@ func TestExec_ForSale_AnnotatesTakenDomain(t *testing.T) {
 	app.Config.CheckForSale = true
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"taken"},
+		result, err := domain.Exec(context.Background(), app, []string{"taken"},
 			resolver.WithRDAPQuerier(takenRDAP()),
 			resolver.WithTXTLookup(forSaleTXT("v=FORSALE1;fval=USD750")))
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -379,9 +457,10 @@ func TestExec_OnlyForSale_FiltersAndSucceeds(t *testing.T) {
 	}
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"taken"},
+		result, err := domain.Exec(context.Background(), app, []string{"taken"},
 			resolver.WithRDAPQuerier(takenRDAP()),
 			resolver.WithTXTLookup(txt))
+		require.NoError(t, err)
 		assert.True(t, result)
 	})
 
@@ -399,9 +478,10 @@ func TestExec_OnlyForSale_NoHitsIsAFailure(t *testing.T) {
 	app.Config.OnlyForSale = true
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"taken"},
+		result, err := domain.Exec(context.Background(), app, []string{"taken"},
 			resolver.WithRDAPQuerier(takenRDAP()),
 			resolver.WithTXTLookup(forSaleTXT("v=spf1 -all")))
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -418,9 +498,10 @@ func TestExec_OnlyAvailableWithForSale_KeepsAvailableSemantics(t *testing.T) {
 	app.Config.OnlyAvailable = true
 
 	out := captureStdout(func() {
-		result := domain.Exec(context.Background(), app, []string{"taken"},
+		result, err := domain.Exec(context.Background(), app, []string{"taken"},
 			resolver.WithRDAPQuerier(takenRDAP()),
 			resolver.WithTXTLookup(forSaleTXT("v=FORSALE1;fval=USD750")))
+		require.NoError(t, err)
 		assert.False(t, result)
 	})
 
@@ -482,10 +563,213 @@ func TestExec_CancelledMidRun(t *testing.T) {
 	defer cancel()
 	mock := &cancellingRDAP{after: 32, cancel: cancel}
 
-	out := captureStdout(func() {
-		assert.False(t, domain.Exec(ctx, app, keywords, resolver.WithRDAPQuerier(mock)))
+	var out string
+	logs := captureLogs(func() {
+		out = captureStdout(func() {
+			found, err := domain.Exec(ctx, app, keywords, resolver.WithRDAPQuerier(mock))
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
 	})
 
-	assert.Contains(t, out, "Operation cancelled")
+	assert.Contains(t, logs, `level=ERROR msg="Operation cancelled"`)
+	assert.NotContains(t, out, "Operation cancelled")
 	assert.Less(t, int(mock.calls.Load()), len(keywords), "the run should stop short")
 }
+
+// quoteRegistrar prices every domain at a fixed amount, premium above 1000.
+type quoteRegistrar map[string]float64
+
+func (q quoteRegistrar) Check(_ context.Context, domain string) (registrar.Quote, error) {
+	price, ok := q[domain]
+	if !ok {
+		return registrar.Quote{}, fmt.Errorf("no quote for %s", domain)
+	}
+	return registrar.Quote{
+		Available: true,
+		Pricing:   registrar.Pricing{Registration: price, Currency: "USD", Premium: price > 1000, Provider: "test"},
+	}, nil
+}
+
+func TestExec_MaxPrice_HidesExpensiveAndUnquotedDomains(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io", "ai"}
+	app.Config.MaxRetries = 0
+	app.Config.NoColor = true
+	app.Config.CheckPricing = true
+	app.Config.MaxPrice = 20
+
+	out := captureStdout(func() {
+		result, err := domain.Exec(context.Background(), app, []string{"test"},
+			resolver.WithRDAPQuerier(&mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}),
+			resolver.WithRegistrar(quoteRegistrar{"test.com": 9.68, "test.io": 5000}),
+		)
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	assert.Contains(t, out, "test.com is available — USD 9.68")
+	assert.NotContains(t, out, "test.io", "over the cap")
+	assert.NotContains(t, out, "test.ai", "no quote, so possibly premium")
+}
+
+func TestExec_MaxPrice_NothingAffordableIsAFailure(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"io"}
+	app.Config.MaxRetries = 0
+	app.Config.NoColor = true
+	app.Config.CheckPricing = true
+	app.Config.MaxPrice = 20
+
+	captureStdout(func() {
+		result, err := domain.Exec(context.Background(), app, []string{"test"},
+			resolver.WithRDAPQuerier(&mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}),
+			resolver.WithRegistrar(quoteRegistrar{"test.io": 5000}),
+		)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+}
+
+func TestWithinMaxPrice(t *testing.T) {
+	cfg := config.NewTldxContext().Config
+	unpriced := resolver.DomainResult{Domain: "a.com", Available: true}
+	assert.True(t, domain.WithinMaxPrice(cfg, unpriced), "no cap means everything fits")
+
+	cfg.MaxPrice = 10
+	assert.False(t, domain.WithinMaxPrice(cfg, unpriced))
+
+	cheap := unpriced
+	cheap.Pricing = &registrar.Pricing{Registration: 10, Currency: "USD"}
+	assert.True(t, domain.WithinMaxPrice(cfg, cheap), "the cap is inclusive")
+
+	euros := unpriced
+	euros.Pricing = &registrar.Pricing{Registration: 5, Currency: "EUR"}
+	assert.False(t, domain.WithinMaxPrice(cfg, euros), "a price in another currency cannot be compared")
+
+	cfg.MaxPriceCurrency = "eur"
+	assert.True(t, domain.WithinMaxPrice(cfg, euros))
+	assert.False(t, domain.WithinMaxPrice(cfg, cheap))
+}
+
+func TestExec_FailsWhenAnOutputFileCannotBeOpened(t *testing.T) {
+	blocker := t.TempDir() + "/file"
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com"}
+	app.Config.OnlyAvailable = true
+	app.Config.Tee = []string{"csv=" + blocker + "/available.csv"}
+
+	mock := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
+
+	var found bool
+	var err error
+	captureLogs(func() {
+		found, err = domain.Exec(context.Background(), app, []string{"test"}, resolver.WithRDAPQuerier(mock))
+	})
+	assert.Error(t, err, "a tee that cannot be opened fails the run")
+	assert.False(t, found)
+}
+
+func TestExec_OutputFileAndTee(t *testing.T) {
+	dir := t.TempDir()
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.MaxRetries = 0
+	app.Config.OutputFormat = "text"
+	app.Config.OutputFile = dir + "/all.txt"
+	app.Config.Tee = []string{"csv=" + dir + "/available.csv,filter=available"}
+
+	mock := &mockRDAPQuerier{
+		resp: &rdap.Response{Object: &rdap.Domain{}},
+	}
+
+	out := captureStdout(func() {
+		domain.Exec(context.Background(), app, []string{"taken"},
+			resolver.WithRDAPQuerier(mock))
+	})
+	assert.NotContains(t, out, "taken.com", "results go to the file, not stdout")
+
+	all, err := os.ReadFile(dir + "/all.txt")
+	require.NoError(t, err)
+	assert.Contains(t, string(all), "taken.com")
+	assert.Contains(t, string(all), "taken.io")
+	assert.NotContains(t, string(all), "\x1b[", "files are written without color")
+
+	available, err := os.ReadFile(dir + "/available.csv")
+	require.NoError(t, err)
+	assert.Contains(t, string(available), "domain")
+	assert.NotContains(t, string(available), "taken.com")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files left behind")
+}
+
+func TestExec_StatsArePerRun(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com"}
+	app.Config.MaxRetries = 0
+	app.Config.OutputFormat = "json-array"
+	app.Config.ShowStats = true
+
+	mock := &mockRDAPQuerier{
+		err: fmt.Errorf("object does not exist."),
+	}
+
+	for range 2 {
+		out := captureStdout(func() {
+			domain.Exec(context.Background(), app, []string{"test"},
+				resolver.WithRDAPQuerier(mock))
+		})
+
+		var payload struct {
+			Stats struct {
+				Total, Available int
+				Sources          map[string]int
+			}
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &payload))
+		assert.Equal(t, 1, payload.Stats.Total)
+		assert.Equal(t, 1, payload.Stats.Available, "a second run starts from zero")
+		assert.Equal(t, map[string]int{"rdap": 1}, payload.Stats.Sources)
+	}
+}
+
+func TestExec_NDJSONSummaryReportsHowTheRunEnded(t *testing.T) {
+	run := func(limit int) map[string]any {
+		app := config.NewTldxContext()
+		app.Config.TLDs = []string{"com", "io", "nope!"}
+		app.Config.MaxRetries = 0
+		app.Config.ConcurrencyLimit = 1
+		app.Config.OutputFormat = output.FormatNDJSONSummary
+		app.Config.Limit = limit
+
+		mock := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
+		var out string
+		captureLogs(func() {
+			out = captureStdout(func() {
+				domain.Exec(context.Background(), app, []string{"test"}, resolver.WithRDAPQuerier(mock))
+			})
+		})
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		for _, line := range lines[:len(lines)-1] {
+			assert.Contains(t, line, `"type":"result"`)
+		}
+		var summary map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &summary))
+		assert.Equal(t, "summary", summary["type"])
+		assert.Equal(t, []any{"invalid TLD: nope!"}, summary["warnings"])
+		return summary
+	}
+
+	summary := run(0)
+	assert.Equal(t, true, summary["complete"])
+	assert.NotContains(t, summary, "stopped")
+
+	summary = run(1)
+	assert.Equal(t, false, summary["complete"])
+	assert.Equal(t, output.StoppedLimit, summary["stopped"])
+}
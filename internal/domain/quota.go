@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// Quota kinds, as reported in output.QuotaStatus.
+const (
+	QuotaTLD     = "tld"
+	QuotaKeyword = "keyword"
+)
+
+// Quotas caps the available domains found per TLD and per keyword, for
+// --limit-per-tld and --limit-per-keyword. Once a TLD or keyword has its
+// quota, the scheduler skips its remaining specs. Shared with the MCP
+// server; safe for concurrent use. A nil *Quotas caps nothing.
+type Quotas struct {
+	perTLD, perKeyword int
+
+	mu       sync.Mutex
+	tlds     *counter
+	keywords *counter
+	skipped  int
+}
+
+// counter keeps counts by key in the order keys were first seen, which is
+// the order they are reported in.
+type counter struct {
+	keys  []string
+	found map[string]int
+}
+
+func newCounter() *counter {
+	return &counter{found: make(map[string]int)}
+}
+
+func (c *counter) register(key string) {
+	if _, ok := c.found[key]; !ok {
+		c.keys = append(c.keys, key)
+		c.found[key] = 0
+	}
+}
+
+func (c *counter) allAt(limit int) bool {
+	for _, key := range c.keys {
+		if c.found[key] < limit {
+			return false
+		}
+	}
+	return true
+}
+
+// NewQuotas returns the quotas for specs, or nil if neither limit is set.
+func NewQuotas(perTLD, perKeyword int, specs []resolver.DomainSpec) *Quotas {
+	if perTLD <= 0 && perKeyword <= 0 {
+		return nil
+	}
+	q := &Quotas{perTLD: perTLD, perKeyword: perKeyword, tlds: newCounter(), keywords: newCounter()}
+	for _, spec := range specs {
+		q.tlds.register(tldKey(spec.Domain, spec.TLD))
+		q.keywords.register(spec.Keyword)
+	}
+	return q
+}
+
+// Skip reports whether spec's TLD or keyword already has its quota. Pass it
+// to resolver.WithSkip.
+func (q *Quotas) Skip(spec resolver.DomainSpec) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.full(tldKey(spec.Domain, spec.TLD), spec.Keyword) {
+		q.skipped++
+		return true
+	}
+	return false
+}
+
+func (q *Quotas) full(tld, keyword string) bool {
+	return (q.perTLD > 0 && q.tlds.found[tld] >= q.perTLD) ||
+		(q.perKeyword > 0 && q.keywords.found[keyword] >= q.perKeyword)
+}
+
+// Take counts an available domain towards its quotas. It reports false when
+// the domain's lookup was already under way as its quota filled; such a
+// domain is over the cap, not shown, and counted as skipped.
+func (q *Quotas) Take(result resolver.DomainResult) bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	tld := tldKey(result.Domain, result.TLD)
+	if q.full(tld, result.Keyword) {
+		q.skipped++
+		return false
+	}
+	q.tlds.found[tld]++
+	q.keywords.found[result.Keyword]++
+	return true
+}
+
+// AllMet reports whether every spec is now skipped: every TLD has its quota,
+// or every keyword has.
+func (q *Quotas) AllMet() bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return (q.perTLD > 0 && q.tlds.allAt(q.perTLD)) ||
+		(q.perKeyword > 0 && q.keywords.allAt(q.perKeyword))
+}
+
+// Skipped is the number of specs a quota left out: never looked up, or
+// dropped by Take.
+func (q *Quotas) Skipped() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.skipped
+}
+
+// Report lists every quota with how far it got.
+func (q *Quotas) Report() []output.QuotaStatus {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var out []output.QuotaStatus
+	add := func(kind string, c *counter, limit int) {
+		if limit <= 0 {
+			return
+		}
+		for _, key := range c.keys {
+			found := c.found[key]
+			out = append(out, output.QuotaStatus{Kind: kind, Key: key, Found: found, Limit: limit, Met: found >= limit})
+		}
+	}
+	add(QuotaTLD, q.tlds, q.perTLD)
+	add(QuotaKeyword, q.keywords, q.perKeyword)
+	return out
+}
+
+// tldKey is the TLD a quota is kept under. Specs built from a list of
+// domains carry no TLD, so it is read off the domain.
+func tldKey(domain, tld string) string {
+	if tld != "" {
+		return tld
+	}
+	_, zone, _ := strings.Cut(domain, ".")
+	return zone
+}
@@ -0,0 +1,174 @@
+package domain_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/domain"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/openrdap/rdap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spec(keyword, tld string) resolver.DomainSpec {
+	return resolver.DomainSpec{Domain: keyword + "." + tld, Keyword: keyword, TLD: tld}
+}
+
+func available(s resolver.DomainSpec) resolver.DomainResult {
+	return resolver.DomainResult{Domain: s.Domain, Keyword: s.Keyword, TLD: s.TLD, Available: true}
+}
+
+func TestQuotas(t *testing.T) {
+	specs := []resolver.DomainSpec{spec("acme", "com"), spec("acme", "io"), spec("zeta", "com"), spec("zeta", "io")}
+	q := domain.NewQuotas(1, 0, specs)
+
+	assert.False(t, q.Skip(specs[0]))
+	assert.True(t, q.Take(available(specs[0])))
+	assert.True(t, q.Skip(specs[2]), ".com has its one domain")
+	assert.False(t, q.Take(available(specs[2])), "an answer already in flight is over the quota")
+	assert.False(t, q.AllMet(), ".io has none yet")
+
+	assert.True(t, q.Take(available(specs[1])))
+	assert.True(t, q.AllMet())
+	assert.Equal(t, 2, q.Skipped(), "the skipped spec and the answer dropped in flight")
+	assert.Equal(t, []output.QuotaStatus{
+		{Kind: domain.QuotaTLD, Key: "com", Found: 1, Limit: 1, Met: true},
+		{Kind: domain.QuotaTLD, Key: "io", Found: 1, Limit: 1, Met: true},
+	}, q.Report())
+}
+
+func TestQuotas_PerKeyword(t *testing.T) {
+	specs := []resolver.DomainSpec{spec("acme", "com"), spec("acme", "io"), spec("zeta", "com")}
+	q := domain.NewQuotas(0, 1, specs)
+
+	q.Take(available(specs[0]))
+	assert.True(t, q.Skip(specs[1]))
+	assert.False(t, q.Skip(specs[2]))
+	assert.Equal(t, []output.QuotaStatus{
+		{Kind: domain.QuotaKeyword, Key: "acme", Found: 1, Limit: 1, Met: true},
+		{Kind: domain.QuotaKeyword, Key: "zeta", Found: 0, Limit: 1, Met: false},
+	}, q.Report())
+}
+
+func TestQuotas_NilCapsNothing(t *testing.T) {
+	q := domain.NewQuotas(0, 0, []resolver.DomainSpec{spec("acme", "com")})
+	assert.Nil(t, q)
+	assert.False(t, q.Skip(spec("acme", "com")))
+	assert.True(t, q.Take(available(spec("acme", "com"))))
+	assert.False(t, q.AllMet())
+	assert.Empty(t, q.Report())
+}
+
+func TestExec_LimitPerTLD(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.MaxRetries = 0
+	app.Config.NoColor = true
+	app.Config.ConcurrencyLimit = 1
+	app.Config.LimitPerTLD = 2
+	app.Config.ShowStats = true
+	app.Config.OutputFormat = "text"
+	app.Config.Reserved.Disabled = true
+
+	mock := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
+
+	out := captureStdout(func() {
+		found, err := domain.Exec(context.Background(), app, []string{"alpha", "bravo", "charlie", "delta"},
+			resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	assert.Equal(t, 2, strings.Count(out, ".com is available"), out)
+	assert.Equal(t, 2, strings.Count(out, ".io is available"), out)
+	assert.NotContains(t, out, "charlie")
+	assert.Contains(t, out, "Per-TLD quotas: .com 2/2  .io 2/2")
+}
+
+func TestExec_StatsLeaveOutResultsDroppedByAQuota(t *testing.T) {
+	app := config.NewTldxContext()
+	// Every .io name is taken, so .io never meets its quota and every .com
+	// answer is read.
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.MaxRetries = 0
+	app.Config.ConcurrencyLimit = 8
+	app.Config.LimitPerTLD = 1
+	app.Config.ShowStats = true
+	app.Config.OutputFormat = "json-array"
+	app.Config.Reserved.Disabled = true
+
+	keywords := []string{"alpha", "bravo", "charlie", "delta"}
+	mock := &barrierRDAP{waiting: make(chan struct{}), want: int32(2 * len(keywords))}
+
+	out := captureStdout(func() {
+		_, err := domain.Exec(context.Background(), app, keywords, resolver.WithRDAPQuerier(mock))
+		require.NoError(t, err)
+	})
+
+	var payload struct {
+		Results []map[string]any `json:"results"`
+		Stats   map[string]any   `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &payload), out)
+	assert.Len(t, payload.Results, 1+len(keywords), "one .com and every .io")
+	assert.EqualValues(t, 1, payload.Stats["Available"], "only the result shown counts as available")
+	assert.EqualValues(t, 1, payload.Stats["TLDs"].(map[string]any)["com"].(map[string]any)["Available"])
+	assert.EqualValues(t, len(keywords)-1, payload.Stats["QuotaSkipped"], "the other .com answers were dropped")
+}
+
+func TestExec_ProgressLeavesOutResultsDroppedByAQuota(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.MaxRetries = 0
+	app.Config.ConcurrencyLimit = 8
+	app.Config.LimitPerTLD = 1
+	app.Config.Progress = true
+	app.Config.OutputFormat = "text"
+	app.Config.Reserved.Disabled = true
+
+	keywords := []string{"alpha", "bravo", "charlie", "delta"}
+	mock := &barrierRDAP{waiting: make(chan struct{}), want: int32(2 * len(keywords))}
+
+	progress := captureStderr(func() {
+		captureStdout(func() {
+			_, err := domain.Exec(context.Background(), app, keywords, resolver.WithRDAPQuerier(mock))
+			require.NoError(t, err)
+		})
+	})
+
+	lines := strings.Split(strings.TrimSuffix(progress, "\n"), "\n")
+	last := lines[len(lines)-1]
+	assert.True(t, strings.HasPrefix(last, "⏳ 5/5 checked"), "the three dropped .com answers are off the total: %q", last)
+	assert.True(t, strings.HasSuffix(last, "· 1 available"), "only the result shown counts as available: %q", last)
+}
+
+// barrierRDAP answers once every lookup is under way, so all of them come
+// back after the first has filled its quota: .io names are registered, the
+// rest not found.
+type barrierRDAP struct {
+	want    int32
+	calls   atomic.Int32
+	waiting chan struct{}
+}
+
+func (m *barrierRDAP) Do(req *rdap.Request) (*rdap.Response, error) {
+	if m.calls.Add(1) == m.want {
+		close(m.waiting)
+	}
+	select {
+	case <-m.waiting:
+	case <-time.After(time.Second):
+	}
+	if strings.HasSuffix(req.Query, ".io") {
+		return &rdap.Response{Object: &rdap.Domain{}}, nil
+	}
+	return nil, fmt.Errorf("object does not exist.")
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// sink is one destination of a run's results, with the filters it was
+// given.
+type sink struct {
+	cfg *config.TldxConfigOptions
+	out output.ResultOutput
+}
+
+// fanOut writes each result to every sink whose filters let it through.
+type fanOut []sink
+
+func (f fanOut) Write(result resolver.DomainResult) {
+	for _, s := range f {
+		if ShouldDisplay(s.cfg, result) {
+			s.out.Write(result)
+		}
+	}
+}
+
+func (f fanOut) Flush() {
+	for _, s := range f {
+		s.out.Flush()
+	}
+}
+
+// openSinks sets up the run's outputs: stdout, or the --output file, in the
+// run's format and filters, and one file per --tee.
+func openSinks(app *config.TldxContext, stats *output.Stats) (fanOut, error) {
+	var sinks fanOut
+	var err error
+	open := func(cfg *config.TldxConfigOptions, format, path string) {
+		if err != nil {
+			return
+		}
+		var out output.ResultOutput
+		out, err = output.NewFileOutput(&config.TldxContext{Config: cfg}, format, path, stats)
+		if err == nil {
+			sinks = append(sinks, sink{cfg: cfg, out: out})
+		}
+	}
+
+	if app.Config.OutputFile != "" {
+		cfg := *app.Config
+		cfg.NoColor = true
+		open(&cfg, app.Config.OutputFormat, app.Config.OutputFile)
+	} else {
+		sinks = append(sinks, sink{cfg: app.Config, out: output.GetOutputWriter(app, stats)})
+	}
+
+	for _, spec := range app.Config.Tee {
+		tee, parseErr := output.ParseTee(spec)
+		if parseErr != nil {
+			err = errors.Join(err, parseErr)
+			break
+		}
+		open(tee.Config(app.Config), tee.Format, tee.Path)
+	}
+
+	if err != nil {
+		sinks.discard()
+		return nil, err
+	}
+	return sinks, nil
+}
+
+// discard removes the temporary files of sinks that will not be flushed.
+func (f fanOut) discard() {
+	for _, s := range f {
+		if file, ok := s.out.(*output.FileOutput); ok {
+			file.Discard()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package epp is a minimal EPP (RFC 5730) client for the domain <check>
+// command (RFC 5731), over the TLS transport of RFC 5734. Registrars with
+// registry credentials get the authoritative availability answer from it,
+// including why a name cannot be registered.
+package epp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	eppNS    = "urn:ietf:params:xml:ns:epp-1.0"
+	domainNS = "urn:ietf:params:xml:ns:domain-1.0"
+
+	// maxFrameSize bounds a server's claimed frame length, so a corrupt
+	// header cannot make us allocate gigabytes.
+	maxFrameSize = 4 << 20
+
+	codeSuccess       = 1000
+	codeSuccessEnding = 1500
+)
+
+// Result is the <domain:cd> answer for one name.
+type Result struct {
+	Name      string
+	Available bool
+	// Reason is the server's explanation when the name is unavailable,
+	// e.g. "In use" or "Reserved".
+	Reason string
+}
+
+// ResultError is a non-success EPP result code, e.g. 2200 for failed
+// authentication.
+type ResultError struct {
+	Code    int
+	Message string
+}
+
+func (e *ResultError) Error() string {
+	return fmt.Sprintf("epp: %d %s", e.Code, e.Message)
+}
+
+// readFrame reads one RFC 5734 data unit: a 4-byte big-endian length that
+// counts itself, followed by the XML.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	total := binary.BigEndian.Uint32(header[:])
+	if total < 4 || total > maxFrameSize {
+		return nil, fmt.Errorf("epp: invalid frame length %d", total)
+	}
+
+	payload := make([]byte, total-4)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func writeFrame(w io.Writer, payload []byte) error {
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)))
+	copy(frame[4:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s)) //nolint:errcheck // strings.Builder never fails
+	return b.String()
+}
+
+func command(inner, clTRID string) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>`)
+	b.WriteString(`<epp xmlns="` + eppNS + `"><command>`)
+	b.WriteString(inner)
+	b.WriteString(`<clTRID>` + escape(clTRID) + `</clTRID></command></epp>`)
+	return b.Bytes()
+}
+
+func loginCommand(username, password, clTRID string) []byte {
+	return command(
+		`<login><clID>`+escape(username)+`</clID><pw>`+escape(password)+`</pw>`+
+			`<options><version>1.0</version><lang>en</lang></options>`+
+			`<svcs><objURI>`+domainNS+`</objURI></svcs></login>`,
+		clTRID)
+}
+
+func checkCommand(names []string, clTRID string) []byte {
+	var b strings.Builder
+	b.WriteString(`<check><domain:check xmlns:domain="` + domainNS + `">`)
+	for _, name := range names {
+		b.WriteString(`<domain:name>` + escape(name) + `</domain:name>`)
+	}
+	b.WriteString(`</domain:check></check>`)
+	return command(b.String(), clTRID)
+}
+
+func logoutCommand(clTRID string) []byte {
+	return command(`<logout/>`, clTRID)
+}
+
+// response covers the greeting and the responses this client reads. Element
+// names are matched without their namespace prefixes.
+type response struct {
+	Greeting *struct{} `xml:"greeting"`
+	Results  []struct {
+		Code int    `xml:"code,attr"`
+		Msg  string `xml:"msg"`
+	} `xml:"response>result"`
+	CheckData []struct {
+		Name struct {
+			Value string `xml:",chardata"`
+			Avail string `xml:"avail,attr"`
+		} `xml:"name"`
+		Reason string `xml:"reason"`
+	} `xml:"response>resData>chkData>cd"`
+}
+
+func parseResponse(payload []byte) (*response, error) {
+	var resp response
+	if err := xml.Unmarshal(payload, &resp); err != nil {
+		return nil, fmt.Errorf("epp: parse response: %w", err)
+	}
+	return &resp, nil
+}
+
+// err returns the first non-success result, if any.
+func (r *response) err() error {
+	if len(r.Results) == 0 {
+		return errors.New("epp: response carries no result")
+	}
+	for _, res := range r.Results {
+		if res.Code != codeSuccess && res.Code != codeSuccessEnding {
+			return &ResultError{Code: res.Code, Message: strings.TrimSpace(res.Msg)}
+		}
+	}
+	return nil
+}
+
+func (r *response) checkResults() []Result {
+	out := make([]Result, 0, len(r.CheckData))
+	for _, cd := range r.CheckData {
+		avail := strings.TrimSpace(cd.Name.Avail)
+		out = append(out, Result{
+			Name:      strings.TrimSpace(cd.Name.Value),
+			Available: avail == "1" || avail == "true",
+			Reason:    strings.TrimSpace(cd.Reason),
+		})
+	}
+	return out
+}
@@ -0,0 +1,322 @@
+package epp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+const (
+	// DefaultMaxCheck is how many names go in one <check> when the server's
+	// limit is not configured. Most registries accept at least this many.
+	DefaultMaxCheck = 5
+
+	defaultTimeout = 15 * time.Second
+
+	// batchLinger is how long a batch waits for more names before it is sent
+	// part-full.
+	batchLinger = 20 * time.Millisecond
+)
+
+var ErrClosed = errors.New("epp: session closed")
+
+// Session is one logged-in EPP connection shared by every lookup in a run.
+// Concurrent Check calls are batched into as few <check> commands as the
+// server's per-command limit allows, and the connection is re-established if
+// the server drops it.
+type Session struct {
+	opts      config.EPPOptions
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex // guards conn; one command on the wire at a time
+	conn net.Conn
+	seq  atomic.Uint64
+
+	start     sync.Once
+	requests  chan request
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type request struct {
+	ctx   context.Context
+	name  string
+	reply chan reply
+}
+
+type reply struct {
+	result Result
+	err    error
+}
+
+// NewSession validates the options but does not connect; the first Check
+// does. A nil tlsConfig is built from the options' certificate files.
+func NewSession(opts config.EPPOptions, tlsConfig *tls.Config) (*Session, error) {
+	if opts.Server == "" || opts.Username == "" || opts.Password == "" {
+		return nil, errors.New("epp: server, username and password are required")
+	}
+	if _, _, err := net.SplitHostPort(opts.Server); err != nil {
+		return nil, fmt.Errorf("epp: server must be host:port: %w", err)
+	}
+
+	if tlsConfig == nil {
+		var err error
+		if tlsConfig, err = buildTLSConfig(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Session{
+		opts:      opts,
+		tlsConfig: tlsConfig,
+		requests:  make(chan request),
+		closed:    make(chan struct{}),
+	}, nil
+}
+
+func buildTLSConfig(opts config.EPPOptions) (*tls.Config, error) {
+	host, _, _ := net.SplitHostPort(opts.Server)
+	cfg := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	// Most registries require a client certificate on top of the login.
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("epp: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("epp: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("epp: no certificates found in %s", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+func (s *Session) maxCheck() int {
+	if s.opts.MaxCheck > 0 {
+		return s.opts.MaxCheck
+	}
+	return DefaultMaxCheck
+}
+
+func (s *Session) timeout() time.Duration {
+	if s.opts.Timeout > 0 {
+		return s.opts.Timeout
+	}
+	return defaultTimeout
+}
+
+// Check queues name for the next <check> and waits for its answer.
+func (s *Session) Check(ctx context.Context, name string) (Result, error) {
+	s.start.Do(func() { go s.batchLoop() })
+
+	req := request{ctx: ctx, name: strings.ToLower(name), reply: make(chan reply, 1)}
+	select {
+	case s.requests <- req:
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	case <-s.closed:
+		return Result{}, ErrClosed
+	}
+
+	select {
+	case r := <-req.reply:
+		return r.result, r.err
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+func (s *Session) batchLoop() {
+	for {
+		var first request
+		select {
+		case first = <-s.requests:
+		case <-s.closed:
+			return
+		}
+
+		batch := []request{first}
+		linger := time.NewTimer(batchLinger)
+	fill:
+		for len(batch) < s.maxCheck() {
+			select {
+			case req := <-s.requests:
+				batch = append(batch, req)
+			case <-linger.C:
+				break fill
+			case <-s.closed:
+				break fill
+			}
+		}
+		linger.Stop()
+
+		s.runBatch(batch)
+	}
+}
+
+func (s *Session) runBatch(batch []request) {
+	live := batch[:0]
+	var names []string
+	seen := make(map[string]bool, len(batch))
+	for _, req := range batch {
+		if req.ctx.Err() != nil {
+			req.reply <- reply{err: req.ctx.Err()}
+			continue
+		}
+		live = append(live, req)
+		if !seen[req.name] {
+			seen[req.name] = true
+			names = append(names, req.name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	results, err := s.check(names)
+	byName := make(map[string]Result, len(results))
+	for _, r := range results {
+		byName[strings.ToLower(r.Name)] = r
+	}
+
+	for _, req := range live {
+		switch r, ok := byName[req.name]; {
+		case err != nil:
+			req.reply <- reply{err: err}
+		case !ok:
+			req.reply <- reply{err: fmt.Errorf("epp: no result for %s", req.name)}
+		default:
+			req.reply <- reply{result: r}
+		}
+	}
+}
+
+// check sends one <check>. A dropped connection, or a server ending the
+// session (2500-2502), is retried once on a fresh login.
+func (s *Session) check(names []string) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err := s.connect(); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := s.roundTrip(checkCommand(names, s.nextTRID()))
+		if err == nil {
+			return resp.checkResults(), nil
+		}
+
+		lastErr = err
+		var resultErr *ResultError
+		if errors.As(err, &resultErr) && resultErr.Code < 2500 {
+			return nil, err
+		}
+		s.dropConn()
+	}
+	return nil, lastErr
+}
+
+// connect dials, reads the greeting and logs in. Callers hold s.mu.
+func (s *Session) connect() error {
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: s.timeout()}, Config: s.tlsConfig}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout())
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", s.opts.Server)
+	if err != nil {
+		return fmt.Errorf("epp: connect %s: %w", s.opts.Server, err)
+	}
+	s.conn = conn
+
+	conn.SetDeadline(time.Now().Add(s.timeout())) //nolint:errcheck
+	greeting, err := readFrame(conn)
+	if err != nil {
+		s.dropConn()
+		return fmt.Errorf("epp: read greeting: %w", err)
+	}
+	if resp, err := parseResponse(greeting); err != nil || resp.Greeting == nil {
+		s.dropConn()
+		return errors.New("epp: server did not send a greeting")
+	}
+
+	if _, err := s.roundTrip(loginCommand(s.opts.Username, s.opts.Password, s.nextTRID())); err != nil {
+		s.dropConn()
+		return fmt.Errorf("epp: login: %w", err)
+	}
+	return nil
+}
+
+// roundTrip sends one command and reads its response. Callers hold s.mu.
+func (s *Session) roundTrip(cmd []byte) (*response, error) {
+	s.conn.SetDeadline(time.Now().Add(s.timeout())) //nolint:errcheck
+
+	if err := writeFrame(s.conn, cmd); err != nil {
+		return nil, err
+	}
+	payload, err := readFrame(s.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := parseResponse(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.err(); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *Session) dropConn() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *Session) nextTRID() string {
+	return "tldx-" + strconv.FormatUint(s.seq.Add(1), 10)
+}
+
+// Close logs out and closes the connection. Pending and later checks fail
+// with ErrClosed.
+func (s *Session) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+
+	_, err := s.roundTrip(logoutCommand(s.nextTRID()))
+	s.dropConn()
+	return err
+}
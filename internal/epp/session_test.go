@@ -0,0 +1,276 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// mockServer is an EPP server on a self-signed TLS listener. Names containing
+// "taken" are unavailable with reason "In use".
+type mockServer struct {
+	addr    string
+	cert    *x509.Certificate
+	logins  atomic.Int32
+	checks  atomic.Int32
+	logouts atomic.Int32
+
+	// loginCode is the result code for <login>, 1000 unless set.
+	loginCode int
+	// dropAfterCheck closes the connection after the first <check> is read,
+	// without answering it.
+	dropAfterCheck atomic.Bool
+}
+
+var namePattern = regexp.MustCompile(`<domain:name>([^<]+)</domain:name>`)
+
+func newMockServer(t *testing.T) *mockServer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "epp.test"},
+		DNSNames:     []string{"epp.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	m := &mockServer{addr: ln.Addr().String(), cert: cert, loginCode: codeSuccess}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+	return m
+}
+
+func (m *mockServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	writeFrame(conn, []byte(`<epp xmlns="`+eppNS+`"><greeting><svID>mock</svID></greeting></epp>`))
+	for {
+		payload, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case bytes.Contains(payload, []byte("<login>")):
+			m.logins.Add(1)
+			writeFrame(conn, resultFrame(m.loginCode, "", ""))
+			if m.loginCode != codeSuccess {
+				return
+			}
+		case bytes.Contains(payload, []byte("<check>")):
+			m.checks.Add(1)
+			if m.dropAfterCheck.CompareAndSwap(true, false) {
+				return
+			}
+			var cd strings.Builder
+			for _, match := range namePattern.FindAllSubmatch(payload, -1) {
+				name := string(match[1])
+				if strings.Contains(name, "taken") {
+					fmt.Fprintf(&cd, `<domain:cd><domain:name avail="0">%s</domain:name><domain:reason>In use</domain:reason></domain:cd>`, name)
+				} else {
+					fmt.Fprintf(&cd, `<domain:cd><domain:name avail="1">%s</domain:name></domain:cd>`, name)
+				}
+			}
+			writeFrame(conn, resultFrame(codeSuccess, "", cd.String()))
+		case bytes.Contains(payload, []byte("<logout/>")):
+			m.logouts.Add(1)
+			writeFrame(conn, resultFrame(codeSuccessEnding, "", ""))
+			return
+		}
+	}
+}
+
+func resultFrame(code int, msg, chkData string) []byte {
+	if msg == "" {
+		msg = "Command completed"
+	}
+	resData := ""
+	if chkData != "" {
+		resData = `<resData><domain:chkData xmlns:domain="` + domainNS + `">` + chkData + `</domain:chkData></resData>`
+	}
+	return []byte(fmt.Sprintf(`<epp xmlns="%s"><response><result code="%d"><msg>%s</msg></result>%s</response></epp>`,
+		eppNS, code, msg, resData))
+}
+
+func (m *mockServer) session(t *testing.T, maxCheck int) *Session {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(m.cert)
+
+	s, err := NewSession(config.EPPOptions{
+		Server:   m.addr,
+		Username: "registrar",
+		Password: "secret & <escaped>",
+		MaxCheck: maxCheck,
+		Timeout:  5 * time.Second,
+	}, &tls.Config{RootCAs: pool, ServerName: "epp.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSession_Check(t *testing.T) {
+	m := newMockServer(t)
+	s := m.session(t, 0)
+
+	result, err := s.Check(context.Background(), "taken.example")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Available || result.Reason != "In use" {
+		t.Errorf("Expected unavailable with a reason, got %+v", result)
+	}
+
+	result, err = s.Check(context.Background(), "Free.Example")
+	if err != nil || !result.Available {
+		t.Errorf("Expected available, got %+v, %v", result, err)
+	}
+
+	if got := m.logins.Load(); got != 1 {
+		t.Errorf("Expected the session to be reused, got %d logins", got)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Expected a clean logout, got %v", err)
+	}
+	if m.logouts.Load() != 1 {
+		t.Error("Expected Close to log out")
+	}
+	if _, err := s.Check(context.Background(), "late.example"); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestSession_BatchesConcurrentChecks(t *testing.T) {
+	m := newMockServer(t)
+	s := m.session(t, 5)
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("name%d.example", i)
+			result, err := s.Check(context.Background(), name)
+			if err == nil && (!result.Available || result.Name != name) {
+				err = fmt.Errorf("wrong result for %s: %+v", name, result)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := m.checks.Load(); got >= n {
+		t.Errorf("Expected %d names to share <check> commands, got %d commands", n, got)
+	}
+}
+
+func TestSession_ReconnectsAfterDrop(t *testing.T) {
+	m := newMockServer(t)
+	s := m.session(t, 0)
+
+	if _, err := s.Check(context.Background(), "first.example"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.dropAfterCheck.Store(true)
+	result, err := s.Check(context.Background(), "second.example")
+	if err != nil || !result.Available {
+		t.Fatalf("Expected the check to be retried on a new session, got %+v, %v", result, err)
+	}
+	if got := m.logins.Load(); got != 2 {
+		t.Errorf("Expected a second login, got %d", got)
+	}
+}
+
+func TestSession_LoginFailure(t *testing.T) {
+	m := newMockServer(t)
+	m.loginCode = 2200
+	s := m.session(t, 0)
+
+	_, err := s.Check(context.Background(), "example.example")
+	if err == nil || !strings.Contains(err.Error(), "2200") {
+		t.Errorf("Expected the 2200 result code in the error, got %v", err)
+	}
+}
+
+func TestNewSession_Validation(t *testing.T) {
+	if _, err := NewSession(config.EPPOptions{Server: "epp.test:700"}, nil); err == nil {
+		t.Error("Expected missing credentials to be rejected")
+	}
+	if _, err := NewSession(config.EPPOptions{Server: "epp.test", Username: "u", Password: "p"}, nil); err == nil {
+		t.Error("Expected a server without a port to be rejected")
+	}
+}
+
+func TestReadFrame_RejectsBadLength(t *testing.T) {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], maxFrameSize+1)
+	if _, err := readFrame(bytes.NewReader(header[:])); err == nil {
+		t.Error("Expected an oversized frame to be rejected")
+	}
+
+	binary.BigEndian.PutUint32(header[:], 2)
+	if _, err := readFrame(bytes.NewReader(header[:])); err == nil {
+		t.Error("Expected a frame shorter than its header to be rejected")
+	}
+}
+
+func TestCheckCommand_EscapesNames(t *testing.T) {
+	cmd := string(checkCommand([]string{"a&b.example"}, "tldx-1"))
+	if !strings.Contains(cmd, "<domain:name>a&amp;b.example</domain:name>") {
+		t.Errorf("Expected the name to be escaped, got %s", cmd)
+	}
+}
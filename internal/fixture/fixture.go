@@ -0,0 +1,159 @@
+// Package fixture records the answers of RDAP, WHOIS and DNS lookups to a
+// directory and replays them later, so a run can be reproduced without the
+// network. Both sides plug into the resolver's injection points.
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRecorded is returned during replay for a query missing from the
+// fixtures.
+var ErrNotRecorded = errors.New("not recorded")
+
+// One file per kind of traffic, each a JSON object keyed by query.
+const (
+	httpFile  = "rdap.json"
+	whoisFile = "whois.json"
+	dnsFile   = "dns.json"
+)
+
+// DNS query kinds, the first word of a dns.json key.
+const (
+	kindHost       = "host"
+	kindTXT        = "txt"
+	kindDelegation = "delegation"
+)
+
+// exchange is one HTTP response, or the transport error in its place.
+type exchange struct {
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type whoisAnswer struct {
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type dnsAnswer struct {
+	Values    []string  `json:"values,omitempty"`
+	Delegated *bool     `json:"delegated,omitempty"`
+	Error     *dnsError `json:"error,omitempty"`
+}
+
+// dnsError keeps what callers inspect on a *net.DNSError, so NXDOMAIN still
+// reads as "not found" on replay.
+type dnsError struct {
+	Message  string `json:"message"`
+	NotFound bool   `json:"not_found,omitempty"`
+	Timeout  bool   `json:"timeout,omitempty"`
+}
+
+func newDNSError(err error) *dnsError {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return &dnsError{Message: dnsErr.Err, NotFound: dnsErr.IsNotFound, Timeout: dnsErr.IsTimeout}
+	}
+	return &dnsError{Message: err.Error()}
+}
+
+func (e *dnsError) err(name string) error {
+	return &net.DNSError{Err: e.Message, Name: name, IsNotFound: e.NotFound, IsTimeout: e.Timeout}
+}
+
+type store struct {
+	http  map[string]exchange
+	whois map[string]whoisAnswer
+	dns   map[string]dnsAnswer
+}
+
+func newStore() store {
+	return store{
+		http:  make(map[string]exchange),
+		whois: make(map[string]whoisAnswer),
+		dns:   make(map[string]dnsAnswer),
+	}
+}
+
+// load reads the fixtures in dir. Missing files are empty; found reports
+// whether any existed.
+func load(dir string) (s store, found bool, err error) {
+	s = newStore()
+	for name, into := range map[string]any{httpFile: &s.http, whoisFile: &s.whois, dnsFile: &s.dns} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return s, found, fmt.Errorf("fixture: %w", err)
+		}
+		if err := json.Unmarshal(data, into); err != nil {
+			return s, found, fmt.Errorf("fixture: parse %s: %w", filepath.Join(dir, name), err)
+		}
+		found = true
+	}
+	return s, found, nil
+}
+
+// save writes each file atomically. encoding/json sorts map keys, so
+// re-recording the same run gives the same files.
+func (s store) save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	for name, v := range map[string]any{httpFile: s.http, whoisFile: s.whois, dnsFile: s.dns} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("fixture: encode %s: %w", name, err)
+		}
+		if err := writeAtomic(filepath.Join(dir, name), append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Recordings are meant to be committed, not kept private.
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fixture: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fixture: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("fixture: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	return nil
+}
+
+func whoisKey(domain string, servers []string) string {
+	if len(servers) == 0 {
+		return domain
+	}
+	return domain + "@" + strings.Join(servers, ",")
+}
+
+func dnsKey(kind, name string) string {
+	return kind + " " + strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,222 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/free.test") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		io.WriteString(w, `{"objectClassName":"domain","ldhName":"taken.test"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	rec, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: rec.transport(http.DefaultTransport)}
+	for _, name := range []string{"taken.test", "free.test"} {
+		resp, err := client.Get(srv.URL + "/domain/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if name == "taken.test" && !strings.Contains(string(body), "ldhName") {
+			t.Errorf("the recorder must pass the body through, got %q", body)
+		}
+	}
+
+	whois := rec.whois(func(domain string, _ ...string) (string, error) {
+		if domain == "down.test" {
+			return "", errors.New("connection refused")
+		}
+		return "Domain Name: " + domain, nil
+	})
+	whois("taken.test")
+	whois("down.test")
+
+	host := rec.lookup(kindHost, func(_ context.Context, name string) ([]string, error) {
+		if name == "free.test" {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return []string{"192.0.2.1"}, nil
+	})
+	host(context.Background(), "taken.test")
+	host(context.Background(), "free.test")
+
+	delegated := rec.delegation(func(context.Context, string) (bool, error) { return true, nil })
+	delegated(context.Background(), "taken.test")
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayClient := &http.Client{Transport: roundTripFunc(rep.roundTrip)}
+	resp, err := replayClient.Get(srv.URL + "/domain/free.test")
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the recorded 404, got %v, %v", resp, err)
+	}
+	resp, err = replayClient.Get(srv.URL + "/domain/taken.test")
+	if err != nil || resp.Header.Get("Content-Type") != "application/rdap+json" {
+		t.Fatalf("expected the recorded RDAP answer, got %v, %v", resp, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"ldhName":"taken.test"`) {
+		t.Errorf("unexpected replayed body %q", body)
+	}
+
+	if raw, err := rep.whois("taken.test"); err != nil || raw != "Domain Name: taken.test" {
+		t.Errorf("whois: got %q, %v", raw, err)
+	}
+	if _, err := rep.whois("down.test"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the recorded WHOIS error, got %v", err)
+	}
+
+	if addrs, err := rep.lookup(kindHost)(context.Background(), "taken.test"); err != nil || len(addrs) != 1 {
+		t.Errorf("host lookup: got %v, %v", addrs, err)
+	}
+	_, err = rep.lookup(kindHost)(context.Background(), "free.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("NXDOMAIN must replay as a not-found DNSError, got %v", err)
+	}
+
+	if ok, err := rep.delegated(context.Background(), "taken.test"); !ok || err != nil {
+		t.Errorf("delegation: got %v, %v", ok, err)
+	}
+
+	if err := rep.Err(); err != nil {
+		t.Errorf("a fully recorded replay must not fail, got %v", err)
+	}
+}
+
+func TestReplay_UnrecordedQueriesFail(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, whoisFile), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rep.whois("acme.test"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded, got %v", err)
+	}
+	if _, err := rep.lookup(kindTXT)(context.Background(), "_for-sale.acme.test"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded, got %v", err)
+	}
+	rep.whois("acme.test")
+
+	want := []string{"txt _for-sale.acme.test", "whois acme.test"}
+	if got := rep.Missing(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Missing: got %v, want %v", got, want)
+	}
+	if err := rep.Err(); !errors.Is(err, ErrNotRecorded) || !strings.Contains(err.Error(), "2 queries") {
+		t.Errorf("expected Err to report both misses, got %v", err)
+	}
+}
+
+func TestLoad_EmptyDirectory(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without recordings")
+	}
+}
+
+func TestRecorder_KeepsEarlierRecordings(t *testing.T) {
+	dir := t.TempDir()
+	for _, domain := range []string{"one.test", "two.test"} {
+		rec, err := NewRecorder(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.whois(func(d string, _ ...string) (string, error) { return "Domain Name: " + d, nil })(domain)
+		if err := rec.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rep, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, domain := range []string{"one.test", "two.test"} {
+		if _, err := rep.whois(domain); err != nil {
+			t.Errorf("%s: %v", domain, err)
+		}
+	}
+}
+
+const bootstrapJSON = `{"version":"1.0","publication":"2024-01-01T00:00:00Z","services":[[["test"],["https://rdap.example.test/"]]]}`
+
+// The RDAP client, bootstrap included, runs entirely from the recording.
+func TestReplay_ThroughTheResolver(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := `{
+  "GET https://data.iana.org/rdap/dns.json": {"status": 200, "content_type": "application/json", "body": ` + quote(bootstrapJSON) + `},
+  "GET https://rdap.example.test/domain/taken.test": {"status": 200, "content_type": "application/rdap+json", "body": ` + quote(`{"objectClassName":"domain","ldhName":"taken.test","status":["active"]}`) + `},
+  "GET https://rdap.example.test/domain/free.test": {"status": 404, "body": "not found"}
+}`
+	if err := os.WriteFile(filepath.Join(dir, httpFile), []byte(fixtures), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{resolver.SourceRDAP}
+	app.Config.MaxRetries = 0
+	s := resolver.NewResolverService(app, rep.Options()...)
+
+	taken, err := s.CheckDomain(context.Background(), "taken.test")
+	if err != nil || !taken.Registered || taken.Source != resolver.SourceRDAP {
+		t.Errorf("taken.test: got %+v, %v", taken, err)
+	}
+	free, err := s.CheckDomain(context.Background(), "free.test")
+	if err != nil || free.Registered {
+		t.Errorf("free.test: got %+v, %v", free, err)
+	}
+
+	// The RDAP client reports a failed fetch as "no server responded", which
+	// is no verdict; the run still fails through Err.
+	if other, _ := s.CheckDomain(context.Background(), "other.test"); other.Source == resolver.SourceRDAP {
+		t.Errorf("an unrecorded domain must not get an RDAP verdict, got %+v", other)
+	}
+	if err := rep.Err(); err == nil || !strings.Contains(err.Error(), "rdap.example.test/domain/other.test") {
+		t.Errorf("expected the unrecorded RDAP query to be reported, got %v", err)
+	}
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,149 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/brandonyoungdev/tldx/internal/transport"
+)
+
+// Recorder captures answers from the real lookups. Answers already in the
+// directory are kept, so several runs can add to the same fixtures.
+type Recorder struct {
+	dir string
+
+	mu sync.Mutex
+	s  store
+}
+
+// NewRecorder records into dir.
+func NewRecorder(dir string) (*Recorder, error) {
+	s, _, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, s: s}, nil
+}
+
+// Options wires the recorder into the resolver's real RDAP, WHOIS, DNS and
+// delegation lookups, configured from cfg.
+func (r *Recorder) Options(cfg *config.TldxConfigOptions) ([]resolver.ResolverOption, error) {
+	client, err := transport.NewHTTPClient(cfg.Transport)
+	if err != nil {
+		return nil, err
+	}
+	dns, err := resolver.NewDNSResolver(cfg.DNSServer, client)
+	if err != nil {
+		return nil, err
+	}
+	rdapHTTP := &http.Client{Transport: r.transport(client.Transport)}
+
+	return []resolver.ResolverOption{
+		resolver.WithRDAPQuerier(resolver.NewRDAPClient(rdapHTTP)),
+		resolver.WithWhoisFetcher(r.whois(resolver.NewWhoisFetcher(cfg.Transport))),
+		resolver.WithDNSLookup(r.lookup(kindHost, dns.LookupHost)),
+		resolver.WithTXTLookup(r.lookup(kindTXT, dns.LookupTXT)),
+		resolver.WithDelegationLookup(r.delegation(resolver.NewDelegationLookup(dns))),
+	}, nil
+}
+
+// Save writes everything captured so far.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.s.save(r.dir)
+}
+
+// transport records every exchange made through base. Requests cut short by
+// their context are not recorded, since the answer says nothing about the
+// query.
+func (r *Recorder) transport(base http.RoundTripper) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		key := req.Method + " " + req.URL.String()
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			if req.Context().Err() == nil {
+				r.put(func(s store) { s.http[key] = exchange{Error: err.Error()} })
+			}
+			return nil, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		r.put(func(s store) {
+			s.http[key] = exchange{
+				Status:      resp.StatusCode,
+				ContentType: resp.Header.Get("Content-Type"),
+				Body:        string(body),
+			}
+		})
+		return resp, nil
+	})
+}
+
+// whois records the responses of fetch.
+func (r *Recorder) whois(fetch func(string, ...string) (string, error)) func(string, ...string) (string, error) {
+	return func(domain string, servers ...string) (string, error) {
+		raw, err := fetch(domain, servers...)
+		answer := whoisAnswer{Response: raw}
+		if err != nil {
+			answer = whoisAnswer{Error: err.Error()}
+		}
+		r.put(func(s store) { s.whois[whoisKey(domain, servers)] = answer })
+		return raw, err
+	}
+}
+
+func (r *Recorder) lookup(kind string, fn func(context.Context, string) ([]string, error)) func(context.Context, string) ([]string, error) {
+	return func(ctx context.Context, name string) ([]string, error) {
+		values, err := fn(ctx, name)
+		if ctx.Err() != nil {
+			return values, err
+		}
+
+		answer := dnsAnswer{Values: values}
+		if err != nil {
+			answer = dnsAnswer{Error: newDNSError(err)}
+		}
+		r.put(func(s store) { s.dns[dnsKey(kind, name)] = answer })
+		return values, err
+	}
+}
+
+// delegation records the verdicts of the delegation pre-screen.
+func (r *Recorder) delegation(fn func(context.Context, string) (bool, error)) func(context.Context, string) (bool, error) {
+	return func(ctx context.Context, domain string) (bool, error) {
+		delegated, err := fn(ctx, domain)
+		if ctx.Err() != nil {
+			return delegated, err
+		}
+
+		answer := dnsAnswer{Delegated: &delegated}
+		if err != nil {
+			answer = dnsAnswer{Error: newDNSError(err)}
+		}
+		r.put(func(s store) { s.dns[dnsKey(kindDelegation, domain)] = answer })
+		return delegated, err
+	}
+}
+
+func (r *Recorder) put(fn func(store)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.s)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
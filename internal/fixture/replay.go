@@ -0,0 +1,151 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// Replayer answers lookups from recorded fixtures and never touches the
+// network. A query without a recording fails with ErrNotRecorded, is logged,
+// and is listed by Missing.
+type Replayer struct {
+	dir string
+	s   store
+
+	mu      sync.Mutex
+	missing map[string]struct{}
+}
+
+// Load reads the fixtures recorded in dir.
+func Load(dir string) (*Replayer, error) {
+	s, found, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("fixture: no recordings in %s (record them with --record)", dir)
+	}
+	return &Replayer{dir: dir, s: s, missing: make(map[string]struct{})}, nil
+}
+
+// Options serves every RDAP, WHOIS, DNS and delegation lookup from the
+// fixtures.
+func (p *Replayer) Options() []resolver.ResolverOption {
+	rdapHTTP := &http.Client{Transport: roundTripFunc(p.roundTrip)}
+
+	return []resolver.ResolverOption{
+		resolver.WithRDAPQuerier(resolver.NewRDAPClient(rdapHTTP)),
+		resolver.WithWhoisFetcher(p.whois),
+		resolver.WithDNSLookup(p.lookup(kindHost)),
+		resolver.WithTXTLookup(p.lookup(kindTXT)),
+		resolver.WithDelegationLookup(p.delegated),
+	}
+}
+
+// Missing lists the queries asked for but not recorded, sorted.
+func (p *Replayer) Missing() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]string, 0, len(p.missing))
+	for query := range p.missing {
+		out = append(out, query)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Err reports whether the run asked for anything that was not recorded.
+func (p *Replayer) Err() error {
+	missing := p.Missing()
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d queries %w in %s: %s", len(missing), ErrNotRecorded, p.dir, strings.Join(missing, ", "))
+}
+
+func (p *Replayer) miss(query string) error {
+	p.mu.Lock()
+	_, seen := p.missing[query]
+	p.missing[query] = struct{}{}
+	p.mu.Unlock()
+
+	if !seen {
+		slog.Error("Query not recorded in the replay fixtures", "query", query, "dir", p.dir)
+	}
+	return fmt.Errorf("%w: %s", ErrNotRecorded, query)
+}
+
+func (p *Replayer) roundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+	ex, ok := p.s.http[key]
+	if !ok {
+		return nil, p.miss(key)
+	}
+	if ex.Error != "" {
+		return nil, errors.New(ex.Error)
+	}
+
+	header := make(http.Header)
+	if ex.ContentType != "" {
+		header.Set("Content-Type", ex.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(ex.Body)),
+		ContentLength: int64(len(ex.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (p *Replayer) whois(domain string, servers ...string) (string, error) {
+	key := whoisKey(domain, servers)
+	answer, ok := p.s.whois[key]
+	if !ok {
+		return "", p.miss("whois " + key)
+	}
+	if answer.Error != "" {
+		return "", errors.New(answer.Error)
+	}
+	return answer.Response, nil
+}
+
+func (p *Replayer) lookup(kind string) func(context.Context, string) ([]string, error) {
+	return func(_ context.Context, name string) ([]string, error) {
+		key := dnsKey(kind, name)
+		answer, ok := p.s.dns[key]
+		if !ok {
+			return nil, p.miss(key)
+		}
+		if answer.Error != nil {
+			return nil, answer.Error.err(name)
+		}
+		return slices.Clone(answer.Values), nil
+	}
+}
+
+func (p *Replayer) delegated(_ context.Context, domain string) (bool, error) {
+	key := dnsKey(kindDelegation, domain)
+	answer, ok := p.s.dns[key]
+	if !ok {
+		return false, p.miss(key)
+	}
+	if answer.Error != nil {
+		return false, answer.Error.err(domain)
+	}
+	return answer.Delegated != nil && *answer.Delegated, nil
+}
@@ -0,0 +1,106 @@
+// Package logging sets up the slog handler tldx's diagnostics go through.
+// Results go to stdout; logs never do.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Levels are the accepted --log-level values, least severe first.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// ParseLevel reads one of Levels, case-insensitively. An empty level is info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid --log-level %q: use one of %s", level, strings.Join(Levels, ", "))
+}
+
+// Validate checks opts before anything is logged with them.
+func Validate(opts config.LogOptions) error {
+	if _, err := ParseLevel(opts.Level); err != nil {
+		return err
+	}
+	switch opts.Format {
+	case "", FormatText, FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid --log-format %q: use text or json", opts.Format)
+}
+
+// NewHandler returns a handler writing to w at the level and in the format
+// of opts.
+func NewHandler(w io.Writer, opts config.LogOptions) (slog.Handler, error) {
+	if err := Validate(opts); err != nil {
+		return nil, err
+	}
+	level, _ := ParseLevel(opts.Level)
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	if opts.Format == FormatJSON {
+		return slog.NewJSONHandler(w, handlerOpts), nil
+	}
+	return slog.NewTextHandler(w, handlerOpts), nil
+}
+
+// Setup makes a handler for opts the default, writing to w.
+func Setup(w io.Writer, opts config.LogOptions) error {
+	handler, err := NewHandler(w, opts)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Discard drops everything logged from here on, for while a full-screen UI
+// owns the terminal.
+func Discard() {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+}
+
+// DefaultMCPPath is where the MCP server logs: $TLDX_MCP_LOG if set, else
+// mcp.log in tldx's directory under the user cache directory.
+func DefaultMCPPath() (string, error) {
+	if path := os.Getenv("TLDX_MCP_LOG"); path != "" {
+		return path, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("logging: cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(cache, "tldx", "mcp.log"), nil
+}
+
+// OpenFile opens path for appending logs, creating it and its directory as
+// needed.
+func OpenFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("logging: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("logging: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,87 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	for level, want := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := logging.ParseLevel(level)
+		assert.NoError(t, err, level)
+		assert.Equal(t, want, got, level)
+	}
+
+	_, err := logging.ParseLevel("warn+2")
+	assert.ErrorContains(t, err, `invalid --log-level "warn+2"`)
+}
+
+func TestValidate_RejectsUnknownFormat(t *testing.T) {
+	assert.NoError(t, logging.Validate(config.LogOptions{Format: logging.FormatJSON}))
+	assert.ErrorContains(t, logging.Validate(config.LogOptions{Format: "xml"}), `invalid --log-format "xml"`)
+}
+
+func TestNewHandler_JSONAtLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := logging.NewHandler(&buf, config.LogOptions{Level: "warn", Format: logging.FormatJSON})
+	require.NoError(t, err)
+
+	logger := slog.New(handler)
+	logger.Info("Not shown")
+	logger.Warn("invalid TLD", "tld", "comx")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record), "one JSON record: %s", buf.String())
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "invalid TLD", record["msg"])
+	assert.Equal(t, "comx", record["tld"])
+}
+
+func TestDiscard_DropsEverything(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var buf bytes.Buffer
+	require.NoError(t, logging.Setup(&buf, config.LogOptions{Level: "debug"}))
+	logging.Discard()
+	slog.Error("Operation cancelled")
+
+	assert.Empty(t, buf.String())
+}
+
+func TestDefaultMCPPath_HonoursTheEnvironment(t *testing.T) {
+	t.Setenv("TLDX_MCP_LOG", "/tmp/tldx-mcp.log")
+	path, err := logging.DefaultMCPPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/tldx-mcp.log", path)
+}
+
+func TestOpenFile_CreatesTheDirectoryAndAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "mcp.log")
+	for _, line := range []string{"one\n", "two\n"} {
+		f, err := logging.OpenFile(path)
+		require.NoError(t, err)
+		_, err = f.WriteString(line)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
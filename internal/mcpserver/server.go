@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brandonyoungdev/tldx/internal/composer"
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/presets"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
@@ -59,10 +60,26 @@ func NewService(opts ...Option) *Service {
 		// nil isSet: there are no command-line flags here, tool arguments are
 		// layered on per call instead.
 		cfg.Defaults.ApplyTo(base, nil)
+		cfg.Backends.ApplyTo(base, nil)
+		cfg.Registrar.ApplyTo(base)
+		cfg.EPP.ApplyTo(base)
+		cfg.Transport.ApplyTo(base, nil)
+		cfg.Resolver.ApplyTo(base, nil)
+		cfg.Reserved.ApplyTo(base, nil)
+		cfg.Zone.ApplyTo(base, nil)
+		cfg.Prefilter.ApplyTo(base, nil)
+	}
+	// Checked again per call, where tool arguments can fix it; logged here so
+	// a bad [resolver] section shows up when the server starts.
+	if err := resolver.ValidateTuning(base); err != nil {
+		slog.Error("Invalid resolver tuning", "error", err)
 	}
 	if base.OnlyForSale {
 		base.CheckForSale = true
 	}
+	if base.MaxPrice > 0 {
+		base.CheckPricing = true
+	}
 
 	s := &Service{
 		base:        base,
@@ -114,6 +131,7 @@ const maxDomainsLiteral = "1000"
 func (s *Service) context() *config.TldxContext {
 	cfg := *s.base
 	cfg.TLDs = slices.Clone(s.base.TLDs)
+	cfg.Backends = slices.Clone(s.base.Backends)
 	cfg.Prefixes = slices.Clone(s.base.Prefixes)
 	cfg.Suffixes = slices.Clone(s.base.Suffixes)
 	return &config.TldxContext{Config: &cfg}
@@ -160,8 +178,9 @@ Pass the exact names you want tested, one or many; they are checked in parallel.
 Use this when you already know the names. To invent names from keywords, use
 generate_and_check instead.
 
-Each result carries a status: "available", "taken", or "unknown" (the lookup
-failed, which is NOT the same as available).`),
+Each result carries a status: "available", "taken", "reserved" (unregistered
+but held back by the registry), or "unknown" (the lookup failed, which is NOT
+the same as available).`),
 		mcp.WithArray("domains",
 			mcp.Required(),
 			mcp.Description(`Fully-qualified domain names, e.g. ["stripe.com","stripe.io"]. Checked exactly as given, with no permutation.`),
@@ -171,6 +190,8 @@ failed, which is NOT the same as available).`),
 		),
 	}
 	opts = append(opts, forSaleParams()...)
+	opts = append(opts, verifyParams()...)
+	opts = append(opts, tuningParams()...)
 	opts = append(opts, readOnlyAnnotations("Check specific domains")...)
 
 	return mcp.NewTool("check_domains", opts...)
@@ -233,16 +254,64 @@ All parameters are named. Do NOT pass them as positional array items.`),
 		mcp.WithNumber("limit",
 			mcp.Description(`Stop the whole sweep once this many available domains are found. 0 means no limit. Setting this is how you afford a large keyword/TLD space.`),
 		),
+		mcp.WithNumber("limit_per_tld",
+			mcp.Description(`Stop checking a TLD once it has this many available domains, so one TLD cannot fill the whole limit. The response's "quotas" says which TLDs got there. 0 means no cap.`),
+		),
+		mcp.WithNumber("limit_per_keyword",
+			mcp.Description(`Stop checking a keyword once it has this many available domains, across its prefixes, suffixes and TLDs. 0 means no cap.`),
+		),
+		mcp.WithString("order",
+			mcp.Enum(composer.Orders...),
+			mcp.Description(`The order candidates are checked in, which decides what a limit finds. "input" (default) goes keyword by keyword; "shortest-first" checks the shortest names first; "score-first" checks short, pronounceable, affix-free names on early TLDs first; "tld-priority" checks every name on the first TLD before the next; "random" samples the space.`),
+		),
 		mcp.WithNumber("max_domain_length",
 			mcp.Description(`Skip candidates longer than this many characters, including the TLD. Applied before checking, so it lowers the domain count. Default 64.`),
 		),
 	}
 	opts = append(opts, forSaleParams()...)
+	opts = append(opts, verifyParams()...)
+	opts = append(opts, tuningParams()...)
 	opts = append(opts, readOnlyAnnotations("Generate and check domain names")...)
 
 	return mcp.NewTool("generate_and_check", opts...)
 }
 
+func verifyParams() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithBoolean("verify",
+			mcp.Description(`When true, ask RDAP, the parent zone's DNS delegation and WHOIS in parallel instead of trusting the first answer. Each result gains a "confidence" ("confirmed", "likely" or "conflicting") and every source's own verdict. Use before acting on a result; it costs about three lookups per domain. Default false.`),
+		),
+	}
+}
+
+// tuningParams mirror the --retries, --timeout and related flags. Durations
+// are Go duration strings, as on the command line.
+func tuningParams() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithNumber("retries",
+			mcp.Description(`Retries per lookup after a timeout or transient error. Defaults to the configured value, normally 3.`),
+		),
+		mcp.WithString("timeout",
+			mcp.Description(`Time allowed for checking one domain, retries included, as a duration such as "10s". Defaults to the configured value.`),
+		),
+		mcp.WithString("initial_backoff",
+			mcp.Description(`Upper bound of the first, randomized wait before a retry, as a duration such as "200ms".`),
+		),
+		mcp.WithString("max_backoff",
+			mcp.Description(`Longest wait between retries, as a duration such as "5s".`),
+		),
+		mcp.WithNumber("backoff_factor",
+			mcp.Description(`Multiplier applied to the wait after each retry. At least 1.`),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description(`Domains checked in parallel. Lower it if lookups are being rate limited.`),
+		),
+		mcp.WithBoolean("adaptive_concurrency",
+			mcp.Description(`When true, check fewer domains in parallel while lookups are failing, then ramp back up.`),
+		),
+	}
+}
+
 func forSaleParams() []mcp.ToolOption {
 	return []mcp.ToolOption{
 		mcp.WithBoolean("check_for_sale",
@@ -267,6 +267,57 @@ tlds = ["io", "ai"]
 	assert.Equal(t, []string{"stripe.com"}, decode(t, res).Domains)
 }
 
+func TestGenerateAndCheck_Order(t *testing.T) {
+	isolateConfig(t, `
+[defaults]
+order = "tld-priority"
+`)
+	c := newClient(t)
+
+	res := callTool(t, c, "generate_and_check", map[string]any{
+		"keywords": []any{"stripe", "acme"},
+		"tlds":     []any{"io", "com"},
+		"dry_run":  true,
+	})
+	assert.Equal(t, []string{"stripe.io", "acme.io", "stripe.com", "acme.com"}, decode(t, res).Domains)
+
+	res = callTool(t, c, "generate_and_check", map[string]any{
+		"keywords": []any{"stripe", "acme"},
+		"tlds":     []any{"io", "com"},
+		"order":    "shortest-first",
+		"dry_run":  true,
+	})
+	assert.Equal(t, []string{"acme.io", "acme.com", "stripe.io", "stripe.com"}, decode(t, res).Domains)
+
+	res = callTool(t, c, "generate_and_check", map[string]any{
+		"keywords": []any{"stripe"},
+		"order":    "best",
+	})
+	require.True(t, res.IsError)
+	assert.Contains(t, textOf(t, res), "unknown order")
+}
+
+func TestGenerateAndCheck_LimitPerTLD(t *testing.T) {
+	isolateConfig(t, "")
+	c := newClient(t, withRDAP(&mockRDAP{err: errNotFound}))
+
+	res := callTool(t, c, "generate_and_check", map[string]any{
+		"keywords":      []any{"alpha", "bravo", "charlie"},
+		"tlds":          []any{"com", "io"},
+		"limit_per_tld": 1,
+	})
+	resp := decode(t, res)
+
+	require.Len(t, resp.Results, 2, "one per TLD")
+	assert.NotEqual(t, resp.Results[0].TLD, resp.Results[1].TLD)
+	assert.False(t, resp.Truncated)
+	assert.Contains(t, resp.Note, "every quota was met")
+	require.Len(t, resp.Quotas, 2)
+	for _, q := range resp.Quotas {
+		assert.True(t, q.Met, "%+v", q)
+	}
+}
+
 func presetEnum(t *testing.T, tool mcp.Tool) []string {
 	t.Helper()
 
@@ -632,3 +683,27 @@ func TestGenerateAndCheck_OnlyForSaleFiltersOutPlainTakenDomains(t *testing.T) {
 	assert.Empty(t, out.Results)
 	assert.Equal(t, 1, out.Checked)
 }
+
+func TestCheckDomains_ReportsReserved(t *testing.T) {
+	isolateConfig(t, "")
+
+	res := callTool(t, newClient(t, withRDAP(&mockRDAP{err: errNotFound})), "check_domains", map[string]any{
+		"domains": []any{"nic.xyz", "free.xyz"},
+	})
+
+	out := decode(t, res)
+	require.Len(t, out.Results, 2)
+	assert.Equal(t, 1, out.Reserved)
+	assert.Equal(t, 1, out.Available)
+
+	for _, r := range out.Results {
+		if r.Domain != "nic.xyz" {
+			continue
+		}
+		assert.Equal(t, mcpserver.StatusReserved, r.Status)
+		require.NotNil(t, r.Available)
+		assert.False(t, *r.Available)
+		require.NotNil(t, r.Reserved)
+		assert.Equal(t, "icann", r.Reserved.List)
+	}
+}
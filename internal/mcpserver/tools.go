@@ -42,7 +42,12 @@ func (s *Service) handleCheckDomains(ctx context.Context, req mcp.CallToolReques
 	}
 
 	app := s.context()
-	applyForSaleArgs(app.Config, req)
+	applyLookupArgs(app.Config, req)
+	if err := applyTuningArgs(app.Config, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// An explicit list is checked in full: no limit and no quotas.
+	app.Config.LimitPerTLD, app.Config.LimitPerKeyword = 0, 0
 
 	resp := s.collect(ctx, app, specs, 0)
 	if len(invalid) > 0 {
@@ -80,7 +85,22 @@ func (s *Service) handleGenerateAndCheck(ctx context.Context, req mcp.CallToolRe
 	if n := req.GetInt("max_domain_length", 0); n > 0 {
 		cfg.MaxDomainLength = n
 	}
-	applyForSaleArgs(cfg, req)
+	if n := req.GetInt("limit_per_tld", -1); n >= 0 {
+		cfg.LimitPerTLD = n
+	}
+	if n := req.GetInt("limit_per_keyword", -1); n >= 0 {
+		cfg.LimitPerKeyword = n
+	}
+	if v := req.GetString("order", ""); v != "" {
+		if err := composer.ValidateOrder(v); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		cfg.Order = v
+	}
+	applyLookupArgs(cfg, req)
+	if err := applyTuningArgs(cfg, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	limit := req.GetInt("limit", cfg.Limit)
 	dryRun := req.GetBool("dry_run", false)
@@ -121,7 +141,19 @@ func (s *Service) collect(ctx context.Context, app *config.TldxContext, specs []
 	deadline := time.NewTimer(softDeadline)
 	defer deadline.Stop()
 
-	resultChan := s.newResolver(app).CheckDomainsStreaming(ctx, specs)
+	quotas := domain.NewQuotas(app.Config.LimitPerTLD, app.Config.LimitPerKeyword, specs)
+	var opts []resolver.ResolverOption
+	if quotas != nil {
+		opts = append(opts, resolver.WithSkip(quotas.Skip))
+	}
+	if limit > 0 {
+		opts = append(opts, resolver.WithOrderedResults())
+	}
+
+	resolverService := s.newResolver(app, opts...)
+	defer resolverService.Close()
+
+	resultChan := resolverService.CheckDomainsStreaming(ctx, specs)
 
 	resp := CheckResponse{Results: []DomainCheck{}}
 	availableFound := 0
@@ -134,12 +166,20 @@ collect:
 				break collect
 			}
 
+			if r.Available && domain.WithinMaxPrice(app.Config, r) && !quotas.Take(r) {
+				// Its quota filled while it was being looked up; counted
+				// in quota_skipped instead.
+				continue
+			}
+
 			resp.Checked++
 			switch {
 			case r.Error != nil:
 				resp.Errored++
 			case r.Available:
 				resp.Available++
+			case r.Reserved != nil:
+				resp.Reserved++
 			default:
 				resp.Taken++
 			}
@@ -151,13 +191,17 @@ collect:
 				resp.Results = append(resp.Results, fromResult(r))
 			}
 
-			if r.Available {
+			if r.Available && domain.WithinMaxPrice(app.Config, r) {
 				availableFound++
 				if limit > 0 && availableFound >= limit {
 					cancel()
 					break collect
 				}
 			}
+			if quotas.AllMet() {
+				cancel()
+				break collect
+			}
 
 		case <-deadline.C:
 			resp.Truncated = true
@@ -170,7 +214,17 @@ collect:
 		}
 	}
 
-	if !resp.Truncated && resp.Checked < len(specs) {
+	resp.Quotas, resp.QuotaSkipped = quotas.Report(), quotas.Skipped()
+	switch {
+	case quotas.AllMet():
+		resp.Note = joinNotes(resp.Note, fmt.Sprintf(
+			"Stopped once every quota was met, after checking %d of %d domains.", resp.Checked, len(specs)))
+	case resp.QuotaSkipped > 0:
+		resp.Note = joinNotes(resp.Note, fmt.Sprintf(
+			"Skipped %d domain(s) whose TLD or keyword had already met its quota.", resp.QuotaSkipped))
+	}
+
+	if !resp.Truncated && resp.Checked+resp.QuotaSkipped < len(specs) && !quotas.AllMet() {
 		resp.Truncated = true
 		if limit > 0 && availableFound >= limit {
 			resp.Note = fmt.Sprintf("Stopped early: reached limit of %d available domain(s) after checking %d of %d.",
@@ -279,7 +333,10 @@ func countDistinct(specs []resolver.DomainSpec) (keywords, tlds int) {
 	return len(seenKeyword), len(seenTLD)
 }
 
-func applyForSaleArgs(cfg *config.TldxConfigOptions, req mcp.CallToolRequest) {
+// applyLookupArgs layers the per-call lookup switches over the configured
+// defaults.
+func applyLookupArgs(cfg *config.TldxConfigOptions, req mcp.CallToolRequest) {
+	cfg.Verify = req.GetBool("verify", cfg.Verify)
 	cfg.CheckForSale = req.GetBool("check_for_sale", cfg.CheckForSale)
 	cfg.OnlyForSale = req.GetBool("only_for_sale", cfg.OnlyForSale)
 	// Mirrors cmd/root.go.
@@ -288,6 +345,48 @@ func applyForSaleArgs(cfg *config.TldxConfigOptions, req mcp.CallToolRequest) {
 	}
 }
 
+// applyTuningArgs layers the per-call resolver tuning over the configured
+// defaults and validates the result, so a bad [resolver] section is reported
+// on every call rather than run with.
+func applyTuningArgs(cfg *config.TldxConfigOptions, req mcp.CallToolRequest) error {
+	args := req.GetArguments()
+	if v, ok := args["retries"]; ok && v != nil {
+		cfg.MaxRetries = req.GetInt("retries", cfg.MaxRetries)
+	}
+	if v, ok := args["backoff_factor"]; ok && v != nil {
+		cfg.BackoffFactor = req.GetFloat("backoff_factor", cfg.BackoffFactor)
+	}
+	if v, ok := args["concurrency"]; ok && v != nil {
+		cfg.ConcurrencyLimit = req.GetInt("concurrency", cfg.ConcurrencyLimit)
+	}
+	cfg.AdaptiveConcurrency = req.GetBool("adaptive_concurrency", cfg.AdaptiveConcurrency)
+
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"timeout", &cfg.ContextTimeout},
+		{"initial_backoff", &cfg.InitialBackoff},
+		{"max_backoff", &cfg.MaxBackoff},
+	}
+	for _, d := range durations {
+		v := req.GetString(d.name, "")
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: use a duration such as \"10s\"", d.name, v)
+		}
+		*d.dst = parsed
+	}
+
+	if err := resolver.ValidateTuning(cfg); err != nil {
+		return fmt.Errorf("invalid resolver tuning: %w", err)
+	}
+	return nil
+}
+
 func warningNote(warnings []error) string {
 	if len(warnings) == 0 {
 		return ""
@@ -2,6 +2,11 @@ package mcpserver_test
 
 import (
 	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/mcpserver"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -79,3 +84,67 @@ func TestServer_OnlyForSaleDefaultImpliesTheLookup(t *testing.T) {
 	require.NotNil(t, out.Results[0].ForSale)
 	assert.Equal(t, "USD", out.Results[0].ForSale.Prices[0].Currency)
 }
+
+func TestTools_RejectInvalidTuning(t *testing.T) {
+	t.Run("from the config file", func(t *testing.T) {
+		isolateConfig(t, "[resolver]\nbackoff_factor = 0.5\n")
+		m := &mockRDAP{}
+
+		res := callTool(t, newClient(t, withRDAP(m)), "check_domains",
+			map[string]any{"domains": []any{"stripe.com"}})
+
+		require.True(t, res.IsError)
+		assert.Contains(t, textOf(t, res), "backoff_factor must be at least 1")
+		assert.Zero(t, m.calls.Load())
+	})
+
+	t.Run("from the arguments", func(t *testing.T) {
+		isolateConfig(t, "")
+
+		res := callTool(t, newClient(t, withRDAP(&mockRDAP{})), "generate_and_check", map[string]any{
+			"keywords": []any{"stripe"},
+			"retries":  -1,
+		})
+
+		require.True(t, res.IsError)
+		assert.Contains(t, textOf(t, res), "retries must not be negative")
+	})
+
+	t.Run("a malformed duration", func(t *testing.T) {
+		isolateConfig(t, "")
+
+		res := callTool(t, newClient(t, withRDAP(&mockRDAP{})), "check_domains", map[string]any{
+			"domains": []any{"stripe.com"},
+			"timeout": "soon",
+		})
+
+		require.True(t, res.IsError)
+		assert.Contains(t, textOf(t, res), `invalid timeout "soon"`)
+	})
+}
+
+func TestTools_ApplyTuningArguments(t *testing.T) {
+	isolateConfig(t, "[resolver]\nbackoff_factor = 0.5\n")
+
+	var got config.TldxConfigOptions
+	factory := mcpserver.WithResolverFactory(
+		func(app *config.TldxContext, opts ...resolver.ResolverOption) *resolver.ResolverService {
+			got = *app.Config
+			opts = append(opts, resolver.WithRDAPQuerier(&mockRDAP{err: errNotFound}))
+			return resolver.NewResolverService(app, opts...)
+		})
+
+	res := callTool(t, newClient(t, factory), "check_domains", map[string]any{
+		"domains":        []any{"stripe.com"},
+		"backoff_factor": 1.5,
+		"retries":        1,
+		"timeout":        "2s",
+		"concurrency":    4,
+	})
+
+	require.False(t, res.IsError, textOf(t, res))
+	assert.Equal(t, 1.5, got.BackoffFactor, "an argument can fix a bad config value")
+	assert.Equal(t, 1, got.MaxRetries)
+	assert.Equal(t, 2*time.Second, got.ContextTimeout)
+	assert.Equal(t, 4, got.ConcurrencyLimit)
+}
@@ -2,13 +2,19 @@ package mcpserver
 
 import (
 	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 )
 
 // StatusUnknown means the lookup failed; never report it as available.
+// StatusReserved is a name no lookup found registered that a registry still
+// will not sell.
 const (
 	StatusAvailable = "available"
 	StatusTaken     = "taken"
+	StatusReserved  = "reserved"
 	StatusUnknown   = "unknown"
 )
 
@@ -16,16 +22,22 @@ const (
 // CLI's output formats.
 type DomainCheck struct {
 	Domain string `json:"domain" jsonschema_description:"The domain name this verdict is for."`
-	Status string `json:"status" jsonschema_description:"One of \"available\", \"taken\", or \"unknown\". \"unknown\" means the lookup failed and says nothing about availability."`
+	Status string `json:"status" jsonschema_description:"One of \"available\", \"taken\", \"reserved\", or \"unknown\". \"reserved\" means no one holds the name but the registry will not sell it; see reserved for why. \"unknown\" means the lookup failed and says nothing about availability."`
 	// Omitted when Status is "unknown", so a failed lookup is not read as free.
-	Available *bool         `json:"available,omitempty" jsonschema_description:"Present only when status is \"available\" or \"taken\"."`
-	Details   string        `json:"details,omitempty"`
-	Error     string        `json:"error,omitempty"`
-	Keyword   string        `json:"keyword,omitempty"`
-	Prefix    string        `json:"prefix,omitempty"`
-	Suffix    string        `json:"suffix,omitempty"`
-	TLD       string        `json:"tld,omitempty"`
-	ForSale   *forsale.Info `json:"for_sale,omitempty"`
+	Available *bool              `json:"available,omitempty" jsonschema_description:"Present unless status is \"unknown\"; false for a reserved name."`
+	Details   string             `json:"details,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Keyword   string             `json:"keyword,omitempty"`
+	Prefix    string             `json:"prefix,omitempty"`
+	Suffix    string             `json:"suffix,omitempty"`
+	TLD       string             `json:"tld,omitempty"`
+	ForSale   *forsale.Info      `json:"for_sale,omitempty"`
+	Source    string             `json:"source,omitempty" jsonschema_description:"Which lookup produced the verdict: rdap, dns-delegation, dns, whois, registrar, epp, consensus (verify), or fallback."`
+	Pricing   *registrar.Pricing `json:"pricing,omitempty" jsonschema_description:"Registration and renewal price from the configured registrar, for available domains."`
+	Reserved  *reserved.Match    `json:"reserved,omitempty" jsonschema_description:"When status is \"reserved\": the list that reserves the name and its reason."`
+	// Set only when verify is on.
+	Confidence string                   `json:"confidence,omitempty" jsonschema_description:"With verify: \"confirmed\" (several sources agree), \"likely\" (one source or none), or \"conflicting\" (sources disagree; reported as taken)."`
+	Verdicts   []resolver.SourceVerdict `json:"verdicts,omitempty" jsonschema_description:"With verify: each source's own verdict."`
 }
 
 type CheckResponse struct {
@@ -34,9 +46,13 @@ type CheckResponse struct {
 	Checked   int  `json:"checked"`
 	Available int  `json:"available_count"`
 	Taken     int  `json:"taken_count"`
+	Reserved  int  `json:"reserved_count,omitempty"`
 	Errored   int  `json:"errored_count,omitempty"`
 	ForSale   int  `json:"for_sale_count,omitempty"`
 	Truncated bool `json:"truncated,omitempty"`
+	// Set with limit_per_tld or limit_per_keyword.
+	Quotas       []output.QuotaStatus `json:"quotas,omitempty"`
+	QuotaSkipped int                  `json:"quota_skipped,omitempty"`
 
 	DryRun  bool     `json:"dry_run,omitempty"`
 	Planned int      `json:"planned,omitempty"`
@@ -55,6 +71,12 @@ func fromResult(r resolver.DomainResult) DomainCheck {
 		Suffix:  r.Suffix,
 		TLD:     r.TLD,
 		ForSale: r.ForSale,
+		Source:  r.Source,
+		Pricing: r.Pricing,
+
+		Reserved:   r.Reserved,
+		Confidence: r.Confidence,
+		Verdicts:   r.Verdicts,
 	}
 
 	if r.Error != nil {
@@ -65,9 +87,12 @@ func fromResult(r resolver.DomainResult) DomainCheck {
 
 	available := r.Available
 	out.Available = &available
-	if available {
+	switch {
+	case available:
 		out.Status = StatusAvailable
-	} else {
+	case r.Reserved != nil:
+		out.Status = StatusReserved
+	default:
 		out.Status = StatusTaken
 	}
 	return out
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+
+	"github.com/BurntSushi/toml"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// Document formats, written once the run ends.
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// DocumentOutput writes the results as one YAML or TOML document on Flush,
+// holding what json-array would: a list of results, or with --show-stats the
+// results and the stats. TOML has no top-level lists, so its results are
+// always under "results". Both use the JSON field names.
+type DocumentOutput struct {
+	JsonArrayOutput
+	format string
+}
+
+func NewDocumentOutput(w io.Writer, app *config.TldxContext, format string, stats *Stats) *DocumentOutput {
+	return &DocumentOutput{JsonArrayOutput: *NewJsonArrayOutput(w, app, stats), format: format}
+}
+
+func (o *DocumentOutput) Flush() {
+	var doc any = o.results
+	if o.app != nil && o.app.Config.ShowStats && o.stats != nil {
+		doc = jsonArrayPayload{Results: o.results, Stats: o.stats}
+	} else if o.format == FormatTOML {
+		doc = jsonArrayPayload{Results: o.results}
+	}
+
+	write := writeYAML
+	if o.format == FormatTOML {
+		write = writeTOML
+	}
+	if err := write(o.writer, doc); err != nil {
+		slog.Error("Failed to write document", "format", o.format, "error", err)
+	}
+}
+
+// writeYAML writes doc as block-style YAML, going through JSON for the field
+// names and order. JSON parses as YAML, so only its flow style needs undoing.
+func writeYAML(w io.Writer, doc any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// writeTOML writes doc as TOML, going through JSON for the field names.
+// Tables come out with their keys sorted, and nulls are left out.
+func writeTOML(w io.Writer, doc any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var tree map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	return toml.NewEncoder(w).Encode(tree)
+}
@@ -0,0 +1,87 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+func writeDocument(app *config.TldxContext, format string, stats *output.Stats) string {
+	var buf bytes.Buffer
+	w := output.NewDocumentOutput(&buf, app, format, stats)
+	w.Write(availableResult("stripe.com", "stripe", "", "", "com"))
+	w.Write(makeResult("stripe.io", false))
+	w.Flush()
+	return buf.String()
+}
+
+func TestDocumentOutput_YAML(t *testing.T) {
+	out := writeDocument(config.NewTldxContext(), output.FormatYAML, output.NewStats(2))
+
+	assert.Equal(t, `- domain: stripe.com
+  available: true
+  keyword: stripe
+  tld: com
+- domain: stripe.io
+  available: false
+  keyword: s
+  tld: io
+`, out)
+}
+
+func TestDocumentOutput_YAMLWithStats(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.ShowStats = true
+	stats := output.NewStats(2)
+	stats.Available = 1
+
+	var doc struct {
+		Results []map[string]any `yaml:"results"`
+		Stats   map[string]any   `yaml:"stats"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(writeDocument(app, output.FormatYAML, stats)), &doc))
+	assert.Len(t, doc.Results, 2)
+	assert.Equal(t, 2, doc.Stats["Total"])
+	assert.Equal(t, 1, doc.Stats["Available"])
+}
+
+func TestDocumentOutput_TOML(t *testing.T) {
+	out := writeDocument(config.NewTldxContext(), output.FormatTOML, output.NewStats(2))
+
+	var doc struct {
+		Results []struct {
+			Domain    string `toml:"domain"`
+			Available bool   `toml:"available"`
+			Keyword   string `toml:"keyword"`
+		} `toml:"results"`
+		Stats map[string]any `toml:"stats"`
+	}
+	_, err := toml.Decode(out, &doc)
+	require.NoError(t, err, out)
+	require.Len(t, doc.Results, 2)
+	assert.Equal(t, "stripe.com", doc.Results[0].Domain)
+	assert.True(t, doc.Results[0].Available)
+	assert.Equal(t, "stripe", doc.Results[0].Keyword)
+	assert.Equal(t, "stripe.io", doc.Results[1].Domain)
+	assert.Nil(t, doc.Stats, "stats only come with --show-stats")
+}
+
+func TestDocumentOutput_TOMLWithStats(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.ShowStats = true
+	stats := output.NewStats(2)
+	stats.TLDs["com"] = output.TLDStats{Checked: 1, Available: 1}
+
+	var doc map[string]any
+	_, err := toml.Decode(writeDocument(app, output.FormatTOML, stats), &doc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), doc["stats"].(map[string]any)["Total"])
+	assert.Equal(t, map[string]any{"Checked": int64(1), "Available": int64(1)},
+		doc["stats"].(map[string]any)["TLDs"].(map[string]any)["com"])
+}
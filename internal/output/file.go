@@ -0,0 +1,136 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// Tee filters: which results a --tee file gets.
+const (
+	// TeeFilterRun keeps the run's own --only-available, --only-for-sale
+	// and --max-price filters. It is the default.
+	TeeFilterRun       = ""
+	TeeFilterAll       = "all"
+	TeeFilterAvailable = "available"
+	TeeFilterForSale   = "for-sale"
+)
+
+// Tee is one parsed --tee: a format written to a file, e.g.
+// "csv=all.csv,filter=all".
+type Tee struct {
+	Format string
+	Path   string
+	Filter string
+}
+
+// ParseTee parses "format=path", optionally followed by ",filter=all",
+// ",filter=available" or ",filter=for-sale".
+func ParseTee(spec string) (Tee, error) {
+	format, path, ok := strings.Cut(spec, "=")
+	if !ok || format == "" || path == "" {
+		return Tee{}, fmt.Errorf("invalid --tee %q: use format=path", spec)
+	}
+	tee := Tee{Format: format, Path: path}
+	if i := strings.LastIndex(path, ",filter="); i >= 0 {
+		tee.Path, tee.Filter = path[:i], path[i+len(",filter="):]
+	}
+
+	switch tee.Filter {
+	case TeeFilterRun, TeeFilterAll, TeeFilterAvailable, TeeFilterForSale:
+	default:
+		return Tee{}, fmt.Errorf("invalid --tee %q: unknown filter %q: use %s, %s or %s",
+			spec, tee.Filter, TeeFilterAll, TeeFilterAvailable, TeeFilterForSale)
+	}
+	if err := ValidateFile(tee.Format, tee.Path); err != nil {
+		return Tee{}, fmt.Errorf("invalid --tee %q: %w", spec, err)
+	}
+	return tee, nil
+}
+
+// Config is cfg with the tee's filter applied, for the tee's writer and
+// for choosing which results it gets.
+func (t Tee) Config(cfg *config.TldxConfigOptions) *config.TldxConfigOptions {
+	c := *cfg
+	c.NoColor = true
+	switch t.Filter {
+	case TeeFilterAll:
+		c.OnlyAvailable, c.OnlyForSale, c.MaxPrice = false, false, 0
+	case TeeFilterAvailable:
+		c.OnlyAvailable, c.OnlyForSale = true, false
+	case TeeFilterForSale:
+		c.OnlyAvailable, c.OnlyForSale = false, true
+	}
+	return &c
+}
+
+// ValidateFile checks that format is known and that path can be written,
+// before a run starts.
+func ValidateFile(format, path string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("%w %q: use one of %s", ErrUnknownFormat, format, strings.Join(Formats, ", "))
+	}
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	dir, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if !dir.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.Dir(path))
+	}
+	return nil
+}
+
+// FileOutput writes a format to a file. Results go to a temporary file next
+// to it, renamed over it on Flush, so the file never holds a partial run.
+type FileOutput struct {
+	ResultOutput
+	tmp  *os.File
+	path string
+}
+
+// NewFileOutput returns a writer of format to path. app should have NoColor
+// set, or text formats carry the terminal's colors into the file.
+func NewFileOutput(app *config.TldxContext, format, path string, stats *Stats) (*FileOutput, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	w, err := NewFormatWriter(tmp, app, format, stats)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return &FileOutput{ResultOutput: w, tmp: tmp, path: path}, nil
+}
+
+func (o *FileOutput) Flush() {
+	if err := o.commit(); err != nil {
+		slog.Error("Failed to write output file", "path", o.path, "error", err)
+	}
+}
+
+func (o *FileOutput) commit() error {
+	o.ResultOutput.Flush()
+	defer os.Remove(o.tmp.Name())
+	err := errors.Join(o.tmp.Chmod(0o644), o.tmp.Close())
+	if err != nil {
+		return err
+	}
+	return os.Rename(o.tmp.Name(), o.path)
+}
+
+// Discard removes the temporary file, leaving path as it was.
+func (o *FileOutput) Discard() {
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+}
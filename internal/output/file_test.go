@@ -0,0 +1,93 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTee(t *testing.T) {
+	dir := t.TempDir()
+
+	tee, err := output.ParseTee("csv=" + dir + "/all.csv")
+	require.NoError(t, err)
+	assert.Equal(t, output.Tee{Format: "csv", Path: dir + "/all.csv"}, tee)
+
+	tee, err = output.ParseTee("json-array=" + dir + "/free.json,filter=available")
+	require.NoError(t, err)
+	assert.Equal(t, output.Tee{Format: "json-array", Path: dir + "/free.json", Filter: output.TeeFilterAvailable}, tee)
+}
+
+func TestParseTee_Errors(t *testing.T) {
+	dir := t.TempDir()
+	for spec, want := range map[string]string{
+		"csv":                             "use format=path",
+		"=" + dir + "/x":                  "use format=path",
+		"xml=" + dir + "/x":               "unknown output format",
+		"csv=" + dir + "/x,filter=cheap":  `unknown filter "cheap"`,
+		"csv=" + dir:                      "is a directory",
+		"csv=" + dir + "/missing/all.csv": "no such file or directory",
+	} {
+		_, err := output.ParseTee(spec)
+		assert.ErrorContains(t, err, want, spec)
+	}
+}
+
+func TestTee_Config(t *testing.T) {
+	cfg := &config.TldxConfigOptions{OnlyAvailable: true, MaxPrice: 20}
+
+	all := output.Tee{Filter: output.TeeFilterAll}.Config(cfg)
+	assert.False(t, all.OnlyAvailable)
+	assert.Zero(t, all.MaxPrice)
+	assert.True(t, all.NoColor)
+
+	forSale := output.Tee{Filter: output.TeeFilterForSale}.Config(cfg)
+	assert.False(t, forSale.OnlyAvailable)
+	assert.True(t, forSale.OnlyForSale)
+
+	run := output.Tee{}.Config(cfg)
+	assert.True(t, run.OnlyAvailable)
+	assert.Equal(t, 20.0, run.MaxPrice)
+	assert.True(t, cfg.OnlyAvailable && !cfg.NoColor, "the run's config is left alone")
+}
+
+func TestFileOutput_WritesOnFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv")
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0o644))
+
+	app := &config.TldxContext{Config: &config.TldxConfigOptions{NoColor: true}}
+	w, err := output.NewFileOutput(app, "csv", path, &output.Stats{})
+	require.NoError(t, err)
+	w.Write(makeResult("example.com", true))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\n", string(data), "the file is untouched until Flush")
+
+	w.Flush()
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "example.com")
+	assert.NotContains(t, string(data), "previous run")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileOutput_Discard(t *testing.T) {
+	dir := t.TempDir()
+	app := &config.TldxContext{Config: &config.TldxConfigOptions{NoColor: true}}
+	w, err := output.NewFileOutput(app, "text", filepath.Join(dir, "results.txt"), &output.Stats{})
+	require.NoError(t, err)
+	w.Discard()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
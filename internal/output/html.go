@@ -0,0 +1,40 @@
+package output
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+//go:embed templates/report.html
+var templates embed.FS
+
+var htmlReport = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"statusClass": func(status string) string { return strings.ReplaceAll(status, " ", "-") },
+	// Only trusted for-sale URIs reach the report, and tel: is among them,
+	// which html/template would otherwise blank out.
+	"trustedURL": func(uri string) template.URL { return template.URL(uri) },
+}).ParseFS(templates, "templates/report.html"))
+
+// HTMLOutput writes the Markdown report's content as a single HTML file,
+// styles and scripts inline, whose tables sort on a header click and filter
+// by domain and status.
+type HTMLOutput struct {
+	reportCollector
+	w io.Writer
+}
+
+func NewHTMLOutput(w io.Writer, app *config.TldxContext, stats *Stats) *HTMLOutput {
+	return &HTMLOutput{reportCollector: newReportCollector(app, stats), w: w}
+}
+
+func (o *HTMLOutput) Flush() {
+	if err := htmlReport.Execute(o.w, o.report(time.Now())); err != nil {
+		slog.Error("Failed to write HTML report", "error", err)
+	}
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// MarkdownOutput writes a report for pasting into docs: the run parameters,
+// the stats, and a table of results per keyword.
+type MarkdownOutput struct {
+	reportCollector
+	w io.Writer
+}
+
+func NewMarkdownOutput(w io.Writer, app *config.TldxContext, stats *Stats) *MarkdownOutput {
+	return &MarkdownOutput{reportCollector: newReportCollector(app, stats), w: w}
+}
+
+func (o *MarkdownOutput) Flush() {
+	r := o.report(time.Now())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Domain report\n\nGenerated by tldx on %s.\n", r.Generated)
+
+	if len(r.Params) > 0 {
+		b.WriteString("\n## Run parameters\n\n| Parameter | Value |\n| --- | --- |\n")
+		for _, p := range r.Params {
+			fmt.Fprintf(&b, "| %s | %s |\n", p.Name, markdownCell(p.Value))
+		}
+	}
+
+	b.WriteString("\n## Summary\n\n| | Count |\n| --- | ---: |\n")
+	for _, s := range r.Stats {
+		fmt.Fprintf(&b, "| %s %s | %d |\n", s.Emoji, s.Label, s.Count)
+	}
+
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "\n## %s\n\n| Domain | Status | Price | Details |\n| --- | --- | --- | --- |\n", markdownCell(g.Keyword))
+		for _, row := range g.Rows {
+			fmt.Fprintf(&b, "| %s | %s %s | %s | %s |\n", row.Domain, row.Emoji, row.Status, markdownCell(row.Price), row.markdownDetails())
+		}
+	}
+
+	if _, err := io.WriteString(o.w, b.String()); err != nil {
+		slog.Error("Failed to write Markdown report", "error", err)
+	}
+}
@@ -0,0 +1,217 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// Matrix formats. Each has a row per base name and a column per TLD.
+const (
+	// FormatMatrix is an aligned table for the terminal.
+	FormatMatrix = "matrix"
+	// FormatMatrixCSV spells the cells out, e.g. "available", for
+	// spreadsheets.
+	FormatMatrixCSV = "matrix-csv"
+	// FormatMatrixMarkdown is a Markdown table, for issues and docs.
+	FormatMatrixMarkdown = "matrix-markdown"
+)
+
+// MatrixOutput pivots results into a base name × TLD grid, e.g. rows
+// "getstripe" and "stripe" against columns ".com" and ".io". A base name is
+// the prefix, keyword and suffix a domain was built from. A cell is blank
+// when its domain was not checked or not shown.
+type MatrixOutput struct {
+	w            io.Writer
+	app          *config.TldxContext
+	format       string
+	styleService *StyleService
+	results      []resolver.DomainResult
+}
+
+func NewMatrixOutput(w io.Writer, app *config.TldxContext, format string) *MatrixOutput {
+	return &MatrixOutput{
+		w:            w,
+		app:          app,
+		format:       format,
+		styleService: NewStyleService(app),
+		results:      make([]resolver.DomainResult, 0, 100),
+	}
+}
+
+func (o *MatrixOutput) Write(result resolver.DomainResult) {
+	if _, ok := o.styleService.Render(result); ok {
+		o.results = append(o.results, result)
+	}
+}
+
+func (o *MatrixOutput) Flush() {
+	names, tlds, cells := o.pivot()
+	if len(names) == 0 {
+		return
+	}
+
+	var err error
+	switch o.format {
+	case FormatMatrixCSV:
+		err = o.writeCSV(names, tlds, cells)
+	case FormatMatrixMarkdown:
+		err = o.writeMarkdown(names, tlds, cells)
+	default:
+		err = o.writeTable(names, tlds, cells)
+	}
+	if err != nil {
+		slog.Error("Failed to write matrix", "error", err)
+	}
+}
+
+// pivot returns the base names sorted, the TLDs in the order they were
+// given with any others after them sorted, and the result for each cell
+// keyed by name and TLD.
+func (o *MatrixOutput) pivot() ([]string, []string, map[[2]string]resolver.DomainResult) {
+	cells := make(map[[2]string]resolver.DomainResult, len(o.results))
+	var names, tlds []string
+	seen := make(map[string]bool)
+	for _, result := range o.results {
+		name, tld := matrixKey(result)
+		if !seen["name:"+name] {
+			seen["name:"+name] = true
+			names = append(names, name)
+		}
+		if !seen["tld:"+tld] {
+			seen["tld:"+tld] = true
+			tlds = append(tlds, tld)
+		}
+		cells[[2]string{name, tld}] = result
+	}
+	slices.Sort(names)
+
+	rank := func(tld string) int {
+		if i := slices.Index(o.app.Config.TLDs, tld); i >= 0 {
+			return i
+		}
+		return len(o.app.Config.TLDs)
+	}
+	slices.SortStableFunc(tlds, func(a, b string) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a, b)
+	})
+	return names, tlds, cells
+}
+
+// matrixKey splits a result into its base name and TLD. Results from a list
+// of domains carry no keyword or TLD, so those are read off the domain.
+func matrixKey(result resolver.DomainResult) (name, tld string) {
+	tld = result.TLD
+	if tld == "" {
+		_, tld, _ = strings.Cut(result.Domain, ".")
+	}
+	if result.Keyword != "" {
+		return result.Prefix + result.Keyword + result.Suffix, tld
+	}
+	return strings.TrimSuffix(result.Domain, "."+tld), tld
+}
+
+// matrixCell is the symbol and the word for a result's status.
+func matrixCell(result resolver.DomainResult) (symbol, word string) {
+	switch {
+	case result.Error != nil:
+		return "🟡", "error"
+	case result.Available:
+		return "✅", "available"
+	case result.ForSale != nil:
+		return "💰", "for sale"
+	case result.Reserved != nil:
+		return "🔒", "reserved"
+	default:
+		return "❌", "taken"
+	}
+}
+
+func (o *MatrixOutput) rows(names, tlds []string, cells map[[2]string]resolver.DomainResult, words bool) [][]string {
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		row := []string{name}
+		for _, tld := range tlds {
+			cell := ""
+			if result, ok := cells[[2]string{name, tld}]; ok {
+				symbol, word := matrixCell(result)
+				cell = symbol
+				if words {
+					cell = word
+				}
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func dotted(tlds []string) []string {
+	out := make([]string, 0, len(tlds))
+	for _, tld := range tlds {
+		out = append(out, "."+tld)
+	}
+	return out
+}
+
+func (o *MatrixOutput) writeTable(names, tlds []string, cells map[[2]string]resolver.DomainResult) error {
+	headers := append([]string{""}, dotted(tlds)...)
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers(headers...).
+		Rows(o.rows(names, tlds, cells, false)...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle().Padding(0, 1)
+			if col > 0 {
+				style = style.Align(lipgloss.Center)
+			}
+			if row == table.HeaderRow && !o.styleService.IsNoColor() {
+				style = style.Bold(true).Foreground(lipgloss.Color("14")) // cyan
+			}
+			return style
+		})
+	if !o.styleService.IsNoColor() {
+		t = t.BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8")))
+	}
+
+	_, err := fmt.Fprintf(o.w, "%s\n✅ available  ❌ taken  💰 for sale  🔒 reserved  🟡 errored\n", t.Render())
+	return err
+}
+
+func (o *MatrixOutput) writeCSV(names, tlds []string, cells map[[2]string]resolver.DomainResult) error {
+	w := csv.NewWriter(o.w)
+	w.Write(append([]string{"name"}, tlds...))
+	w.WriteAll(o.rows(names, tlds, cells, true))
+	return w.Error()
+}
+
+func (o *MatrixOutput) writeMarkdown(names, tlds []string, cells map[[2]string]resolver.DomainResult) error {
+	var b strings.Builder
+	b.WriteString("| name |")
+	for _, tld := range dotted(tlds) {
+		fmt.Fprintf(&b, " %s |", tld)
+	}
+	b.WriteString("\n| --- |")
+	for range tlds {
+		b.WriteString(" :---: |")
+	}
+	b.WriteByte('\n')
+	for _, row := range o.rows(names, tlds, cells, false) {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+	}
+	_, err := io.WriteString(o.w, b.String())
+	return err
+}
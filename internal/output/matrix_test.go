@@ -0,0 +1,97 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func matrixResults() []resolver.DomainResult {
+	return []resolver.DomainResult{
+		availableResult("stripe.io", "stripe", "", "", "io"),
+		{Domain: "stripe.com", Keyword: "stripe", TLD: "com"},
+		availableResult("getstripe.com", "stripe", "get", "", "com"),
+		{Domain: "getstripe.io", Keyword: "stripe", Prefix: "get", TLD: "io", ForSale: &forsale.Info{}},
+		{Domain: "stripehq.dev", Keyword: "stripe", Suffix: "hq", TLD: "dev", Error: errors.New("timeout")},
+	}
+}
+
+func writeMatrix(t *testing.T, format string) string {
+	t.Helper()
+	app := config.NewTldxContext()
+	app.Config.NoColor = true
+	app.Config.TLDs = []string{"io", "com"}
+	var buf bytes.Buffer
+	w := output.NewMatrixOutput(&buf, app, format)
+	for _, result := range matrixResults() {
+		w.Write(result)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func TestMatrixOutput_CSV(t *testing.T) {
+	out := writeMatrix(t, output.FormatMatrixCSV)
+	assert.Equal(t, strings.Join([]string{
+		"name,io,com,dev",
+		"getstripe,for sale,available,",
+		"stripe,available,taken,",
+		"stripehq,,,error",
+		"",
+	}, "\n"), out)
+}
+
+func TestMatrixOutput_Markdown(t *testing.T) {
+	out := writeMatrix(t, output.FormatMatrixMarkdown)
+	assert.Equal(t, strings.Join([]string{
+		"| name | .io | .com | .dev |",
+		"| --- | :---: | :---: | :---: |",
+		"| getstripe | 💰 | ✅ |  |",
+		"| stripe | ✅ | ❌ |  |",
+		"| stripehq |  |  | 🟡 |",
+		"",
+	}, "\n"), out)
+}
+
+func TestMatrixOutput_Table(t *testing.T) {
+	out := writeMatrix(t, output.FormatMatrix)
+	lines := strings.Split(out, "\n")
+
+	assert.Contains(t, lines[1], ".io")
+	assert.Less(t, strings.Index(lines[1], ".io"), strings.Index(lines[1], ".com"), "TLDs in the order given")
+	assert.Contains(t, out, "getstripe")
+	assert.Contains(t, out, "✅ available")
+
+	// Every row of the table is the same width.
+	width := lipgloss.Width(lines[0])
+	for _, line := range lines[:len(lines)-2] {
+		assert.Equal(t, width, lipgloss.Width(line), line)
+	}
+}
+
+func TestMatrixOutput_OnlyAvailableHidesErrors(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.OnlyAvailable = true
+	var buf bytes.Buffer
+	w := output.NewMatrixOutput(&buf, app, output.FormatMatrixCSV)
+	w.Write(resolver.DomainResult{Domain: "stripe.com", Error: errors.New("timeout")})
+	w.Flush()
+	assert.Empty(t, buf.String())
+}
+
+func TestMatrixOutput_NameFromDomain(t *testing.T) {
+	app := config.NewTldxContext()
+	var buf bytes.Buffer
+	w := output.NewMatrixOutput(&buf, app, output.FormatMatrixCSV)
+	w.Write(resolver.DomainResult{Domain: "acme.co.uk", Available: true})
+	w.Flush()
+	assert.Equal(t, "name,co.uk\nacme,available\n", buf.String())
+}
@@ -3,10 +3,13 @@ package output
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/brandonyoungdev/tldx/internal/config"
@@ -18,30 +21,83 @@ type ResultOutput interface {
 	Flush()
 }
 
-func GetOutputWriter(app *config.TldxContext) ResultOutput {
-	switch app.Config.OutputFormat {
+// Formats lists the --format values.
+var Formats = []string{
+	"text", "json", "json-stream", "json-array", "csv", "grouped", "grouped-tld",
+	FormatMatrix, FormatMatrixCSV, FormatMatrixMarkdown, "markdown", "html", FormatTemplate,
+	FormatYAML, FormatTOML, FormatNDJSONSummary,
+}
+
+// ErrUnknownFormat is returned by NewFormatWriter for a format not in Formats.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+func GetOutputWriter(app *config.TldxContext, stats *Stats) ResultOutput {
+	w, err := NewFormatWriter(nil, app, app.Config.OutputFormat, stats)
+	switch {
+	case errors.Is(err, ErrUnknownFormat):
+		slog.Warn("Unknown output format, defaulting to text", "format", app.Config.OutputFormat)
+		return NewTextOutput(app)
+	case err != nil:
+		slog.Warn("Could not set up the output format, defaulting to text", "format", app.Config.OutputFormat, "error", err)
+		return NewTextOutput(app)
+	}
+	return w
+}
+
+// NewFormatWriter returns the writer for format, writing to w. A nil w is
+// stdout. The formats that show stats read them from stats on Flush.
+func NewFormatWriter(w io.Writer, app *config.TldxContext, format string, stats *Stats) (ResultOutput, error) {
+	switch format {
 	case "json-stream":
-		return &JSONStreamOutput{}
+		return &JSONStreamOutput{w: w}, nil
+	case FormatNDJSONSummary:
+		return NewNDJSONSummaryOutput(w, app, stats), nil
 	case "json-array", "json":
-		return NewJsonArrayOutput(os.Stdout, app)
+		return NewJsonArrayOutput(orStdout(w), app, stats), nil
+	case FormatYAML, FormatTOML:
+		return NewDocumentOutput(orStdout(w), app, format, stats), nil
 	case "csv":
-		return NewCSVOutput()
+		return newCSVOutput(orStdout(w)), nil
 	case "text":
-		return NewTextOutput(app)
+		return &TextOutput{app: app, styleService: NewStyleService(app), w: w}, nil
 	case "grouped":
-		return NewGroupedOutput(app)
+		o := NewGroupedOutput(app)
+		o.w = w
+		return o, nil
 	case "grouped-tld":
-		return NewGroupedByTLDOutput(app)
+		o := NewGroupedByTLDOutput(app)
+		o.w = w
+		return o, nil
+	case FormatTemplate:
+		tmpl, err := ParseTemplate(app.Config)
+		if err != nil {
+			return nil, err
+		}
+		return NewTemplateOutput(orStdout(w), tmpl, stats), nil
+	case "markdown":
+		return NewMarkdownOutput(orStdout(w), app, stats), nil
+	case "html":
+		return NewHTMLOutput(orStdout(w), app, stats), nil
+	case FormatMatrix, FormatMatrixCSV, FormatMatrixMarkdown:
+		return NewMatrixOutput(orStdout(w), app, format), nil
 	default:
-		// This is okay, since it'll output text by default.
-		fmt.Println("Unknown output format. Defaulting to text.")
-		return NewTextOutput(app)
+		return nil, fmt.Errorf("%w %q: use one of %s", ErrUnknownFormat, format, strings.Join(Formats, ", "))
+	}
+}
+
+// orStdout is w, or stdout as it is now if w is nil. Writers that keep a nil
+// w look stdout up on every write instead, so tests can swap it.
+func orStdout(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
 	}
+	return w
 }
 
 type TextOutput struct {
 	app          *config.TldxContext
 	styleService *StyleService
+	w            io.Writer
 }
 
 func NewTextOutput(app *config.TldxContext) *TextOutput {
@@ -53,7 +109,7 @@ func NewTextOutput(app *config.TldxContext) *TextOutput {
 
 func (o *TextOutput) Write(result resolver.DomainResult) {
 	if line, ok := o.styleService.Render(result); ok {
-		fmt.Println(line)
+		fmt.Fprintln(orStdout(o.w), line)
 	}
 }
 
@@ -64,11 +120,18 @@ type CSVOutput struct {
 }
 
 func NewCSVOutput() *CSVOutput {
-	w := csv.NewWriter(os.Stdout)
+	return newCSVOutput(os.Stdout)
+}
+
+func newCSVOutput(out io.Writer) *CSVOutput {
+	w := csv.NewWriter(out)
 	// The for-sale columns are appended so existing column positions hold.
 	w.Write([]string{
 		"domain", "available", "keyword", "prefix", "suffix", "tld", "details", "error",
 		"for_sale", "for_sale_price", "for_sale_uri", "for_sale_text",
+		"source", "price", "renewal_price", "currency", "premium",
+		"confidence", "verdicts",
+		"reserved", "reserved_reason",
 	})
 	return &CSVOutput{writer: w}
 }
@@ -90,6 +153,26 @@ func (o *CSVOutput) Write(result resolver.DomainResult) {
 		texts = strings.Join(result.ForSale.Texts, "; ")
 	}
 
+	var price, renewal, currency, premium string
+	if result.Pricing != nil {
+		price = strconv.FormatFloat(result.Pricing.Registration, 'f', 2, 64)
+		if result.Pricing.Renewal > 0 {
+			renewal = strconv.FormatFloat(result.Pricing.Renewal, 'f', 2, 64)
+		}
+		currency = result.Pricing.Currency
+		premium = fmt.Sprintf("%v", result.Pricing.Premium)
+	}
+
+	var reservedReason string
+	if result.Reserved != nil {
+		reservedReason = result.Reserved.String()
+	}
+
+	verdicts := make([]string, 0, len(result.Verdicts))
+	for _, v := range result.Verdicts {
+		verdicts = append(verdicts, v.Source+"="+v.Verdict)
+	}
+
 	record := []string{
 		result.Domain,
 		fmt.Sprintf("%v", result.Available),
@@ -103,24 +186,35 @@ func (o *CSVOutput) Write(result resolver.DomainResult) {
 		prices,
 		uris,
 		texts,
+		result.Source,
+		price,
+		renewal,
+		currency,
+		premium,
+		result.Confidence,
+		strings.Join(verdicts, "; "),
+		fmt.Sprintf("%v", result.Reserved != nil),
+		reservedReason,
 	}
 
 	if err := o.writer.Write(record); err != nil {
-		fmt.Fprintf(os.Stderr, "error writing CSV record: %v\n", err)
+		slog.Error("Failed to write CSV record", "error", err)
 	}
 }
 
 func (o *CSVOutput) Flush() {
 	o.writer.Flush()
 	if err := o.writer.Error(); err != nil {
-		fmt.Fprintf(os.Stderr, "error flushing CSV writer: %v\n", err)
+		slog.Error("Failed to flush CSV writer", "error", err)
 	}
 }
 
-type JSONStreamOutput struct{}
+type JSONStreamOutput struct {
+	w io.Writer
+}
 
 func (o *JSONStreamOutput) Write(result resolver.DomainResult) {
-	json.NewEncoder(os.Stdout).Encode(result.AsEncodable())
+	json.NewEncoder(orStdout(o.w)).Encode(result.AsEncodable())
 }
 
 func (o *JSONStreamOutput) Flush() {}
@@ -134,13 +228,15 @@ type JsonArrayOutput struct {
 	results []resolver.EncodableDomainResult
 	writer  io.Writer
 	app     *config.TldxContext
+	stats   *Stats
 }
 
-func NewJsonArrayOutput(w io.Writer, app *config.TldxContext) *JsonArrayOutput {
+func NewJsonArrayOutput(w io.Writer, app *config.TldxContext, stats *Stats) *JsonArrayOutput {
 	return &JsonArrayOutput{
 		results: make([]resolver.EncodableDomainResult, 0, 100),
 		writer:  w,
 		app:     app,
+		stats:   stats,
 	}
 }
 
@@ -152,20 +248,19 @@ func (o *JsonArrayOutput) Flush() {
 	enc := json.NewEncoder(o.writer)
 	enc.SetIndent("", "  ")
 
-	if o.app != nil && o.app.Config.ShowStats {
-		statsCopy := Stat
+	if o.app != nil && o.app.Config.ShowStats && o.stats != nil {
 		payload := jsonArrayPayload{
 			Results: o.results,
-			Stats:   &statsCopy,
+			Stats:   o.stats,
 		}
 		if err := enc.Encode(payload); err != nil {
-			fmt.Fprintf(os.Stderr, "error encoding JSON array: %v\n", err)
+			slog.Error("Failed to encode JSON array", "error", err)
 		}
 		return
 	}
 
 	if err := enc.Encode(o.results); err != nil {
-		fmt.Fprintf(os.Stderr, "error encoding JSON array: %v\n", err)
+		slog.Error("Failed to encode JSON array", "error", err)
 	}
 }
 
@@ -173,6 +268,7 @@ type GroupedOutput struct {
 	app          *config.TldxContext
 	styleService *StyleService
 	results      []resolver.DomainResult
+	w            io.Writer
 }
 
 func NewGroupedOutput(app *config.TldxContext) *GroupedOutput {
@@ -188,6 +284,13 @@ func (o *GroupedOutput) Write(result resolver.DomainResult) {
 }
 
 func (o *GroupedOutput) keywordFor(result resolver.DomainResult) string {
+	return keywordOf(o.app.Config, result)
+}
+
+// keywordOf is the keyword a result is grouped under. Results from a list of
+// domains carry none, so it is the domain's name less any configured prefix
+// and suffix.
+func keywordOf(cfg *config.TldxConfigOptions, result resolver.DomainResult) string {
 	if result.Keyword != "" {
 		return result.Keyword
 	}
@@ -196,13 +299,13 @@ func (o *GroupedOutput) keywordFor(result resolver.DomainResult) string {
 		return result.Domain
 	}
 	baseName := strings.Join(parts[:len(parts)-1], ".")
-	for _, prefix := range o.app.Config.Prefixes {
+	for _, prefix := range cfg.Prefixes {
 		if strings.HasPrefix(baseName, prefix) {
 			baseName = strings.TrimPrefix(baseName, prefix)
 			break
 		}
 	}
-	for _, suffix := range o.app.Config.Suffixes {
+	for _, suffix := range cfg.Suffixes {
 		if strings.HasSuffix(baseName, suffix) {
 			baseName = strings.TrimSuffix(baseName, suffix)
 			break
@@ -234,10 +337,10 @@ func (o *GroupedOutput) Flush() {
 			return domains[i].Domain < domains[j].Domain
 		})
 
-		fmt.Printf("\n%s\n", o.styleService.GroupHeader(strings.ToLower(keyword)))
+		fmt.Fprintf(orStdout(o.w), "\n%s\n", o.styleService.GroupHeader(strings.ToLower(keyword)))
 		for _, result := range domains {
 			if line, ok := o.styleService.Render(result); ok {
-				fmt.Println(line)
+				fmt.Fprintln(orStdout(o.w), line)
 			}
 		}
 	}
@@ -247,6 +350,7 @@ type GroupedByTLDOutput struct {
 	app          *config.TldxContext
 	styleService *StyleService
 	results      []resolver.DomainResult
+	w            io.Writer
 }
 
 func NewGroupedByTLDOutput(app *config.TldxContext) *GroupedByTLDOutput {
@@ -290,10 +394,10 @@ func (o *GroupedByTLDOutput) Flush() {
 			return domains[i].Domain < domains[j].Domain
 		})
 
-		fmt.Printf("\n%s\n", o.styleService.GroupHeader(fmt.Sprintf(".%s", tld)))
+		fmt.Fprintf(orStdout(o.w), "\n%s\n", o.styleService.GroupHeader(fmt.Sprintf(".%s", tld)))
 		for _, result := range domains {
 			if line, ok := o.styleService.Render(result); ok {
-				fmt.Println(line)
+				fmt.Fprintln(orStdout(o.w), line)
 			}
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/forsale"
 	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,7 +36,7 @@ func TestJsonArrayOutput_BasicArray(t *testing.T) {
 	app.Config.ShowStats = false
 
 	var buf bytes.Buffer
-	w := output.NewJsonArrayOutput(&buf, app)
+	w := output.NewJsonArrayOutput(&buf, app, &output.Stats{})
 	w.Write(availableResult("stripe.com", "stripe", "", "", "com"))
 	w.Write(availableResult("stripe.io", "stripe", "", "", "io"))
 	w.Flush()
@@ -47,14 +50,13 @@ func TestJsonArrayOutput_BasicArray(t *testing.T) {
 }
 
 func TestJsonArrayOutput_WithStats(t *testing.T) {
-	// Reset global stat
-	output.Stat = output.Stats{Total: 2, Available: 1, NotAvailable: 1}
+	stats := &output.Stats{Total: 2, Available: 1, NotAvailable: 1}
 
 	app := config.NewTldxContext()
 	app.Config.ShowStats = true
 
 	var buf bytes.Buffer
-	w := output.NewJsonArrayOutput(&buf, app)
+	w := output.NewJsonArrayOutput(&buf, app, stats)
 	w.Write(availableResult("stripe.com", "stripe", "", "", "com"))
 	w.Flush()
 
@@ -64,20 +66,20 @@ func TestJsonArrayOutput_WithStats(t *testing.T) {
 	assert.Contains(t, payload, "results", "stats output should have a results key")
 	assert.Contains(t, payload, "stats", "stats output should have a stats key")
 
-	stats, ok := payload["stats"].(map[string]any)
+	encoded, ok := payload["stats"].(map[string]any)
 	require.True(t, ok)
-	assert.EqualValues(t, 2, stats["Total"])
-	assert.EqualValues(t, 1, stats["Available"])
+	assert.EqualValues(t, 2, encoded["Total"])
+	assert.EqualValues(t, 1, encoded["Available"])
 }
 
 func TestJsonArrayOutput_StatsOmittedWhenDisabled(t *testing.T) {
-	output.Stat = output.Stats{Total: 5, Available: 3}
+	stats := &output.Stats{Total: 5, Available: 3}
 
 	app := config.NewTldxContext()
 	app.Config.ShowStats = false
 
 	var buf bytes.Buffer
-	w := output.NewJsonArrayOutput(&buf, app)
+	w := output.NewJsonArrayOutput(&buf, app, stats)
 	w.Write(availableResult("a.com", "a", "", "", "com"))
 	w.Flush()
 
@@ -201,21 +203,20 @@ func TestStyleService_Verbose_Available(t *testing.T) {
 }
 
 func TestRenderStatsSummary_ContainsStats(t *testing.T) {
-	output.Stat = output.Stats{Total: 10, Available: 3, NotAvailable: 5, TimedOut: 1, Errored: 1}
-	rendered := output.RenderStatsSummary()
+	rendered := output.RenderStatsSummary(&output.Stats{Total: 10, Available: 3, NotAvailable: 5, TimedOut: 1, Errored: 1})
 	assert.Contains(t, rendered, "10")
 	assert.Contains(t, rendered, "3")
 	assert.Contains(t, rendered, "5")
 }
 
 func TestGetOutputWriter_AllFormats(t *testing.T) {
-	formats := []string{"text", "csv", "json-stream", "json-array", "json", "grouped", "grouped-tld", "unknown"}
+	formats := []string{"text", "csv", "json-stream", "json-array", "json", "grouped", "grouped-tld", "matrix", "matrix-csv", "matrix-markdown", "markdown", "html", "yaml", "toml", "ndjson-summary", "unknown"}
 	for _, format := range formats {
 		t.Run(format, func(t *testing.T) {
 			app := config.NewTldxContext()
 			app.Config.OutputFormat = format
 			assert.NotPanics(t, func() {
-				w := output.GetOutputWriter(app)
+				w := output.GetOutputWriter(app, &output.Stats{})
 				assert.NotNil(t, w)
 			})
 		})
@@ -234,15 +235,13 @@ func captureStdout(fn func()) string {
 	return buf.String()
 }
 
-func captureStderr(fn func()) string {
-	old := os.Stderr
-	r, w, _ := os.Pipe()
-	os.Stderr = w
-	fn()
-	w.Close()
-	os.Stderr = old
+// captureLogs returns what fn logs through the default slog logger.
+func captureLogs(fn func()) string {
+	old := slog.Default()
+	defer slog.SetDefault(old)
 	var buf bytes.Buffer
-	io.Copy(&buf, r) //nolint:errcheck
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	fn()
 	return buf.String()
 }
 
@@ -595,7 +594,7 @@ func TestJsonOutput_IncludesForSale(t *testing.T) {
 	app := config.NewTldxContext()
 
 	var buf bytes.Buffer
-	w := output.NewJsonArrayOutput(&buf, app)
+	w := output.NewJsonArrayOutput(&buf, app, &output.Stats{})
 	w.Write(forSaleResult("stripe.com", "v=FORSALE1;fval=USD750", "v=FORSALE1;furi=https://fs.example.com/"))
 	w.Write(availableResult("stripe.io", "stripe", "", "", "io"))
 	w.Flush()
@@ -637,7 +636,7 @@ func TestCSVOutput_ForSaleColumns(t *testing.T) {
 	require.Len(t, lines, 3)
 
 	header := strings.Split(lines[0], ",")
-	assert.Equal(t, []string{"for_sale", "for_sale_price", "for_sale_uri", "for_sale_text"}, header[8:])
+	assert.Equal(t, []string{"for_sale", "for_sale_price", "for_sale_uri", "for_sale_text"}, header[8:12])
 	// Pre-existing columns keep their positions.
 	assert.Equal(t, "domain", header[0])
 	assert.Equal(t, "error", header[7])
@@ -647,13 +646,9 @@ func TestCSVOutput_ForSaleColumns(t *testing.T) {
 }
 
 func TestRenderStatsSummary_ForSaleRowOnlyWhenPresent(t *testing.T) {
-	output.Stat = output.Stats{Total: 2, NotAvailable: 2}
-	assert.NotContains(t, output.RenderStatsSummary(), "for sale")
+	assert.NotContains(t, output.RenderStatsSummary(&output.Stats{Total: 2, NotAvailable: 2}), "for sale")
 
-	output.Stat = output.Stats{Total: 2, NotAvailable: 2, ForSale: 1}
-	assert.Contains(t, output.RenderStatsSummary(), "for sale")
-
-	output.Stat = output.Stats{}
+	assert.Contains(t, output.RenderStatsSummary(&output.Stats{Total: 2, NotAvailable: 2, ForSale: 1}), "for sale")
 }
 
 type failingWriter struct{}
@@ -664,20 +659,141 @@ func TestJsonArrayOutput_FlushReportsAWriteFailure(t *testing.T) {
 	app := config.NewTldxContext()
 	app.Config.ShowStats = false
 
-	w := output.NewJsonArrayOutput(failingWriter{}, app)
+	w := output.NewJsonArrayOutput(failingWriter{}, app, &output.Stats{})
 	w.Write(availableResult("stripe.com", "stripe", "", "", "com"))
 
-	stderr := captureStderr(func() { w.Flush() })
-	assert.Contains(t, stderr, "error encoding JSON array")
+	logs := captureLogs(func() { w.Flush() })
+	assert.Contains(t, logs, `msg="Failed to encode JSON array" error="disk on fire"`)
 }
 
 func TestJsonArrayOutput_FlushReportsAWriteFailureWithStats(t *testing.T) {
 	app := config.NewTldxContext()
 	app.Config.ShowStats = true
 
-	w := output.NewJsonArrayOutput(failingWriter{}, app)
+	w := output.NewJsonArrayOutput(failingWriter{}, app, &output.Stats{})
 	w.Write(availableResult("stripe.com", "stripe", "", "", "com"))
 
-	stderr := captureStderr(func() { w.Flush() })
-	assert.Contains(t, stderr, "error encoding JSON array")
+	logs := captureLogs(func() { w.Flush() })
+	assert.Contains(t, logs, `msg="Failed to encode JSON array" error="disk on fire"`)
+}
+
+func pricedResult(domain string, pricing registrar.Pricing) resolver.DomainResult {
+	r := availableResult(domain, "", "", "", "")
+	r.Pricing = &pricing
+	return r
+}
+
+func TestCSVOutput_PricingColumns(t *testing.T) {
+	out := captureStdout(func() {
+		w := output.NewCSVOutput()
+		w.Write(pricedResult("stripe.io", registrar.Pricing{Registration: 5000, Renewal: 45.5, Currency: "USD", Premium: true}))
+		w.Write(availableResult("stripe.ai", "stripe", "", "", "ai"))
+		w.Flush()
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+
+	header := strings.Split(lines[0], ",")
+	assert.Equal(t, []string{"price", "renewal_price", "currency", "premium"}, header[13:17])
+	assert.True(t, strings.Contains(lines[1], ",5000.00,45.50,USD,true,"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], ",,,,,,false,"), lines[2])
+}
+
+func TestCSVOutput_VerifyColumns(t *testing.T) {
+	out := captureStdout(func() {
+		w := output.NewCSVOutput()
+		r := availableResult("stripe.io", "", "", "", "")
+		r.Confidence = resolver.ConfidenceConfirmed
+		r.Verdicts = []resolver.SourceVerdict{
+			{Source: "rdap", Verdict: resolver.VerdictAvailable},
+			{Source: "whois", Verdict: resolver.VerdictNone},
+		}
+		w.Write(r)
+		w.Flush()
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], ",confidence,verdicts,reserved,reserved_reason"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], ",confirmed,rdap=available; whois=no-verdict,false,"), lines[1])
+}
+
+func TestStyleService_ConfidenceNote(t *testing.T) {
+	app := config.NewTldxContext()
+	styles := output.NewStyleServiceDirect(app, true)
+
+	r := resolver.DomainResult{Domain: "acme.io", Confidence: resolver.ConfidenceConflicting}
+	assert.Equal(t, "❌ acme.io is not available (conflicting: sources disagree)", styles.NotAvailable(r))
+
+	r = resolver.DomainResult{Domain: "acme.dev", Available: true, Confidence: resolver.ConfidenceConfirmed}
+	assert.Equal(t, "✅ acme.dev is available (confirmed)", styles.Available(r))
+
+	app.Config.Verbose = true
+	r.Details = "Available according to rdap"
+	r.Verdicts = []resolver.SourceVerdict{{Source: "rdap", Verdict: resolver.VerdictAvailable}}
+	assert.Equal(t, "✅ acme.dev is available (confirmed) - Available according to rdap [rdap: available]", styles.Available(r))
+}
+
+func TestStyleService_Available_ShowsPricing(t *testing.T) {
+	app := config.NewTldxContext()
+	svc := output.NewStyleServiceDirect(app, true)
+
+	out := svc.Available(pricedResult("stripe.io", registrar.Pricing{Registration: 5000, Renewal: 45.5, Currency: "USD", Premium: true}))
+	assert.Equal(t, "✅ stripe.io is available — USD 5000.00 · renews USD 45.50 · premium", out)
+
+	out = svc.Available(pricedResult("stripe.ai", registrar.Pricing{Registration: 9.68, Currency: "USD"}))
+	assert.Equal(t, "✅ stripe.ai is available — USD 9.68", out)
+}
+
+func reservedResult(domain string) resolver.DomainResult {
+	return resolver.DomainResult{
+		Domain:   domain,
+		Details:  "RDAP is not found or doesn't exist",
+		Source:   resolver.SourceRDAP,
+		Reserved: &reserved.Match{List: "icann", Reason: "reserved for the registry operator"},
+	}
+}
+
+func TestStyleService_Reserved(t *testing.T) {
+	app := config.NewTldxContext()
+	styles := output.NewStyleServiceDirect(app, true)
+
+	line, ok := styles.Render(reservedResult("nic.xyz"))
+	require.True(t, ok)
+	assert.Equal(t, "🔒 nic.xyz is reserved — reserved for the registry operator", line)
+
+	app.Config.Verbose = true
+	line, _ = styles.Render(reservedResult("nic.xyz"))
+	assert.Equal(t, "🔒 nic.xyz is reserved — reserved for the registry operator - RDAP is not found or doesn't exist (via rdap)", line)
+
+	app.Config.OnlyAvailable = true
+	_, ok = styles.Render(reservedResult("nic.xyz"))
+	assert.False(t, ok, "--only-available hides reserved names")
+}
+
+func TestCSVOutput_ReservedColumns(t *testing.T) {
+	out := captureStdout(func() {
+		w := output.NewCSVOutput()
+		w.Write(reservedResult("nic.xyz"))
+		w.Flush()
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], "nic.xyz,false,"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[1], ",true,reserved for the registry operator (icann)"), lines[1])
+}
+
+func TestJsonArrayOutput_Reserved(t *testing.T) {
+	var buf bytes.Buffer
+	w := output.NewJsonArrayOutput(&buf, config.NewTldxContext(), &output.Stats{})
+	w.Write(reservedResult("nic.xyz"))
+	w.Flush()
+
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, false, got[0]["available"])
+	assert.Equal(t, map[string]any{"list": "icann", "reason": "reserved for the registry operator"}, got[0]["reserved"])
 }
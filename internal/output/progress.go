@@ -0,0 +1,270 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// progressInterval is the least time between two redraws of the progress
+// line, so a fast sweep does not spend its time writing to the terminal.
+const progressInterval = 100 * time.Millisecond
+
+// plainProgressInterval is the least time between two progress lines when
+// stderr is not a terminal, where each one is a new line rather than a
+// redraw.
+const plainProgressInterval = 5 * time.Second
+
+// Progress draws a one-line progress indicator, redrawn in place, e.g.
+// "⏳ 1200/5000 checked · 85/s · ETA 44s · 12 available · 3 errors". It
+// writes to stderr so it never mixes into machine-readable stdout. A nil
+// *Progress draws nothing.
+//
+// On a terminal the line is erased before each result is printed and drawn
+// again after it, and NewProgress redraws it every progressInterval so the
+// rate and ETA move on while lookups stall. Elsewhere, e.g. stderr redirected to a log under
+// --progress, nothing can be erased, so it prints a line of its own every
+// plainProgressInterval instead.
+type Progress struct {
+	w     io.Writer
+	total int
+	now   func() time.Time
+	plain bool
+
+	// stop ends the redraws NewProgress starts; nil when there are none.
+	stop chan struct{}
+
+	mu        sync.Mutex
+	start     time.Time
+	last      time.Time
+	drawn     bool
+	done      bool
+	checked   int
+	available int
+	errored   int
+}
+
+// ProgressEnabled reports whether a run shows progress: --progress forces it
+// on, --no-progress off, and otherwise it is on when stderr is a terminal.
+func ProgressEnabled(cfg *config.TldxConfigOptions) bool {
+	switch {
+	case cfg.NoProgress:
+		return false
+	case cfg.Progress:
+		return true
+	}
+	return stderrIsTerminal()
+}
+
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// NewProgress returns an indicator for a run of total domains, or nil if
+// the config turns it off.
+func NewProgress(cfg *config.TldxConfigOptions, total int) *Progress {
+	if !ProgressEnabled(cfg) {
+		return nil
+	}
+	if !stderrIsTerminal() {
+		return NewPlainProgressWriter(os.Stderr, total, time.Now)
+	}
+	p := NewProgressWriter(os.Stderr, total, time.Now)
+	p.tickEvery(progressInterval)
+	return p
+}
+
+// tickEvery calls Tick every interval until Done.
+func (p *Progress) tickEvery(interval time.Duration) {
+	p.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.Tick()
+			}
+		}
+	}()
+}
+
+// NewProgressWriter draws to w as to a terminal, telling time by now. For
+// tests.
+func NewProgressWriter(w io.Writer, total int, now func() time.Time) *Progress {
+	start := now()
+	return &Progress{w: w, total: total, now: now, start: start}
+}
+
+// NewPlainProgressWriter prints lines of progress to w, which is not a
+// terminal, telling time by now.
+func NewPlainProgressWriter(w io.Writer, total int, now func() time.Time) *Progress {
+	p := NewProgressWriter(w, total, now)
+	p.plain = true
+	return p
+}
+
+// Update counts one more result and redraws, unless it drew very recently.
+func (p *Progress) Update(available, errored bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checked++
+	if available {
+		p.available++
+	}
+	if errored {
+		p.errored++
+	}
+	if p.done {
+		return
+	}
+	interval := progressInterval
+	if p.plain {
+		interval = plainProgressInterval
+	}
+	if now := p.now(); p.last.IsZero() || (!p.plain && !p.drawn) || now.Sub(p.last) >= interval || p.checked == p.total {
+		p.draw(now)
+	}
+}
+
+// Skip takes one domain off the total, for one the run drops without a
+// result to count, such as a domain a quota left unchecked. It is safe to
+// call while results are being written.
+func (p *Progress) Skip() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total--
+	// Drawn only over a line still on screen, not while a result is being
+	// written in its place.
+	if !p.done && p.checked == p.total && (p.plain || p.drawn) {
+		p.draw(p.now())
+	}
+}
+
+// Tick redraws the line on a terminal, so the rate and ETA keep moving
+// while no result arrives. It leaves alone a line erased for a result.
+func (p *Progress) Tick() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.plain && !p.done && (p.drawn || p.last.IsZero()) {
+		p.draw(p.now())
+	}
+}
+
+// Clear erases the progress line, so a line can be printed in its place.
+// Redraw puts it back below.
+func (p *Progress) Clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+// Redraw draws the progress line again after Clear, so it stays on screen
+// while the next lookups are pending.
+func (p *Progress) Redraw() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.plain && !p.done && !p.drawn && !p.last.IsZero() {
+		p.draw(p.now())
+	}
+}
+
+// Done erases the progress line for good and stops its redraws.
+func (p *Progress) Done() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	if p.stop != nil && !p.done {
+		close(p.stop)
+	}
+	p.done = true
+}
+
+// Wrap returns w, erasing the progress line before each result w writes and
+// drawing it again after, and erasing it for good before w flushes.
+func (p *Progress) Wrap(w ResultOutput) ResultOutput {
+	if p == nil {
+		return w
+	}
+	return &progressOutput{ResultOutput: w, progress: p}
+}
+
+type progressOutput struct {
+	ResultOutput
+	progress *Progress
+}
+
+func (o *progressOutput) Write(result resolver.DomainResult) {
+	o.progress.Clear()
+	o.ResultOutput.Write(result)
+	o.progress.Redraw()
+}
+
+func (o *progressOutput) Flush() {
+	o.progress.Done()
+	o.ResultOutput.Flush()
+}
+
+func (p *Progress) clear() {
+	if p.drawn && !p.plain {
+		fmt.Fprint(p.w, "\r\x1b[2K")
+		p.drawn = false
+	}
+}
+
+func (p *Progress) draw(now time.Time) {
+	if p.plain {
+		fmt.Fprintln(p.w, p.line(now))
+	} else {
+		p.clear()
+		fmt.Fprint(p.w, p.line(now))
+		p.drawn = true
+	}
+	p.last = now
+}
+
+// line is the progress text at now.
+func (p *Progress) line(now time.Time) string {
+	line := fmt.Sprintf("⏳ %d/%d checked", p.checked, p.total)
+
+	elapsed := now.Sub(p.start).Seconds()
+	if elapsed > 0 && p.checked > 0 {
+		rate := float64(p.checked) / elapsed
+		line += fmt.Sprintf(" · %.0f/s", rate)
+		if remaining := p.total - p.checked; remaining > 0 {
+			eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+			line += " · ETA " + eta.Round(time.Second).String()
+		}
+	}
+	line += fmt.Sprintf(" · %d available", p.available)
+	if p.errored > 0 {
+		line += fmt.Sprintf(" · %d errors", p.errored)
+	}
+	return line
+}
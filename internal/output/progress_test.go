@@ -0,0 +1,191 @@
+package output_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock advances by step every time it is read.
+type fakeClock struct {
+	t    time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(c.step)
+	return c.t
+}
+
+// lastLine is what the terminal shows after the redraws in out.
+func lastLine(out string) string {
+	parts := strings.Split(out, "\r\x1b[2K")
+	return parts[len(parts)-1]
+}
+
+func TestProgress_ShowsRateAndETA(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewProgressWriter(&buf, 10, clock.now)
+
+	p.Update(true, false)
+	p.Update(false, true)
+
+	// Two results in two seconds: one a second, eight seconds to go.
+	assert.Equal(t, "⏳ 2/10 checked · 1/s · ETA 8s · 1 available · 1 errors", lastLine(buf.String()))
+}
+
+func TestProgress_Throttles(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Millisecond}
+	p := output.NewProgressWriter(&buf, 100, clock.now)
+
+	for range 50 {
+		p.Update(false, false)
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "checked"), "redrawn at most every 100ms")
+
+	for range 50 {
+		p.Update(false, false)
+	}
+	assert.Contains(t, lastLine(buf.String()), "100/100 checked", "the last result is always drawn")
+	assert.NotContains(t, lastLine(buf.String()), "ETA")
+}
+
+func TestProgress_WrapClearsBeforeWritingAndOnFlush(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewProgressWriter(&buf, 2, clock.now)
+	w := p.Wrap(&echoOutput{w: &buf})
+
+	p.Update(true, false)
+	w.Write(resolver.DomainResult{Domain: "a.com", Available: true})
+	assert.Contains(t, buf.String(), "\r\x1b[2Ka.com\n", "cleared before the result")
+	assert.Contains(t, lastLine(buf.String()), "1/2 checked", "drawn again after the result")
+	assert.Contains(t, buf.String(), "a.com\n⏳ 1/2 checked", "the result stays above the line")
+
+	p.Update(true, false)
+	w.Flush()
+	assert.True(t, strings.HasSuffix(buf.String(), "\r\x1b[2K"), "cleared before flushing")
+
+	n := buf.Len()
+	p.Update(true, false)
+	w.Write(resolver.DomainResult{Domain: "b.com"})
+	assert.Equal(t, "b.com\n", buf.String()[n:], "nothing drawn once done")
+}
+
+func TestProgress_RedrawsAfterAThrottledWrite(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Millisecond}
+	p := output.NewProgressWriter(&buf, 10, clock.now)
+	w := p.Wrap(&echoOutput{w: &buf})
+
+	for _, domain := range []string{"a.com", "b.com", "c.com"} {
+		p.Update(false, false)
+		w.Write(resolver.DomainResult{Domain: domain})
+		assert.Contains(t, lastLine(buf.String()), "checked",
+			"the line is back while the next lookups are pending, even within the redraw interval")
+	}
+	assert.Contains(t, lastLine(buf.String()), "3/10 checked")
+}
+
+func TestProgress_PlainPrintsLinesWithoutEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewPlainProgressWriter(&buf, 10, clock.now)
+	w := p.Wrap(&discardOutput{})
+
+	for range 10 {
+		p.Update(true, false)
+		w.Write(resolver.DomainResult{})
+	}
+	w.Flush()
+
+	assert.NotContains(t, buf.String(), "\x1b")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, []string{
+		"⏳ 1/10 checked · 1/s · ETA 9s · 1 available",
+		"⏳ 6/10 checked · 1/s · ETA 4s · 6 available",
+		"⏳ 10/10 checked · 1/s · 10 available",
+	}, lines, "one line every few seconds, and the last")
+}
+
+func TestProgress_TickMovesTheETAOnWhileResultsStall(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewProgressWriter(&buf, 10, clock.now)
+
+	p.Update(false, false)
+	assert.Equal(t, "⏳ 1/10 checked · 1/s · ETA 9s · 0 available", lastLine(buf.String()))
+
+	// No result for three more seconds: the rate falls and the ETA grows.
+	clock.step = 3 * time.Second
+	p.Tick()
+	assert.Equal(t, "⏳ 1/10 checked · 0/s · ETA 36s · 0 available", lastLine(buf.String()))
+}
+
+func TestProgress_TickLeavesAnErasedLineAlone(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewProgressWriter(&buf, 10, clock.now)
+
+	p.Update(false, false)
+	p.Clear()
+	before := buf.String()
+	p.Tick()
+	assert.Equal(t, before, buf.String(), "not drawn while a result is written in its place")
+
+	p.Done()
+	p.Tick()
+	assert.Equal(t, before, buf.String(), "not drawn once done")
+}
+
+func TestProgress_SkipTakesDomainsOffTheTotal(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	p := output.NewPlainProgressWriter(&buf, 4, clock.now)
+
+	p.Update(true, false)
+	p.Skip()
+	p.Update(false, false)
+	p.Skip()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, "⏳ 2/2 checked · 1/s · 1 available", lines[len(lines)-1],
+		"drawn as finished once the last domain is dropped")
+}
+
+func TestProgress_NilDrawsNothing(t *testing.T) {
+	var p *output.Progress
+	p.Update(true, true)
+	p.Skip()
+	p.Tick()
+	p.Clear()
+	p.Done()
+
+	w := &discardOutput{}
+	assert.Same(t, w, p.Wrap(w))
+}
+
+type discardOutput struct{}
+
+func (*discardOutput) Write(resolver.DomainResult) {}
+func (*discardOutput) Flush()                      {}
+
+// echoOutput prints each domain on a line of its own, as the text format
+// would.
+type echoOutput struct{ w *bytes.Buffer }
+
+func (o *echoOutput) Write(result resolver.DomainResult) { o.w.WriteString(result.Domain + "\n") }
+func (*echoOutput) Flush()                               {}
+
+func TestProgressEnabled(t *testing.T) {
+	assert.True(t, output.ProgressEnabled(&config.TldxConfigOptions{Progress: true}))
+	assert.False(t, output.ProgressEnabled(&config.TldxConfigOptions{Progress: true, NoProgress: true}))
+}
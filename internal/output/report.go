@@ -0,0 +1,216 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// report is what the markdown and html formats render: the run parameters,
+// the stats, and the results in a section per keyword.
+type report struct {
+	Generated string
+	Params    []reportParam
+	Stats     []reportStat
+	Groups    []reportGroup
+}
+
+type reportParam struct {
+	Name, Value string
+}
+
+type reportStat struct {
+	Emoji, Label string
+	Count        int
+}
+
+type reportGroup struct {
+	Keyword string
+	Rows    []reportRow
+}
+
+type reportRow struct {
+	Domain string
+	// Status is one of available, for sale, reserved, taken or error.
+	Status string
+	Emoji  string
+	// Price is the registrar quote and PriceValue its amount for sorting,
+	// -1 without a quote.
+	Price      string
+	PriceValue float64
+	// Details are the for-sale prices, the reserved reason, or the error.
+	Details string
+	// Links are the trusted for-sale URIs. Untrusted ones are left out of
+	// reports, which are meant to be shared.
+	Links []string
+}
+
+// reportCollector gathers the results shown, for a report on Flush.
+type reportCollector struct {
+	app          *config.TldxContext
+	styleService *StyleService
+	stats        *Stats
+	results      []resolver.DomainResult
+}
+
+func newReportCollector(app *config.TldxContext, stats *Stats) reportCollector {
+	return reportCollector{
+		app:          app,
+		stats:        stats,
+		styleService: NewStyleService(app),
+		results:      make([]resolver.DomainResult, 0, 100),
+	}
+}
+
+func (c *reportCollector) Write(result resolver.DomainResult) {
+	if _, ok := c.styleService.Render(result); ok {
+		c.results = append(c.results, result)
+	}
+}
+
+// report builds the report from the results so far and the run's stats.
+func (c *reportCollector) report(now time.Time) report {
+	grouped := make(map[string][]resolver.DomainResult)
+	for _, result := range c.results {
+		keyword := keywordOf(c.app.Config, result)
+		grouped[keyword] = append(grouped[keyword], result)
+	}
+	keywords := make([]string, 0, len(grouped))
+	for keyword := range grouped {
+		keywords = append(keywords, keyword)
+	}
+	slices.Sort(keywords)
+
+	r := report{
+		Generated: now.Format("2006-01-02 15:04 MST"),
+		Params:    reportParams(c.app.Config, keywords),
+		Stats:     reportStats(c.stats),
+	}
+	for _, keyword := range keywords {
+		results := grouped[keyword]
+		slices.SortFunc(results, func(a, b resolver.DomainResult) int {
+			return strings.Compare(a.Domain, b.Domain)
+		})
+		group := reportGroup{Keyword: keyword}
+		for _, result := range results {
+			group.Rows = append(group.Rows, newReportRow(result))
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	return r
+}
+
+func newReportRow(result resolver.DomainResult) reportRow {
+	row := reportRow{Domain: result.Domain, PriceValue: -1}
+	row.Emoji, row.Status = matrixCell(result)
+
+	if result.Pricing != nil {
+		row.Price = pricingDetails(result.Pricing)
+		row.PriceValue = result.Pricing.Registration
+	}
+	switch {
+	case result.Error != nil:
+		row.Details = result.Error.Error()
+	case result.ForSale != nil:
+		var prices []string
+		for _, price := range result.ForSale.Prices {
+			prices = append(prices, price.String())
+		}
+		row.Details = strings.Join(prices, " · ")
+		row.Links = result.ForSale.TrustedURIs()
+	case result.Reserved != nil:
+		row.Details = result.Reserved.String()
+	}
+	return row
+}
+
+// reportParams lists the settings that shaped the run, leaving out the ones
+// left at their defaults.
+func reportParams(cfg *config.TldxConfigOptions, keywords []string) []reportParam {
+	var params []reportParam
+	add := func(name, value string) {
+		if value != "" {
+			params = append(params, reportParam{name, value})
+		}
+	}
+	add("Keywords", strings.Join(keywords, ", "))
+	add("TLDs", strings.Join(cfg.TLDs, ", "))
+	add("TLD preset", cfg.TLDPreset)
+	add("Prefixes", strings.Join(cfg.Prefixes, ", "))
+	add("Suffixes", strings.Join(cfg.Suffixes, ", "))
+	if cfg.Regex {
+		add("Regex", "on")
+	}
+	if cfg.OnlyAvailable {
+		add("Shown", "available only")
+	} else if cfg.OnlyForSale {
+		add("Shown", "for sale only")
+	}
+	if cfg.MaxPrice > 0 {
+		add("Max price", strconv.FormatFloat(cfg.MaxPrice, 'f', -1, 64))
+	}
+	if cfg.Limit > 0 {
+		add("Limit", strconv.Itoa(cfg.Limit))
+	}
+	if cfg.LimitPerTLD > 0 {
+		add("Limit per TLD", strconv.Itoa(cfg.LimitPerTLD))
+	}
+	if cfg.LimitPerKeyword > 0 {
+		add("Limit per keyword", strconv.Itoa(cfg.LimitPerKeyword))
+	}
+	if cfg.Order != "" && cfg.Order != "input" {
+		add("Order", cfg.Order)
+	}
+	add("Backends", strings.Join(cfg.Backends, ", "))
+	if cfg.Verify {
+		add("Verify", "on")
+	}
+	return params
+}
+
+// reportStats is the stats as rows, with the same optional rows as the text
+// summary.
+func reportStats(stats *Stats) []reportStat {
+	rows := []reportStat{
+		{"🔍", "Searched", stats.Total},
+		{"✅", "Available", stats.Available},
+		{"❌", "Taken", stats.NotAvailable},
+		{"⏳", "Timed out", stats.TimedOut},
+		{"🟡", "Errored", stats.Errored},
+	}
+	if stats.ForSale > 0 {
+		rows = append(rows, reportStat{"💰", "For sale", stats.ForSale})
+	}
+	if stats.Reserved > 0 {
+		rows = append(rows, reportStat{"🔒", "Reserved", stats.Reserved})
+	}
+	if stats.Prefiltered > 0 {
+		rows = append(rows, reportStat{"⚡", "Prefiltered", stats.Prefiltered})
+	}
+	if stats.QuotaSkipped > 0 {
+		rows = append(rows, reportStat{"⏭", "Skipped by quota", stats.QuotaSkipped})
+	}
+	return rows
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (r reportRow) markdownDetails() string {
+	parts := []string{}
+	if r.Details != "" {
+		parts = append(parts, markdownCell(r.Details))
+	}
+	for _, link := range r.Links {
+		parts = append(parts, fmt.Sprintf("<%s>", markdownCell(link)))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,114 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+)
+
+func reportApp() *config.TldxContext {
+	app := config.NewTldxContext()
+	app.Config.NoColor = true
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.Prefixes = []string{"get"}
+	app.Config.Limit = 5
+	return app
+}
+
+func reportResults() []resolver.DomainResult {
+	return []resolver.DomainResult{
+		{Domain: "stripe.io", Available: true, Keyword: "stripe", TLD: "io",
+			Pricing: &registrar.Pricing{Currency: "USD", Registration: 39.5}},
+		{Domain: "stripe.com", Keyword: "stripe", TLD: "com", ForSale: &forsale.Info{
+			Prices: []forsale.Price{{Currency: "USD", Amount: "5000"}},
+			URIs: []forsale.URI{
+				{Value: "https://broker.example/stripe.com", Scheme: "https", Trusted: true},
+				{Value: "javascript:alert(1)", Scheme: "javascript"},
+			},
+		}},
+		{Domain: "getatlas.com", Keyword: "atlas", Prefix: "get", TLD: "com",
+			Reserved: &reserved.Match{List: "icann", Reason: "Reserved | by registry"}},
+		{Domain: "atlas.io", Keyword: "atlas", TLD: "io", Error: errors.New("timeout")},
+	}
+}
+
+func TestMarkdownOutput_Report(t *testing.T) {
+	stats := &output.Stats{Total: 4, Available: 1, ForSale: 1, Reserved: 1, Errored: 1}
+
+	var buf bytes.Buffer
+	w := output.NewMarkdownOutput(&buf, reportApp(), stats)
+	for _, result := range reportResults() {
+		w.Write(result)
+	}
+	w.Flush()
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "# Domain report\n"))
+	assert.Contains(t, out, "| Keywords | atlas, stripe |")
+	assert.Contains(t, out, "| Prefixes | get |")
+	assert.Contains(t, out, "| Limit | 5 |")
+	assert.Contains(t, out, "| 🔍 Searched | 4 |")
+	assert.Contains(t, out, "| 💰 For sale | 1 |")
+	assert.NotContains(t, out, "Prefiltered", "optional rows only when present")
+
+	assert.Less(t, strings.Index(out, "## atlas"), strings.Index(out, "## stripe"), "sections by keyword")
+	assert.Contains(t, out, "| stripe.io | ✅ available | USD 39.50 |  |")
+	assert.Contains(t, out, "| stripe.com | 💰 for sale |  | USD 5000 · <https://broker.example/stripe.com> |")
+	assert.Contains(t, out, `Reserved \| by registry (icann)`, "pipes escaped")
+	assert.Contains(t, out, "| atlas.io | 🟡 error |  | timeout |")
+	assert.NotContains(t, out, "javascript:", "untrusted links left out")
+}
+
+func TestHTMLOutput_Report(t *testing.T) {
+	stats := &output.Stats{Total: 4, Available: 1, ForSale: 1}
+
+	var buf bytes.Buffer
+	w := output.NewHTMLOutput(&buf, reportApp(), stats)
+	for _, result := range reportResults() {
+		w.Write(result)
+	}
+	w.Flush()
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.NotContains(t, out, "<link", "self-contained")
+	assert.NotContains(t, out, "<script src", "self-contained")
+	assert.Contains(t, out, "<h2>stripe</h2>")
+	assert.Contains(t, out, `<tr data-domain="stripe.io" data-status="available">`)
+	assert.Contains(t, out, `<td data-value="39.5">USD 39.50</td>`)
+	assert.Contains(t, out, `<td data-value="-1"></td>`, "unquoted domains sort last")
+	assert.Contains(t, out, `<a href="https://broker.example/stripe.com" rel="noopener noreferrer">`)
+	assert.Contains(t, out, `class="for-sale"`)
+	assert.Contains(t, out, "Reserved | by registry (icann)")
+	assert.NotContains(t, out, "javascript:alert")
+	assert.Contains(t, out, `id="filter"`)
+}
+
+func TestHTMLOutput_EscapesResults(t *testing.T) {
+	var buf bytes.Buffer
+	w := output.NewHTMLOutput(&buf, reportApp(), &output.Stats{})
+	w.Write(resolver.DomainResult{Domain: "x.com", Error: errors.New("<script>bad()</script>")})
+	w.Flush()
+	assert.NotContains(t, buf.String(), "<script>bad()")
+	assert.Contains(t, buf.String(), "&lt;script&gt;bad()")
+}
+
+func TestMarkdownOutput_OnlyAvailableHidesErrors(t *testing.T) {
+	app := reportApp()
+	app.Config.OnlyAvailable = true
+	var buf bytes.Buffer
+	w := output.NewMarkdownOutput(&buf, app, &output.Stats{})
+	w.Write(resolver.DomainResult{Domain: "atlas.io", Keyword: "atlas", Error: errors.New("timeout")})
+	w.Flush()
+	assert.NotContains(t, buf.String(), "atlas.io")
+	assert.Contains(t, buf.String(), "| Shown | available only |")
+}
@@ -1,23 +1,186 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Stats formats for --stats-format.
+const (
+	StatsFormatText = "text"
+	StatsFormatJSON = "json"
+)
+
+// Why a run stopped early, for Stats.Stopped.
+const (
+	StoppedLimit     = "limit"
+	StoppedCancelled = "cancelled"
+)
+
+// Stats describes one run. Each run makes its own with NewStats, counts its
+// results into it with Add, and hands it to the writers that show it.
 type Stats struct {
 	Total        int
 	Available    int
 	NotAvailable int
 	ForSale      int
-	TimedOut     int
-	Errored      int
+	Reserved     int
+	// TimedOut counts lookups that ran out of time, and Errored the ones
+	// that failed any other way.
+	TimedOut int
+	Errored  int
+	// Prefiltered counts lookups the prefilter saved.
+	Prefiltered int
+	// Quotas reports --limit-per-tld and --limit-per-keyword, and
+	// QuotaSkipped the domains they left unchecked.
+	Quotas       []QuotaStatus
+	QuotaSkipped int
+
+	// WallTime is how long the run took, and Latency how long its lookups
+	// took, retries included. Both are set by Finish, in nanoseconds when
+	// encoded.
+	WallTime time.Duration
+	Latency  Latency
+	// Retries counts the lookup attempts retried after a transient error.
+	Retries int
+	// TLDs counts the results per TLD, Sources per verdict source (rdap,
+	// dns, whois, ...) and Errors per error category (timeout, network,
+	// ...).
+	TLDs    map[string]TLDStats
+	Sources map[string]int
+	Errors  map[string]int
+
+	// Keywords are the run's input as given, Warnings the problems found
+	// with it, and Stopped why the run ended before every domain was
+	// checked: StoppedLimit, StoppedCancelled, or empty when it did not. The
+	// ndjson-summary format reports them beside the stats.
+	Keywords []string `json:"-"`
+	Warnings []string `json:"-"`
+	Stopped  string   `json:"-"`
+
+	started   time.Time
+	latencies []time.Duration
+}
+
+// Latency is the 50th, 95th and 99th percentile of the lookup times.
+type Latency struct {
+	P50, P95, P99 time.Duration
+}
+
+// TLDStats is how one TLD fared.
+type TLDStats struct {
+	Checked   int
+	Available int
+}
+
+// QuotaStatus is how far one TLD or keyword got towards its quota.
+type QuotaStatus struct {
+	Kind  string `json:"kind"`
+	Key   string `json:"key"`
+	Found int    `json:"found"`
+	Limit int    `json:"limit"`
+	Met   bool   `json:"met"`
+}
+
+// NewStats starts the stats of a run of total domains.
+func NewStats(total int) *Stats {
+	return &Stats{
+		Total:   total,
+		TLDs:    map[string]TLDStats{},
+		Sources: map[string]int{},
+		Errors:  map[string]int{},
+		started: time.Now(),
+	}
+}
+
+// Add counts one result.
+func (s *Stats) Add(result resolver.DomainResult) {
+	if result.Error != nil {
+		category := resolver.ErrorCategory(result.Error)
+		if category == resolver.ErrorTimeout {
+			s.TimedOut++
+		} else {
+			s.Errored++
+		}
+		s.Errors[category]++
+	} else if result.Available {
+		s.Available++
+	} else if result.Reserved != nil {
+		s.Reserved++
+	} else {
+		s.NotAvailable++
+	}
+	if result.ForSale != nil {
+		s.ForSale++
+	}
+
+	if result.Error == nil && result.Source != "" {
+		s.Sources[result.Source]++
+	}
+	_, tld := matrixKey(result)
+	t := s.TLDs[tld]
+	t.Checked++
+	if result.Error == nil && result.Available {
+		t.Available++
+	}
+	s.TLDs[tld] = t
+
+	s.Retries += result.Retries
+	if result.Duration > 0 {
+		s.latencies = append(s.latencies, result.Duration)
+	}
+}
+
+// Finish records the wall time and the lookup latencies, once the last
+// result is in.
+func (s *Stats) Finish() {
+	s.WallTime = time.Since(s.started)
+	slices.Sort(s.latencies)
+	s.Latency = Latency{
+		P50: percentile(s.latencies, 50),
+		P95: percentile(s.latencies, 95),
+		P99: percentile(s.latencies, 99),
+	}
+}
+
+// percentile is the nearest-rank pth percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
 }
 
-var Stat = Stats{}
+// WriteStats shows stats as --show-stats and --stats-format ask: a summary on
+// stdout after text output, or JSON on stderr after any format.
+func WriteStats(cfg *config.TldxConfigOptions, stats *Stats, stdout, stderr io.Writer) {
+	if !cfg.ShowStats {
+		return
+	}
+	if cfg.StatsFormat == StatsFormatJSON {
+		enc := json.NewEncoder(stderr)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			fmt.Fprintf(stderr, "error encoding stats: %v\n", err)
+		}
+		return
+	}
+	if cfg.OutputFormat == "text" {
+		fmt.Fprintln(stdout, RenderStatsSummary(stats))
+	}
+}
 
-func RenderStatsSummary() string {
+func RenderStatsSummary(stats *Stats) string {
 	baseStyle := lipgloss.NewStyle().Bold(true)
 
 	// Widths for number and label padding
@@ -36,20 +199,26 @@ func RenderStatsSummary() string {
 		color string
 	}
 
-	stats := []statRow{
-		{"🔍", Stat.Total, "searched", "14"},      // Bright Blue
-		{"✅", Stat.Available, "available", "10"}, // Bright Green
-		{"❌", Stat.NotAvailable, "taken", "9"},   // Red
-		{"⏳", Stat.TimedOut, "timed out", "12"},  // Intense Yellow
-		{"🟡", Stat.Errored, "errored", "3"},      // Yellow
+	rows := []statRow{
+		{"🔍", stats.Total, "searched", "14"},      // Bright Blue
+		{"✅", stats.Available, "available", "10"}, // Bright Green
+		{"❌", stats.NotAvailable, "taken", "9"},   // Red
+		{"⏳", stats.TimedOut, "timed out", "12"},  // Intense Yellow
+		{"🟡", stats.Errored, "errored", "3"},      // Yellow
 	}
 
-	if Stat.ForSale > 0 {
-		stats = append(stats, statRow{"💰", Stat.ForSale, "for sale", "13"}) // Magenta
+	if stats.ForSale > 0 {
+		rows = append(rows, statRow{"💰", stats.ForSale, "for sale", "13"}) // Magenta
+	}
+	if stats.Reserved > 0 {
+		rows = append(rows, statRow{"🔒", stats.Reserved, "reserved", "208"}) // Orange
+	}
+	if stats.Prefiltered > 0 {
+		rows = append(rows, statRow{"⚡", stats.Prefiltered, "prefiltered", "6"}) // Cyan
 	}
 
 	var blocks []string
-	for _, stat := range stats {
+	for _, stat := range rows {
 		// emoji + space + padded number + space + padded label
 		formatted := fmt.Sprintf(
 			"%s%*d %-*s",
@@ -73,5 +242,95 @@ func RenderStatsSummary() string {
 		Align(lipgloss.Left).
 		BorderForeground(lipgloss.Color("14"))
 
-	return border.Render(content)
+	return border.Render(content) + renderDetails(stats) + renderQuotas(stats.Quotas, stats.QuotaSkipped)
+}
+
+// renderDetails lists the timing and the counts by TLD, source and error
+// under the summary.
+func renderDetails(stats *Stats) string {
+	var lines []string
+	if stats.WallTime > 0 {
+		line := "Took " + stats.WallTime.Round(time.Millisecond).String()
+		if stats.Latency.P50 > 0 {
+			line += fmt.Sprintf(" · lookups p50 %s · p95 %s · p99 %s",
+				stats.Latency.P50.Round(time.Millisecond),
+				stats.Latency.P95.Round(time.Millisecond),
+				stats.Latency.P99.Round(time.Millisecond))
+		}
+		if stats.Retries > 0 {
+			line += fmt.Sprintf(" · %d retries", stats.Retries)
+		}
+		lines = append(lines, line)
+	}
+	if len(stats.TLDs) > 0 {
+		var parts []string
+		for _, tld := range slices.Sorted(maps.Keys(stats.TLDs)) {
+			t := stats.TLDs[tld]
+			parts = append(parts, fmt.Sprintf(".%s %d/%d", tld, t.Available, t.Checked))
+		}
+		lines = append(lines, "Available per TLD: "+strings.Join(parts, "  "))
+	}
+	if line := renderCounts(stats.Sources); line != "" {
+		lines = append(lines, "Verdicts by source: "+line)
+	}
+	if line := renderCounts(stats.Errors); line != "" {
+		lines = append(lines, "Errors: "+line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// renderCounts is counts as "key n", most first.
+func renderCounts(counts map[string]int) string {
+	keys := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(keys, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// renderQuotas lists each quota under the summary, met ones in green.
+func renderQuotas(quotas []QuotaStatus, skipped int) string {
+	if len(quotas) == 0 {
+		return ""
+	}
+
+	var lines []string
+	byKind := map[string][]string{}
+	var kinds []string
+	for _, q := range quotas {
+		if _, ok := byKind[q.Kind]; !ok {
+			kinds = append(kinds, q.Kind)
+		}
+		key := q.Key
+		if q.Kind == "tld" {
+			key = "." + key
+		}
+		color := "11" // Yellow
+		if q.Met {
+			color = "10" // Bright Green
+		}
+		byKind[q.Kind] = append(byKind[q.Kind],
+			lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(fmt.Sprintf("%s %d/%d", key, q.Found, q.Limit)))
+	}
+	for _, kind := range kinds {
+		label := kind
+		if kind == "tld" {
+			label = "TLD"
+		}
+		lines = append(lines, fmt.Sprintf("Per-%s quotas: %s", label, strings.Join(byKind[kind], "  ")))
+	}
+	if skipped > 0 {
+		lines = append(lines, fmt.Sprintf("%d domain(s) skipped once their quota was met", skipped))
+	}
+	return "\n" + strings.Join(lines, "\n")
 }
@@ -0,0 +1,97 @@
+package output_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_Add(t *testing.T) {
+	stats := output.NewStats(5)
+	stats.Add(resolver.DomainResult{Domain: "a.com", TLD: "com", Available: true, Source: resolver.SourceRDAP, Duration: time.Millisecond})
+	stats.Add(resolver.DomainResult{Domain: "b.com", TLD: "com", Source: resolver.SourceRDAP, Retries: 2, Duration: 3 * time.Millisecond})
+	stats.Add(resolver.DomainResult{Domain: "a.io", TLD: "io", Available: true, Source: resolver.SourceDNS, Reserved: &reserved.Match{}})
+	stats.Add(resolver.DomainResult{Domain: "b.io", TLD: "io", Available: true, Error: context.DeadlineExceeded})
+	stats.Add(resolver.DomainResult{Domain: "c.io", TLD: "io", Available: true, Error: errors.New("connection refused")})
+
+	assert.Equal(t, 2, stats.Available)
+	assert.Equal(t, 1, stats.NotAvailable)
+	assert.Equal(t, 1, stats.TimedOut)
+	assert.Equal(t, 1, stats.Errored, "a timeout is not counted twice")
+	assert.Equal(t, 2, stats.Retries)
+	assert.Equal(t, map[string]output.TLDStats{"com": {Checked: 2, Available: 1}, "io": {Checked: 3, Available: 1}}, stats.TLDs)
+	assert.Equal(t, map[string]int{"rdap": 2, "dns": 1}, stats.Sources)
+	assert.Equal(t, map[string]int{"timeout": 1, "network": 1}, stats.Errors)
+}
+
+func TestStats_FinishLatency(t *testing.T) {
+	stats := output.NewStats(100)
+	for i := 100; i >= 1; i-- {
+		stats.Add(resolver.DomainResult{Domain: "x.com", Duration: time.Duration(i) * time.Millisecond})
+	}
+	stats.Add(resolver.DomainResult{Domain: "prefiltered.com", Source: resolver.SourcePrefilter})
+	stats.Finish()
+
+	assert.Equal(t, output.Latency{P50: 50 * time.Millisecond, P95: 95 * time.Millisecond, P99: 99 * time.Millisecond}, stats.Latency,
+		"lookups that were never made do not count")
+	assert.Positive(t, stats.WallTime)
+}
+
+func TestStats_RunsAreIndependent(t *testing.T) {
+	first, second := output.NewStats(1), output.NewStats(1)
+	first.Add(resolver.DomainResult{Domain: "a.com", TLD: "com", Available: true})
+	assert.Equal(t, 1, first.Available)
+	assert.Zero(t, second.Available)
+	assert.Empty(t, second.TLDs)
+}
+
+func TestRenderStatsSummary_Details(t *testing.T) {
+	stats := output.NewStats(2)
+	stats.Add(resolver.DomainResult{Domain: "a.com", TLD: "com", Available: true, Source: resolver.SourceRDAP, Retries: 1, Duration: 20 * time.Millisecond})
+	stats.Add(resolver.DomainResult{Domain: "a.io", TLD: "io", Error: errors.New("HTTP 429")})
+	stats.Finish()
+
+	rendered := output.RenderStatsSummary(stats)
+	assert.Contains(t, rendered, "timed out")
+	assert.Contains(t, rendered, "p50 20ms")
+	assert.Contains(t, rendered, "1 retries")
+	assert.Contains(t, rendered, ".com 1/1  .io 0/1")
+	assert.Contains(t, rendered, "Verdicts by source: rdap 1")
+	assert.Contains(t, rendered, "Errors: rate-limited 1")
+}
+
+func TestWriteStats(t *testing.T) {
+	stats := output.NewStats(1)
+	stats.Add(resolver.DomainResult{Domain: "a.com", TLD: "com", Available: true, Source: resolver.SourceRDAP})
+	stats.Finish()
+
+	var stdout, stderr bytes.Buffer
+	output.WriteStats(&config.TldxConfigOptions{OutputFormat: "text"}, stats, &stdout, &stderr)
+	assert.Empty(t, stdout.String()+stderr.String(), "nothing without --show-stats")
+
+	output.WriteStats(&config.TldxConfigOptions{ShowStats: true, OutputFormat: "csv"}, stats, &stdout, &stderr)
+	assert.Empty(t, stdout.String()+stderr.String(), "the text summary follows text output only")
+
+	output.WriteStats(&config.TldxConfigOptions{ShowStats: true, OutputFormat: "text"}, stats, &stdout, &stderr)
+	assert.Contains(t, stdout.String(), "searched")
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	output.WriteStats(&config.TldxConfigOptions{ShowStats: true, OutputFormat: "csv", StatsFormat: output.StatsFormatJSON}, stats, &stdout, &stderr)
+	assert.Empty(t, stdout.String())
+	var decoded output.Stats
+	require.NoError(t, json.Unmarshal(stderr.Bytes(), &decoded))
+	assert.Equal(t, 1, decoded.Available)
+	assert.Equal(t, map[string]int{"rdap": 1}, decoded.Sources)
+	assert.Equal(t, stats.WallTime, decoded.WallTime)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
 	"github.com/brandonyoungdev/tldx/internal/resolver"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -36,21 +37,84 @@ func NewStyleServiceDirect(app *config.TldxContext, noColor bool) *StyleService
 }
 
 func (s *StyleService) Available(domain resolver.DomainResult) string {
-	text := fmt.Sprintf("✅ %s is available", domain.Domain)
+	text := fmt.Sprintf("✅ %s is available%s", domain.Domain, confidenceNote(domain))
+	if pricing := pricingDetails(domain.Pricing); pricing != "" {
+		text = fmt.Sprintf("%s — %s", text, pricing)
+	}
 	if s.app.Config.Verbose {
-		text = fmt.Sprintf("%s - %v", text, domain.Details)
+		text = fmt.Sprintf("%s - %s", text, verboseDetails(domain))
 	}
 	return s.Styled(text, "10") // green
 }
 
 func (s *StyleService) NotAvailable(domain resolver.DomainResult) string {
-	text := fmt.Sprintf("❌ %s is not available", domain.Domain)
+	text := fmt.Sprintf("❌ %s is not available%s", domain.Domain, confidenceNote(domain))
 	if s.app.Config.Verbose {
-		text = fmt.Sprintf("%s - %v", text, domain.Details)
+		text = fmt.Sprintf("%s - %s", text, verboseDetails(domain))
 	}
 	return s.Styled(text, "9") // red
 }
 
+// Reserved is a domain no source found registered, but that a reserved-name
+// list says the registry will not sell.
+func (s *StyleService) Reserved(domain resolver.DomainResult) string {
+	text := fmt.Sprintf("🔒 %s is reserved", domain.Domain)
+	if domain.Reserved != nil {
+		text = fmt.Sprintf("%s — %s", text, domain.Reserved.Reason)
+	}
+	if s.app.Config.Verbose {
+		text = fmt.Sprintf("%s - %s", text, verboseDetails(domain))
+	}
+	return s.Styled(text, "208") // orange
+}
+
+// pricingDetails renders a registrar quote, e.g.
+// "USD 9.68 · renews USD 10.50 · premium".
+func pricingDetails(pricing *registrar.Pricing) string {
+	if pricing == nil {
+		return ""
+	}
+
+	parts := []string{pricing.String()}
+	if pricing.Renewal > 0 {
+		parts = append(parts, "renews "+registrar.FormatPrice(pricing.Currency, pricing.Renewal))
+	}
+	if pricing.Premium {
+		parts = append(parts, "premium")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// confidenceNote labels a --verify verdict, e.g. " (confirmed)", and calls
+// out sources that disagree.
+func confidenceNote(domain resolver.DomainResult) string {
+	switch domain.Confidence {
+	case "":
+		return ""
+	case resolver.ConfidenceConflicting:
+		return " (conflicting: sources disagree)"
+	default:
+		return fmt.Sprintf(" (%s)", domain.Confidence)
+	}
+}
+
+// verboseDetails appends the verdict source to the details, e.g.
+// "Rdap registered: [active] (via rdap)". In --verify mode it lists every
+// source's answer instead.
+func verboseDetails(domain resolver.DomainResult) string {
+	if len(domain.Verdicts) > 0 {
+		parts := make([]string, 0, len(domain.Verdicts))
+		for _, v := range domain.Verdicts {
+			parts = append(parts, fmt.Sprintf("%s: %s", v.Source, v.Verdict))
+		}
+		return fmt.Sprintf("%s [%s]", domain.Details, strings.Join(parts, ", "))
+	}
+	if domain.Source == "" {
+		return domain.Details
+	}
+	return fmt.Sprintf("%s (via %s)", domain.Details, domain.Source)
+}
+
 func (s *StyleService) ForSale(domain resolver.DomainResult) string {
 	text := fmt.Sprintf("💰 %s is taken but for sale", domain.Domain)
 
@@ -96,6 +160,11 @@ func (s *StyleService) Render(result resolver.DomainResult) (string, bool) {
 		return s.Available(result), true
 	case result.ForSale != nil:
 		return s.ForSale(result), true
+	case result.Reserved != nil:
+		if s.app.Config.OnlyAvailable || s.app.Config.OnlyForSale {
+			return "", false
+		}
+		return s.Reserved(result), true
 	default:
 		if s.app.Config.OnlyAvailable || s.app.Config.OnlyForSale {
 			return "", false
@@ -0,0 +1,180 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// FormatNDJSONSummary is json-stream with every line tagged by its type and
+// a summary line last.
+const FormatNDJSONSummary = "ndjson-summary"
+
+// Record types of the ndjson-summary format.
+const (
+	RecordResult  = "result"
+	RecordSummary = "summary"
+)
+
+// NDJSONSummaryOutput streams each result as a {"type":"result",...} line as
+// it arrives, then ends with a {"type":"summary",...} line on Flush, so a
+// consumer knows the run is over and whether it finished.
+type NDJSONSummaryOutput struct {
+	w     io.Writer
+	app   *config.TldxContext
+	stats *Stats
+}
+
+func NewNDJSONSummaryOutput(w io.Writer, app *config.TldxContext, stats *Stats) *NDJSONSummaryOutput {
+	return &NDJSONSummaryOutput{w: w, app: app, stats: stats}
+}
+
+type resultRecord struct {
+	Type string `json:"type"`
+	resolver.EncodableDomainResult
+}
+
+// summaryRecord ends an ndjson-summary stream. Complete is false when the
+// run stopped early, for the reason in Stopped.
+type summaryRecord struct {
+	Type     string       `json:"type"`
+	Complete bool         `json:"complete"`
+	Stopped  string       `json:"stopped,omitempty"`
+	Params   runParams    `json:"params"`
+	Warnings []string     `json:"warnings"`
+	Stats    summaryStats `json:"stats"`
+}
+
+// summaryStats is Stats with the snake_case keys of the rest of the
+// summary record. json-array and the other formats keep Stats' own names.
+type summaryStats struct {
+	Total        int                        `json:"total"`
+	Available    int                        `json:"available"`
+	NotAvailable int                        `json:"not_available"`
+	ForSale      int                        `json:"for_sale"`
+	Reserved     int                        `json:"reserved"`
+	TimedOut     int                        `json:"timed_out"`
+	Errored      int                        `json:"errored"`
+	Prefiltered  int                        `json:"prefiltered"`
+	Quotas       []QuotaStatus              `json:"quotas"`
+	QuotaSkipped int                        `json:"quota_skipped"`
+	WallTime     time.Duration              `json:"wall_time"`
+	Latency      summaryLatency             `json:"latency"`
+	Retries      int                        `json:"retries"`
+	TLDs         map[string]summaryTLDStats `json:"tlds"`
+	Sources      map[string]int             `json:"sources"`
+	Errors       map[string]int             `json:"errors"`
+}
+
+type summaryLatency struct {
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+}
+
+type summaryTLDStats struct {
+	Checked   int `json:"checked"`
+	Available int `json:"available"`
+}
+
+func newSummaryStats(s *Stats) summaryStats {
+	tlds := make(map[string]summaryTLDStats, len(s.TLDs))
+	for tld, t := range s.TLDs {
+		tlds[tld] = summaryTLDStats{Checked: t.Checked, Available: t.Available}
+	}
+	return summaryStats{
+		Total:        s.Total,
+		Available:    s.Available,
+		NotAvailable: s.NotAvailable,
+		ForSale:      s.ForSale,
+		Reserved:     s.Reserved,
+		TimedOut:     s.TimedOut,
+		Errored:      s.Errored,
+		Prefiltered:  s.Prefiltered,
+		Quotas:       s.Quotas,
+		QuotaSkipped: s.QuotaSkipped,
+		WallTime:     s.WallTime,
+		Latency:      summaryLatency{P50: s.Latency.P50, P95: s.Latency.P95, P99: s.Latency.P99},
+		Retries:      s.Retries,
+		TLDs:         tlds,
+		Sources:      s.Sources,
+		Errors:       s.Errors,
+	}
+}
+
+// runParams are the settings that shaped a run, as reportParams lists them
+// for the reports.
+type runParams struct {
+	Keywords        []string `json:"keywords"`
+	TLDs            []string `json:"tlds"`
+	TLDPreset       string   `json:"tld_preset,omitempty"`
+	Prefixes        []string `json:"prefixes,omitempty"`
+	Suffixes        []string `json:"suffixes,omitempty"`
+	Regex           bool     `json:"regex,omitempty"`
+	OnlyAvailable   bool     `json:"only_available,omitempty"`
+	OnlyForSale     bool     `json:"only_for_sale,omitempty"`
+	MaxPrice        float64  `json:"max_price,omitempty"`
+	Limit           int      `json:"limit,omitempty"`
+	LimitPerTLD     int      `json:"limit_per_tld,omitempty"`
+	LimitPerKeyword int      `json:"limit_per_keyword,omitempty"`
+	Order           string   `json:"order,omitempty"`
+	Backends        []string `json:"backends,omitempty"`
+	Verify          bool     `json:"verify,omitempty"`
+}
+
+func newRunParams(cfg *config.TldxConfigOptions, keywords []string) runParams {
+	return runParams{
+		Keywords:        keywords,
+		TLDs:            cfg.TLDs,
+		TLDPreset:       cfg.TLDPreset,
+		Prefixes:        cfg.Prefixes,
+		Suffixes:        cfg.Suffixes,
+		Regex:           cfg.Regex,
+		OnlyAvailable:   cfg.OnlyAvailable,
+		OnlyForSale:     cfg.OnlyForSale,
+		MaxPrice:        cfg.MaxPrice,
+		Limit:           cfg.Limit,
+		LimitPerTLD:     cfg.LimitPerTLD,
+		LimitPerKeyword: cfg.LimitPerKeyword,
+		Order:           cfg.Order,
+		Backends:        cfg.Backends,
+		Verify:          cfg.Verify,
+	}
+}
+
+func (o *NDJSONSummaryOutput) Write(result resolver.DomainResult) {
+	o.encode(resultRecord{Type: RecordResult, EncodableDomainResult: result.AsEncodable()})
+}
+
+func (o *NDJSONSummaryOutput) Flush() {
+	stats := o.stats
+	if stats == nil {
+		stats = NewStats(0)
+	}
+	warnings := stats.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	keywords := stats.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+	o.encode(summaryRecord{
+		Type:     RecordSummary,
+		Complete: stats.Stopped == "",
+		Stopped:  stats.Stopped,
+		Params:   newRunParams(o.app.Config, keywords),
+		Warnings: warnings,
+		Stats:    newSummaryStats(stats),
+	})
+}
+
+func (o *NDJSONSummaryOutput) encode(record any) {
+	if err := json.NewEncoder(orStdout(o.w)).Encode(record); err != nil {
+		slog.Error("Failed to write NDJSON record", "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package output_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeRecords(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNDJSONSummaryOutput_TagsResultsAndEndsWithASummary(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDs = []string{"com", "io"}
+	app.Config.Limit = 1
+	stats := output.NewStats(2)
+	var buf bytes.Buffer
+	w := output.NewNDJSONSummaryOutput(&buf, app, stats)
+
+	result := availableResult("stripe.com", "stripe", "", "", "com")
+	stats.Add(result)
+	w.Write(result)
+	stats.Keywords = []string{"stripe", "acme"}
+	stats.Warnings = []string{"invalid TLD: nope"}
+	stats.Stopped = output.StoppedLimit
+	stats.Finish()
+	w.Flush()
+
+	records := decodeRecords(t, buf.Bytes())
+	require.Len(t, records, 2)
+	assert.Equal(t, "result", records[0]["type"])
+	assert.Equal(t, "stripe.com", records[0]["domain"])
+	assert.Equal(t, true, records[0]["available"])
+
+	summary := records[1]
+	assert.Equal(t, "summary", summary["type"])
+	assert.Equal(t, false, summary["complete"])
+	assert.Equal(t, "limit", summary["stopped"])
+	assert.Equal(t, []any{"invalid TLD: nope"}, summary["warnings"])
+	assert.Equal(t, map[string]any{
+		"keywords": []any{"stripe", "acme"},
+		"tlds":     []any{"com", "io"},
+		"limit":    float64(1),
+	}, summary["params"], "keywords with no results shown are still listed")
+	require.IsType(t, map[string]any{}, summary["stats"])
+	assert.Equal(t, float64(2), summary["stats"].(map[string]any)["total"])
+	assert.Equal(t, float64(1), summary["stats"].(map[string]any)["available"])
+}
+
+func TestNDJSONSummaryOutput_SummaryKeys(t *testing.T) {
+	var buf bytes.Buffer
+	stats := output.NewStats(1)
+	stats.Add(availableResult("stripe.com", "stripe", "", "", "com"))
+	stats.Finish()
+	w := output.NewNDJSONSummaryOutput(&buf, config.NewTldxContext(), stats)
+	w.Flush()
+
+	records := decodeRecords(t, buf.Bytes())
+	require.Len(t, records, 1)
+	summary := records[0]
+
+	keysOf := func(m map[string]any) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	assert.Equal(t, []string{"complete", "params", "stats", "type", "warnings"}, keysOf(summary))
+
+	statsRecord := summary["stats"].(map[string]any)
+	assert.Equal(t, []string{
+		"available", "errored", "errors", "for_sale", "latency", "not_available", "prefiltered",
+		"quota_skipped", "quotas", "reserved", "retries", "sources", "timed_out", "tlds", "total", "wall_time",
+	}, keysOf(statsRecord))
+	assert.Equal(t, []string{"p50", "p95", "p99"}, keysOf(statsRecord["latency"].(map[string]any)))
+	assert.Equal(t, []string{"available", "checked"},
+		keysOf(statsRecord["tlds"].(map[string]any)["com"].(map[string]any)))
+}
+
+func TestNDJSONSummaryOutput_CompleteRunWithNoResults(t *testing.T) {
+	var buf bytes.Buffer
+	w := output.NewNDJSONSummaryOutput(&buf, config.NewTldxContext(), output.NewStats(0))
+	w.Flush()
+
+	records := decodeRecords(t, buf.Bytes())
+	require.Len(t, records, 1)
+	assert.Equal(t, "summary", records[0]["type"])
+	assert.Equal(t, true, records[0]["complete"])
+	assert.NotContains(t, records[0], "stopped")
+	assert.Equal(t, []any{}, records[0]["warnings"])
+}
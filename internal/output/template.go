@@ -0,0 +1,132 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// FormatTemplate renders each result through the user's --template or
+// --template-file.
+const FormatTemplate = "template"
+
+// Names of the optional templates a user template may define. Both are
+// given the final Stats.
+const (
+	templateHeader = "header"
+	templateFooter = "footer"
+)
+
+// templateFuncs are the helpers available to user templates.
+var templateFuncs = template.FuncMap{
+	// json renders a value as compact JSON, e.g. {{json .ForSale}}.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// join takes the list last so it can end a pipeline:
+	// {{.Tags | join ", "}}.
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	// price renders a registrar quote as "USD 9.68", or nothing without one.
+	"price": func(pricing *registrar.Pricing) string {
+		if pricing == nil {
+			return ""
+		}
+		return pricing.String()
+	},
+}
+
+// ParseTemplate parses --template or --template-file. The template renders
+// one resolver.EncodableDomainResult; it may also define "header" and
+// "footer" templates, rendered around the results with the run's Stats.
+func ParseTemplate(cfg *config.TldxConfigOptions) (*template.Template, error) {
+	text, name := cfg.Template, "template"
+	switch {
+	case cfg.Template != "" && cfg.TemplateFile != "":
+		return nil, errors.New("--template and --template-file cannot be used together")
+	case cfg.TemplateFile != "":
+		data, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		text, name = string(data), cfg.TemplateFile
+	case cfg.Template == "":
+		return nil, errors.New("--format template needs --template or --template-file")
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// TemplateOutput writes each result through a user template, one per line.
+// Results stream as they arrive unless there is a header, which has to wait
+// for the final stats.
+type TemplateOutput struct {
+	w        io.Writer
+	tmpl     *template.Template
+	stats    *Stats
+	buffered bool
+	buf      bytes.Buffer
+}
+
+func NewTemplateOutput(w io.Writer, tmpl *template.Template, stats *Stats) *TemplateOutput {
+	return &TemplateOutput{w: w, tmpl: tmpl, stats: stats, buffered: tmpl.Lookup(templateHeader) != nil}
+}
+
+func (o *TemplateOutput) Write(result resolver.DomainResult) {
+	var line bytes.Buffer
+	if err := o.tmpl.Execute(&line, result.AsEncodable()); err != nil {
+		slog.Error("Failed to render template", "domain", result.Domain, "error", err)
+		return
+	}
+	if !bytes.HasSuffix(line.Bytes(), []byte("\n")) {
+		line.WriteByte('\n')
+	}
+
+	if o.buffered {
+		o.buf.Write(line.Bytes())
+		return
+	}
+	if _, err := o.w.Write(line.Bytes()); err != nil {
+		slog.Error("Failed to write template output", "error", err)
+	}
+}
+
+func (o *TemplateOutput) Flush() {
+	if err := o.flush(); err != nil {
+		slog.Error("Failed to write template output", "error", err)
+	}
+}
+
+func (o *TemplateOutput) flush() error {
+	if o.buffered {
+		if err := o.tmpl.ExecuteTemplate(o.w, templateHeader, o.stats); err != nil {
+			return err
+		}
+		if _, err := o.w.Write(o.buf.Bytes()); err != nil {
+			return err
+		}
+		o.buf.Reset()
+	}
+	if o.tmpl.Lookup(templateFooter) != nil {
+		return o.tmpl.ExecuteTemplate(o.w, templateFooter, o.stats)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package output_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderTemplate(t *testing.T, cfg *config.TldxConfigOptions, results ...resolver.DomainResult) string {
+	t.Helper()
+	tmpl, err := output.ParseTemplate(cfg)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	stats := output.NewStats(len(results))
+	w := output.NewTemplateOutput(&buf, tmpl, stats)
+	for _, result := range results {
+		stats.Add(result)
+		w.Write(result)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func TestTemplateOutput_Inline(t *testing.T) {
+	out := renderTemplate(t, &config.TldxConfigOptions{Template: "{{.Domain}},{{.Available}}"},
+		availableResult("stripe.io", "stripe", "", "", "io"),
+		resolver.DomainResult{Domain: "stripe.com"},
+	)
+	assert.Equal(t, "stripe.io,true\nstripe.com,false\n", out)
+}
+
+func TestTemplateOutput_Helpers(t *testing.T) {
+	result := resolver.DomainResult{
+		Domain:    "stripe.io",
+		Available: true,
+		Pricing:   &registrar.Pricing{Currency: "USD", Registration: 9.68},
+		ForSale:   &forsale.Info{Texts: []string{"make", "an offer"}},
+	}
+	out := renderTemplate(t, &config.TldxConfigOptions{
+		Template: `{{upper .Domain}} {{price .Pricing}} {{.ForSale.Texts | join "+"}} {{json .Pricing}}`,
+	}, result)
+	assert.Equal(t, `STRIPE.IO USD 9.68 make+an offer {"registration":9.68,"currency":"USD","provider":""}`+"\n", out)
+
+	out = renderTemplate(t, &config.TldxConfigOptions{Template: `[{{price .Pricing}}]`}, resolver.DomainResult{Domain: "a.com"})
+	assert.Equal(t, "[]\n", out, "price of nothing is empty")
+}
+
+func TestTemplateOutput_HeaderAndFooterFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(
+		`{{define "header"}}# {{.Available}} of {{.Total}} available
+{{end -}}
+{{define "footer"}}# done
+{{end -}}
+- {{.Domain}}
+`), 0o644))
+
+	out := renderTemplate(t, &config.TldxConfigOptions{TemplateFile: path},
+		availableResult("stripe.io", "stripe", "", "", "io"),
+		resolver.DomainResult{Domain: "stripe.com"},
+	)
+	assert.Equal(t, "# 1 of 2 available\n- stripe.io\n- stripe.com\n# done\n", out)
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	_, err := output.ParseTemplate(&config.TldxConfigOptions{})
+	assert.ErrorContains(t, err, "needs --template or --template-file")
+
+	_, err = output.ParseTemplate(&config.TldxConfigOptions{Template: "x", TemplateFile: "y"})
+	assert.ErrorContains(t, err, "cannot be used together")
+
+	_, err = output.ParseTemplate(&config.TldxConfigOptions{Template: "{{.Domain"})
+	assert.ErrorContains(t, err, "template:")
+
+	_, err = output.ParseTemplate(&config.TldxConfigOptions{TemplateFile: filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTemplateOutput_ExecutionErrorSkipsTheResult(t *testing.T) {
+	var out string
+	logs := captureLogs(func() {
+		out = renderTemplate(t, &config.TldxConfigOptions{Template: "{{.ForSale.Texts}}"}, resolver.DomainResult{Domain: "a.com"})
+	})
+	assert.Empty(t, out)
+	assert.Contains(t, logs, `msg="Failed to render template" domain=a.com`)
+}
@@ -0,0 +1,323 @@
+// Package prefilter keeps a Bloom filter of domains found taken, saved
+// between runs. A sweep that revisits names seen before can skip them, or
+// check them last, instead of asking RDAP again. A Bloom filter never misses
+// a name it was given, but answers "taken" for a small share of names it was
+// never given; those are the false positives the rate setting bounds. Since
+// names cannot be taken back out, and taken names do get dropped, a filter
+// is started afresh once it is older than the configured maximum age.
+package prefilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+const (
+	// ModeSkip reports a known-taken domain as taken without a lookup.
+	ModeSkip = "skip"
+	// ModeDefer looks a known-taken domain up after every other one, so a
+	// --limit is usually met before it is reached. It is the default: a
+	// false positive then costs a late lookup rather than a free domain.
+	ModeDefer = "defer"
+)
+
+// magic starts every saved filter; the digit is the format version.
+// Version 2 added the time the filter was started.
+var (
+	magic   = [8]byte{'t', 'l', 'd', 'x', 'b', 'f', '2', '\n'}
+	magicV1 = [8]byte{'t', 'l', 'd', 'x', 'b', 'f', '1', '\n'}
+)
+
+const (
+	headerSize   = 40
+	headerSizeV1 = 32
+)
+
+// now is the clock filters are started by. For tests.
+var now = time.Now
+
+// Filter is a Bloom filter sized for a number of domains at a false positive
+// rate. It is safe for concurrent use.
+type Filter struct {
+	capacity uint64
+	rate     float64
+	m, k     uint64
+	// created is when the filter was started, to the second. Filters
+	// started at different times are different generations, never merged.
+	created time.Time
+
+	mu    sync.RWMutex
+	bits  []uint64
+	count uint64
+	// loaded is count as read from disk, so Save can tell what this
+	// process added.
+	loaded uint64
+	dirty  bool
+}
+
+// New returns an empty filter for capacity domains at the false positive
+// rate, e.g. 0.01 for one in a hundred.
+func New(capacity int, rate float64) *Filter {
+	n := float64(max(capacity, 1))
+	m := uint64(math.Ceil(-n * math.Log(rate) / (math.Ln2 * math.Ln2)))
+	m = max((m+63)/64*64, 64)
+	k := uint64(max(math.Round(float64(m)/n*math.Ln2), 1))
+	return &Filter{
+		capacity: uint64(max(capacity, 1)),
+		rate:     rate,
+		m:        m,
+		k:        k,
+		bits:     make([]uint64, m/64),
+		created:  now().Truncate(time.Second),
+	}
+}
+
+// DefaultPath is where the filter is saved: $TLDX_PREFILTER, else
+// prefilter.bloom in the user cache directory.
+func DefaultPath() (string, error) {
+	if path := os.Getenv("TLDX_PREFILTER"); path != "" {
+		return path, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("prefilter: cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(cache, "tldx", "prefilter.bloom"), nil
+}
+
+// Validate checks the settings before a run.
+func Validate(opts config.PrefilterOptions) error {
+	switch opts.Mode {
+	case "", ModeSkip, ModeDefer:
+	default:
+		return fmt.Errorf("prefilter: unknown mode %q: use %s or %s", opts.Mode, ModeSkip, ModeDefer)
+	}
+	if opts.Size <= 0 {
+		return fmt.Errorf("prefilter: size must be positive, got %d", opts.Size)
+	}
+	if opts.FalsePositiveRate <= 0 || opts.FalsePositiveRate >= 1 {
+		return fmt.Errorf("prefilter: false positive rate must be between 0 and 1, got %g", opts.FalsePositiveRate)
+	}
+	if opts.MaxAge < 0 {
+		return fmt.Errorf("prefilter: max age must not be negative, got %s", opts.MaxAge)
+	}
+	return nil
+}
+
+// Open loads the filter saved at path. A missing file, one sized for a
+// different capacity or rate, or one started more than maxAge ago gives an
+// empty filter; fresh reports that. A zero maxAge never expires it.
+func Open(path string, capacity int, rate float64, maxAge time.Duration) (f *Filter, fresh bool, err error) {
+	f, err = Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(capacity, rate), true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if f.capacity != uint64(capacity) || f.rate != rate || f.Expired(maxAge) {
+		return New(capacity, rate), true, nil
+	}
+	return f, false, nil
+}
+
+// Expired reports whether the filter was started more than maxAge ago. A
+// zero maxAge never expires.
+func (f *Filter) Expired(maxAge time.Duration) bool {
+	return maxAge > 0 && now().Sub(f.created) > maxAge
+}
+
+// Created is when the filter was started.
+func (f *Filter) Created() time.Time {
+	return f.created
+}
+
+// Reset deletes the filter saved at path, if there is one.
+func Reset(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("prefilter: %w", err)
+	}
+	return nil
+}
+
+// Load reads a filter written by Save. An error matching os.ErrNotExist
+// means there is none yet.
+func Load(path string) (*Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("prefilter: %w", err)
+	}
+	size := headerSize
+	switch {
+	case len(data) >= headerSize && bytes.Equal(data[:8], magic[:]):
+	case len(data) >= headerSizeV1 && bytes.Equal(data[:8], magicV1[:]):
+		size = headerSizeV1
+	default:
+		return nil, fmt.Errorf("prefilter: %s is not a tldx prefilter", path)
+	}
+
+	capacity := binary.LittleEndian.Uint64(data[8:])
+	rate := math.Float64frombits(binary.LittleEndian.Uint64(data[16:]))
+	count := binary.LittleEndian.Uint64(data[24:])
+	if capacity == 0 || capacity > math.MaxInt32 || rate <= 0 || rate >= 1 {
+		return nil, fmt.Errorf("prefilter: %s has a corrupt header", path)
+	}
+
+	f := New(int(capacity), rate)
+	if uint64(len(data)-size) != f.m/8 {
+		return nil, fmt.Errorf("prefilter: %s is truncated", path)
+	}
+	if err := binary.Read(bytes.NewReader(data[size:]), binary.LittleEndian, f.bits); err != nil {
+		return nil, fmt.Errorf("prefilter: %s: %w", path, err)
+	}
+	f.count, f.loaded = count, count
+	if size == headerSize {
+		f.created = time.Unix(int64(binary.LittleEndian.Uint64(data[32:])), 0)
+	} else if fi, err := os.Stat(path); err == nil {
+		// Version 1 kept no start time; the last save is the best guess.
+		f.created = fi.ModTime().Truncate(time.Second)
+	}
+	return f, nil
+}
+
+// Save writes the filter to path, merged with whatever another run saved
+// there since this one loaded it, unless that is a filter of another
+// generation. Nothing is written if nothing was added.
+func (f *Filter) Save(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.dirty {
+		return nil
+	}
+
+	if disk, err := Load(path); err == nil && disk.m == f.m && disk.k == f.k && disk.created.Equal(f.created) {
+		for i, w := range disk.bits {
+			f.bits[i] |= w
+		}
+		if disk.count > f.loaded {
+			f.count += disk.count - f.loaded
+		}
+		f.loaded = disk.count
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(headerSize + len(f.bits)*8)
+	buf.Write(magic[:])
+	binary.Write(&buf, binary.LittleEndian, f.capacity)
+	binary.Write(&buf, binary.LittleEndian, math.Float64bits(f.rate))
+	binary.Write(&buf, binary.LittleEndian, f.count)
+	binary.Write(&buf, binary.LittleEndian, uint64(f.created.Unix()))
+	binary.Write(&buf, binary.LittleEndian, f.bits)
+
+	if err := writeAtomic(path, &buf); err != nil {
+		return err
+	}
+	f.loaded = f.count
+	f.dirty = false
+	return nil
+}
+
+func writeAtomic(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("prefilter: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("prefilter: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("prefilter: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("prefilter: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("prefilter: %w", err)
+	}
+	return nil
+}
+
+// Add records domain as taken. It reports whether the domain was new to the
+// filter, as far as the filter can tell.
+func (f *Filter) Add(domain string) bool {
+	if f == nil {
+		return false
+	}
+	h1, h2 := hashes(domain)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	added := false
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			f.bits[bit/64] |= 1 << (bit % 64)
+			added = true
+		}
+	}
+	if added {
+		f.count++
+		f.dirty = true
+	}
+	return added
+}
+
+// Has reports whether domain was probably added before. A false answer is
+// certain.
+func (f *Filter) Has(domain string) bool {
+	if f == nil {
+		return false
+	}
+	h1, h2 := hashes(domain)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count is the number of domains added, across every run that saved to the
+// same file.
+func (f *Filter) Count() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return int(f.count)
+}
+
+// Capacity is the number of domains the filter was sized for. Past it, the
+// false positive rate climbs above the configured one.
+func (f *Filter) Capacity() int {
+	return int(f.capacity)
+}
+
+// hashes derives the two hashes that the k bit positions are built from.
+// They must not change between releases, or saved filters stop matching.
+func hashes(domain string) (uint64, uint64) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	a := fnv.New64a()
+	a.Write([]byte(domain))
+	b := fnv.New64()
+	b.Write([]byte(domain))
+	// The step must not be zero, or all k positions collapse into one.
+	return a.Sum64(), b.Sum64() | 1
+}
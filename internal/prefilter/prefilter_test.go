@@ -0,0 +1,210 @@
+package prefilter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+func TestFilter_AddHas(t *testing.T) {
+	f := New(1000, 0.01)
+	if f.Has("acme.com") {
+		t.Fatal("an empty filter must not have anything")
+	}
+	if !f.Add("acme.com") {
+		t.Error("the first Add must report a new domain")
+	}
+	if f.Add("ACME.com.") {
+		t.Error("names must be compared case- and dot-insensitively")
+	}
+	if !f.Has("acme.com") || f.Count() != 1 {
+		t.Errorf("expected acme.com with a count of 1, got %d", f.Count())
+	}
+}
+
+func TestFilter_FalsePositiveRate(t *testing.T) {
+	f := New(10000, 0.01)
+	for i := range 10000 {
+		f.Add(fmt.Sprintf("taken%d.com", i))
+	}
+	falsePositives := 0
+	for i := range 10000 {
+		if f.Has(fmt.Sprintf("free%d.com", i)) {
+			falsePositives++
+		}
+	}
+	// 1% expected; 2% leaves room for variance without hiding a bad hash.
+	if falsePositives > 200 {
+		t.Errorf("expected about 100 false positives in 10000, got %d", falsePositives)
+	}
+}
+
+func TestFilter_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "prefilter.bloom")
+
+	f := New(1000, 0.01)
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("an unchanged filter must not be written")
+	}
+
+	f.Add("acme.com")
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, fresh, err := Open(path, 1000, 0.01, 0)
+	if err != nil || fresh {
+		t.Fatalf("expected the saved filter, got fresh=%v, %v", fresh, err)
+	}
+	if !loaded.Has("acme.com") || loaded.Count() != 1 {
+		t.Errorf("the saved domain is missing, count %d", loaded.Count())
+	}
+
+	resized, fresh, err := Open(path, 2000, 0.01, 0)
+	if err != nil || !fresh || resized.Has("acme.com") {
+		t.Errorf("a new size must start an empty filter, got fresh=%v, %v", fresh, err)
+	}
+}
+
+func TestFilter_SaveMergesConcurrentRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefilter.bloom")
+	a, _, _ := Open(path, 1000, 0.01, 0)
+	b, _, _ := Open(path, 1000, 0.01, 0)
+
+	a.Add("first.com")
+	b.Add("second.com")
+	if err := a.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Has("first.com") || !merged.Has("second.com") || merged.Count() != 2 {
+		t.Errorf("expected both runs' domains, count %d", merged.Count())
+	}
+}
+
+func TestLoad_RejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage")
+	os.WriteFile(garbage, []byte("not a filter at all, just some text"), 0o644)
+	if _, err := Load(garbage); err == nil || !strings.Contains(err.Error(), "not a tldx prefilter") {
+		t.Errorf("expected a format error, got %v", err)
+	}
+
+	path := filepath.Join(dir, "prefilter.bloom")
+	f := New(1000, 0.01)
+	f.Add("acme.com")
+	f.Save(path)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, data[:len(data)-8], 0o644)
+	if _, _, err := Open(path, 1000, 0.01, 0); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("expected a truncated file to fail, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := config.NewTldxContext().Config.Prefilter
+	if err := Validate(valid); err != nil {
+		t.Errorf("the defaults must be valid, got %v", err)
+	}
+
+	for _, opts := range []config.PrefilterOptions{
+		{Mode: "sometimes", Size: 10, FalsePositiveRate: 0.01},
+		{Mode: ModeSkip, Size: 0, FalsePositiveRate: 0.01},
+		{Mode: ModeDefer, Size: 10, FalsePositiveRate: 1},
+	} {
+		if err := Validate(opts); err == nil {
+			t.Errorf("expected %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestOpen_StartsAfreshOnceExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefilter.bloom")
+	started := time.Unix(1_700_000_000, 0)
+	now = func() time.Time { return started }
+	defer func() { now = time.Now }()
+
+	f := New(1000, 0.01)
+	f.Add("acme.com")
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	now = func() time.Time { return started.Add(24 * time.Hour) }
+	loaded, fresh, err := Open(path, 1000, 0.01, 48*time.Hour)
+	if err != nil || fresh || !loaded.Has("acme.com") || !loaded.Created().Equal(started) {
+		t.Fatalf("expected the saved filter within its age, got fresh=%v, %v", fresh, err)
+	}
+
+	now = func() time.Time { return started.Add(72 * time.Hour) }
+	renewed, fresh, err := Open(path, 1000, 0.01, 48*time.Hour)
+	if err != nil || !fresh || renewed.Has("acme.com") {
+		t.Fatalf("expected an expired filter to start empty, got fresh=%v, %v", fresh, err)
+	}
+	if _, fresh, _ := Open(path, 1000, 0.01, 0); fresh {
+		t.Error("a zero max age must never expire the filter")
+	}
+
+	renewed.Add("other.com")
+	if err := renewed.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Has("acme.com") || saved.Count() != 1 {
+		t.Errorf("the new generation must not merge the expired one, count %d", saved.Count())
+	}
+}
+
+func TestLoad_ReadsVersion1Files(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefilter.bloom")
+	f := New(1000, 0.01)
+	f.Add("acme.com")
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	v1 := append(append(magicV1[:], data[8:32]...), data[headerSize:]...)
+	os.WriteFile(path, v1, 0o644)
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Has("acme.com") || loaded.Created().IsZero() {
+		t.Errorf("expected the version 1 filter with its save time, created %v", loaded.Created())
+	}
+}
+
+func TestReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefilter.bloom")
+	if err := Reset(path); err != nil {
+		t.Errorf("resetting a missing filter must succeed, got %v", err)
+	}
+	f := New(1000, 0.01)
+	f.Add("acme.com")
+	f.Save(path)
+	if err := Reset(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the filter to be deleted")
+	}
+}
@@ -0,0 +1,94 @@
+package registrar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+const goDaddyEndpoint = "https://api.godaddy.com/v1"
+
+// goDaddyMicros is GoDaddy's price unit: 11990000 means 11.99.
+const goDaddyMicros = 1_000_000
+
+type goDaddy struct {
+	endpoint string
+	key      string
+	secret   string
+	client   *http.Client
+}
+
+func newGoDaddy(opts config.RegistrarOptions, client *http.Client) (Provider, error) {
+	if err := requireCredentials("godaddy", opts.APIKey, opts.APISecret); err != nil {
+		return nil, err
+	}
+	endpoint := goDaddyEndpoint
+	if opts.Endpoint != "" {
+		endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	}
+	return &goDaddy{endpoint: endpoint, key: opts.APIKey, secret: opts.APISecret, client: client}, nil
+}
+
+// goDaddyResponse is the availability answer. RenewalPrice and Premium are
+// left out of some responses; a missing renewal price stays zero, and a
+// missing premium flag is taken as not premium.
+type goDaddyResponse struct {
+	Available    bool   `json:"available"`
+	Currency     string `json:"currency"`
+	Price        int64  `json:"price"`
+	RenewalPrice int64  `json:"renewalPrice"`
+	Period       int    `json:"period"`
+	Premium      bool   `json:"premium"`
+}
+
+// Check uses the FULL check type, the one GoDaddy documents as definitive.
+func (g *goDaddy) Check(ctx context.Context, domain string) (Quote, error) {
+	query := url.Values{"domain": {domain}, "checkType": {"FULL"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		g.endpoint+"/domains/available?"+query.Encode(), nil)
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("Authorization", "sso-key "+g.key+":"+g.secret)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("registrar: godaddy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return Quote{}, statusError("godaddy", resp)
+	}
+
+	var parsed goDaddyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Quote{}, fmt.Errorf("registrar: godaddy: decode response: %w", err)
+	}
+
+	return Quote{
+		Available: parsed.Available,
+		Pricing: Pricing{
+			Registration: parsed.perYear(parsed.Price),
+			Renewal:      parsed.perYear(parsed.RenewalPrice),
+			Currency:     parsed.Currency,
+			Premium:      parsed.Premium,
+			Provider:     "godaddy",
+		},
+	}, nil
+}
+
+// perYear converts a price in micros for the quoted period to one year.
+func (r goDaddyResponse) perYear(micros int64) float64 {
+	price := float64(micros) / goDaddyMicros
+	if r.Period > 1 {
+		price /= float64(r.Period)
+	}
+	return price
+}
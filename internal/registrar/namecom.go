@@ -0,0 +1,93 @@
+package registrar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+const nameComEndpoint = "https://api.name.com/v4"
+
+type nameCom struct {
+	endpoint string
+	username string
+	token    string
+	client   *http.Client
+}
+
+// newNameCom authenticates with the account username and an API token, given
+// as username and api_key.
+func newNameCom(opts config.RegistrarOptions, client *http.Client) (Provider, error) {
+	if opts.Username == "" || opts.APIKey == "" {
+		return nil, errors.New("registrar: namecom needs username and api_key")
+	}
+	endpoint := nameComEndpoint
+	if opts.Endpoint != "" {
+		endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	}
+	return &nameCom{endpoint: endpoint, username: opts.Username, token: opts.APIKey, client: client}, nil
+}
+
+type nameComResponse struct {
+	Results []struct {
+		DomainName    string  `json:"domainName"`
+		Purchasable   bool    `json:"purchasable"`
+		Premium       bool    `json:"premium"`
+		PurchasePrice float64 `json:"purchasePrice"`
+		RenewalPrice  float64 `json:"renewalPrice"`
+	} `json:"results"`
+}
+
+func (n *nameCom) Check(ctx context.Context, domain string) (Quote, error) {
+	body, err := json.Marshal(map[string][]string{"domainNames": {domain}})
+	if err != nil {
+		return Quote{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		n.endpoint+"/domains:checkAvailability", bytes.NewReader(body))
+	if err != nil {
+		return Quote{}, err
+	}
+	req.SetBasicAuth(n.username, n.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("registrar: namecom: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return Quote{}, statusError("namecom", resp)
+	}
+
+	var parsed nameComResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Quote{}, fmt.Errorf("registrar: namecom: decode response: %w", err)
+	}
+
+	for _, r := range parsed.Results {
+		if !strings.EqualFold(r.DomainName, domain) {
+			continue
+		}
+		return Quote{
+			Available: r.Purchasable,
+			Pricing: Pricing{
+				Registration: r.PurchasePrice,
+				Renewal:      r.RenewalPrice,
+				Currency:     "USD",
+				Premium:      r.Premium,
+				Provider:     "namecom",
+			},
+		}, nil
+	}
+
+	return Quote{}, fmt.Errorf("registrar: namecom: no result for %s", domain)
+}
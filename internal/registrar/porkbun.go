@@ -0,0 +1,101 @@
+package registrar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+const porkbunEndpoint = "https://api.porkbun.com/api/json/v3"
+
+type porkbun struct {
+	endpoint string
+	key      string
+	secret   string
+	client   *http.Client
+}
+
+func newPorkbun(opts config.RegistrarOptions, client *http.Client) (Provider, error) {
+	if err := requireCredentials("porkbun", opts.APIKey, opts.APISecret); err != nil {
+		return nil, err
+	}
+	endpoint := porkbunEndpoint
+	if opts.Endpoint != "" {
+		endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	}
+	return &porkbun{endpoint: endpoint, key: opts.APIKey, secret: opts.APISecret, client: client}, nil
+}
+
+type porkbunResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Response struct {
+		Avail        string `json:"avail"`
+		Price        string `json:"price"`
+		RegularPrice string `json:"regularPrice"`
+		Premium      string `json:"premium"`
+		Additional   struct {
+			Renewal struct {
+				Price string `json:"price"`
+			} `json:"renewal"`
+		} `json:"additional"`
+	} `json:"response"`
+}
+
+func (p *porkbun) Check(ctx context.Context, domain string) (Quote, error) {
+	body, err := json.Marshal(map[string]string{"apikey": p.key, "secretapikey": p.secret})
+	if err != nil {
+		return Quote{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		p.endpoint+"/domain/checkDomain/"+url.PathEscape(domain), bytes.NewReader(body))
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("registrar: porkbun: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var parsed porkbunResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return Quote{}, statusError("porkbun", resp)
+		}
+		return Quote{}, fmt.Errorf("registrar: porkbun: decode response: %w", err)
+	}
+	if parsed.Status != "SUCCESS" {
+		return Quote{}, fmt.Errorf("registrar: porkbun: %s", parsed.Message)
+	}
+
+	r := parsed.Response
+	price, err := parseAmount(r.Price)
+	if err != nil {
+		return Quote{}, fmt.Errorf("registrar: porkbun: price %q: %w", r.Price, err)
+	}
+	renewal, err := parseAmount(r.Additional.Renewal.Price)
+	if err != nil {
+		return Quote{}, fmt.Errorf("registrar: porkbun: renewal price %q: %w", r.Additional.Renewal.Price, err)
+	}
+
+	return Quote{
+		Available: r.Avail == "yes",
+		Pricing: Pricing{
+			Registration: price,
+			Renewal:      renewal,
+			Currency:     "USD",
+			Premium:      r.Premium == "yes",
+			Provider:     "porkbun",
+		},
+	}, nil
+}
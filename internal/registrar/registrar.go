@@ -0,0 +1,103 @@
+// Package registrar asks registrar APIs whether a domain can be bought, and at
+// what price. RDAP only says whether a name is registered; a registrar also
+// knows its premium names and what it charges.
+package registrar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// Pricing is one registrar's quote for a domain. Prices are for one year, in
+// Currency.
+type Pricing struct {
+	Registration float64 `json:"registration"`
+	Renewal      float64 `json:"renewal,omitempty"`
+	Currency     string  `json:"currency"`
+	Premium      bool    `json:"premium,omitempty"`
+	Provider     string  `json:"provider"`
+}
+
+func (p Pricing) String() string {
+	return FormatPrice(p.Currency, p.Registration)
+}
+
+// FormatPrice renders an amount the way the for-sale prices are shown,
+// e.g. "USD 9.68".
+func FormatPrice(currency string, amount float64) string {
+	return currency + " " + strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+type Quote struct {
+	Available bool
+	Pricing   Pricing
+}
+
+// Provider is one registrar's availability and pricing API.
+type Provider interface {
+	Check(ctx context.Context, domain string) (Quote, error)
+}
+
+// ProviderFactory builds a provider from its config. client is shared with
+// the rest of the resolver, so transport settings apply here too.
+type ProviderFactory func(opts config.RegistrarOptions, client *http.Client) (Provider, error)
+
+var providers = map[string]ProviderFactory{
+	"porkbun": newPorkbun,
+	"namecom": newNameCom,
+	"godaddy": newGoDaddy,
+}
+
+// ProviderNames lists the supported registrars, sorted.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func New(opts config.RegistrarOptions, client *http.Client) (Provider, error) {
+	if opts.Provider == "" {
+		return nil, errors.New("registrar: no provider configured")
+	}
+	factory, ok := providers[strings.ToLower(opts.Provider)]
+	if !ok {
+		return nil, fmt.Errorf("registrar: unknown provider %q (available: %s)",
+			opts.Provider, strings.Join(ProviderNames(), ", "))
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return factory(opts, client)
+}
+
+func requireCredentials(name string, values ...string) error {
+	for _, v := range values {
+		if v == "" {
+			return fmt.Errorf("registrar: %s needs api_key and api_secret", name)
+		}
+	}
+	return nil
+}
+
+// statusError turns a non-2xx response into an error naming the provider,
+// without echoing a body that may carry account details.
+func statusError(provider string, resp *http.Response) error {
+	return fmt.Errorf("registrar: %s responded %s", provider, resp.Status)
+}
+
+func parseAmount(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,146 @@
+package registrar_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProvider(t *testing.T, opts config.RegistrarOptions, handler http.HandlerFunc) registrar.Provider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts.Endpoint = srv.URL
+	p, err := registrar.New(opts, srv.Client())
+	require.NoError(t, err)
+	return p
+}
+
+func TestPorkbun_Check(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "porkbun", APIKey: "pk", APISecret: "sk"},
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/domain/checkDomain/stripe.io", r.URL.Path)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "pk", body["apikey"])
+			assert.Equal(t, "sk", body["secretapikey"])
+
+			w.Write([]byte(`{"status":"SUCCESS","response":{"avail":"yes","type":"registration",
+				"price":"5000.00","regularPrice":"5000.00","premium":"yes",
+				"additional":{"renewal":{"type":"renewal","price":"45.50"}}}}`))
+		})
+
+	quote, err := p.Check(context.Background(), "stripe.io")
+	require.NoError(t, err)
+	assert.True(t, quote.Available)
+	assert.Equal(t, registrar.Pricing{
+		Registration: 5000, Renewal: 45.5, Currency: "USD", Premium: true, Provider: "porkbun",
+	}, quote.Pricing)
+}
+
+func TestPorkbun_ErrorStatus(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "porkbun", APIKey: "pk", APISecret: "sk"},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"ERROR","message":"Invalid API key."}`))
+		})
+
+	_, err := p.Check(context.Background(), "stripe.io")
+	assert.ErrorContains(t, err, "Invalid API key")
+}
+
+func TestNameCom_Check(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "namecom", Username: "acme", APIKey: "token"},
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/domains:checkAvailability", r.URL.Path)
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "acme", user)
+			assert.Equal(t, "token", pass)
+
+			w.Write([]byte(`{"results":[{"domainName":"stripe.io","purchasable":true,"premium":false,
+				"purchasePrice":39.99,"purchaseType":"registration","renewalPrice":59.99}]}`))
+		})
+
+	quote, err := p.Check(context.Background(), "stripe.io")
+	require.NoError(t, err)
+	assert.True(t, quote.Available)
+	assert.Equal(t, 39.99, quote.Pricing.Registration)
+	assert.Equal(t, 59.99, quote.Pricing.Renewal)
+	assert.False(t, quote.Pricing.Premium)
+}
+
+func TestNameCom_Unauthorized(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "namecom", Username: "acme", APIKey: "token"},
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Unauthorized","details":"account acme"}`, http.StatusUnauthorized)
+		})
+
+	_, err := p.Check(context.Background(), "stripe.io")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.NotContains(t, err.Error(), "account acme", "response bodies must not leak into errors")
+}
+
+func TestGoDaddy_Check(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "godaddy", APIKey: "key", APISecret: "secret"},
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/domains/available", r.URL.Path)
+			assert.Equal(t, "stripe.io", r.URL.Query().Get("domain"))
+			assert.Equal(t, "sso-key key:secret", r.Header.Get("Authorization"))
+
+			w.Write([]byte(`{"available":false,"currency":"EUR","definitive":true,"domain":"stripe.io","period":1,"price":11990000}`))
+		})
+
+	quote, err := p.Check(context.Background(), "stripe.io")
+	require.NoError(t, err)
+	assert.False(t, quote.Available)
+	assert.Equal(t, 11.99, quote.Pricing.Registration)
+	assert.Equal(t, "EUR", quote.Pricing.Currency)
+	assert.Zero(t, quote.Pricing.Renewal, "no renewal price in the response")
+	assert.False(t, quote.Pricing.Premium)
+}
+
+func TestGoDaddy_CheckPremium(t *testing.T) {
+	p := newProvider(t, config.RegistrarOptions{Provider: "godaddy", APIKey: "key", APISecret: "secret"},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"available":true,"currency":"USD","definitive":true,"domain":"stripe.ai","period":2,
+				"price":10000000000,"renewalPrice":160000000,"premium":true}`))
+		})
+
+	quote, err := p.Check(context.Background(), "stripe.ai")
+	require.NoError(t, err)
+	assert.Equal(t, registrar.Pricing{
+		Registration: 5000, Renewal: 80, Currency: "USD", Premium: true, Provider: "godaddy",
+	}, quote.Pricing, "prices are per year")
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := registrar.New(config.RegistrarOptions{}, nil)
+	assert.ErrorContains(t, err, "no provider")
+
+	_, err = registrar.New(config.RegistrarOptions{Provider: "carrier-pigeon"}, nil)
+	assert.ErrorContains(t, err, "carrier-pigeon")
+
+	_, err = registrar.New(config.RegistrarOptions{Provider: "porkbun", APIKey: "pk"}, nil)
+	assert.ErrorContains(t, err, "api_secret")
+
+	_, err = registrar.New(config.RegistrarOptions{Provider: "NameCom", Username: "acme", APIKey: "token"}, nil)
+	assert.NoError(t, err, "provider names are case-insensitive")
+}
+
+func TestPricing_String(t *testing.T) {
+	assert.Equal(t, "USD 9.68", registrar.Pricing{Registration: 9.68, Currency: "USD"}.String())
+	assert.True(t, strings.HasPrefix(registrar.FormatPrice("EUR", 5000), "EUR 5000.00"))
+}
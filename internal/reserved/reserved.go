@@ -0,0 +1,207 @@
+// Package reserved knows second-level names that RDAP reports as free but a
+// registry will not sell: names held back under the ICANN Registry Agreement,
+// name collision block lists, country names. The lists are plain text, built
+// in or supplied by the user, and only consulted once a lookup came back
+// negative.
+package reserved
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+//go:embed lists/*.txt
+var builtin embed.FS
+
+// Match says why a name is reserved and which list said so.
+type Match struct {
+	List   string `json:"list"`
+	Reason string `json:"reason"`
+}
+
+func (m Match) String() string {
+	return fmt.Sprintf("%s (%s)", m.Reason, m.List)
+}
+
+// Lists is a set of parsed lists. The zero value matches nothing.
+type Lists struct {
+	rules []rule
+}
+
+type rule struct {
+	list    string
+	reason  string
+	scope   scope
+	pattern string
+}
+
+// legacyGTLDs predate the 2012 round and its reservation rules.
+var legacyGTLDs = []string{
+	"aero", "asia", "biz", "cat", "com", "coop", "edu", "gov", "info", "int", "jobs",
+	"mil", "mobi", "museum", "name", "net", "org", "post", "pro", "tel", "travel", "xxx",
+}
+
+// scope limits a rule to some zones: "*", "gtld", "new-gtld", "cctld", or a
+// comma-separated list of zones such as "de,co.uk".
+type scope string
+
+func (s scope) covers(zone string) bool {
+	switch s {
+	case "", "*":
+		return true
+	case "gtld":
+		return isGTLD(zone)
+	case "new-gtld":
+		return isGTLD(zone) && !slices.Contains(legacyGTLDs, zone)
+	case "cctld":
+		last := zone[strings.LastIndex(zone, ".")+1:]
+		return len(last) == 2
+	}
+	return slices.Contains(strings.Split(string(s), ","), zone)
+}
+
+func isGTLD(zone string) bool {
+	return !strings.Contains(zone, ".") && len(zone) > 2
+}
+
+// Load returns the built-in lists, unless opts.SkipBuiltin is set, followed by
+// opts.Files.
+func Load(opts config.ReservedOptions) (*Lists, error) {
+	l := &Lists{}
+	if !opts.SkipBuiltin {
+		b, err := Builtin()
+		if err != nil {
+			return nil, err
+		}
+		l.rules = append(l.rules, b.rules...)
+	}
+	for _, file := range opts.Files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("reserved: %w", err)
+		}
+		err = l.add(listName(file), f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Builtin returns the lists shipped with tldx.
+func Builtin() (*Lists, error) {
+	l := &Lists{}
+	entries, err := fs.ReadDir(builtin, "lists")
+	if err != nil {
+		return nil, fmt.Errorf("reserved: %w", err)
+	}
+	for _, entry := range entries {
+		f, err := builtin.Open("lists/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("reserved: %w", err)
+		}
+		err = l.add(listName(entry.Name()), f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func listName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// add parses one list. Each line is a glob, optionally followed by a reason
+// for that entry alone. A glob without a dot matches the second-level label
+// in the zones of the current @tlds scope; one with a dot matches the whole
+// domain. "@reason <text>" and "@tlds <scope>" apply to the lines after them,
+// and "#" starts a comment.
+func (l *Lists) add(name string, r io.Reader) error {
+	reason := "listed in " + name
+	var sc scope
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch key {
+		case "@reason":
+			if rest == "" {
+				return fmt.Errorf("reserved: %s:%d: @reason needs a text", name, n)
+			}
+			reason = rest
+			continue
+		case "@tlds":
+			sc = scope(strings.ToLower(strings.ReplaceAll(rest, " ", "")))
+			if sc == "" {
+				return fmt.Errorf("reserved: %s:%d: @tlds needs a scope", name, n)
+			}
+			continue
+		}
+		if strings.HasPrefix(key, "@") {
+			return fmt.Errorf("reserved: %s:%d: unknown directive %s", name, n, key)
+		}
+
+		pattern := strings.ToLower(strings.TrimSuffix(key, "."))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("reserved: %s:%d: bad pattern %q", name, n, key)
+		}
+		entryReason := reason
+		if rest != "" {
+			entryReason = rest
+		}
+		l.rules = append(l.rules, rule{list: name, reason: entryReason, scope: sc, pattern: pattern})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reserved: read %s: %w", name, err)
+	}
+	return nil
+}
+
+// Check reports the first list that reserves domain.
+func (l *Lists) Check(domain string) (Match, bool) {
+	if l == nil {
+		return Match{}, false
+	}
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	label, zone, ok := strings.Cut(domain, ".")
+	if !ok {
+		return Match{}, false
+	}
+
+	for _, r := range l.rules {
+		var matched bool
+		if strings.Contains(r.pattern, ".") {
+			matched, _ = path.Match(r.pattern, domain)
+		} else if r.scope.covers(zone) {
+			matched, _ = path.Match(r.pattern, label)
+		}
+		if matched {
+			return Match{List: r.list, Reason: r.reason}, true
+		}
+	}
+	return Match{}, false
+}
@@ -0,0 +1,127 @@
+package reserved_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeList(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestBuiltin(t *testing.T) {
+	lists, err := reserved.Builtin()
+	require.NoError(t, err)
+
+	tests := []struct {
+		domain   string
+		wantList string
+	}{
+		{"nic.xyz", "icann"},
+		{"example.shop", "icann"},
+		{"wpad.app", "name-collision"},
+		{"france.store", "country-names"},
+		{"united-kingdom.shop", "country-names"},
+		{"unitedkingdom.shop", "country-names"},
+		// Legacy gTLDs and ccTLDs are not covered.
+		{"nic.com", ""},
+		{"france.de", ""},
+		{"stripe.xyz", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			match, ok := lists.Check(tt.domain)
+			if tt.wantList == "" {
+				assert.False(t, ok, "unexpected match %+v", match)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantList, match.List)
+			assert.NotEmpty(t, match.Reason)
+		})
+	}
+}
+
+func TestLoad_UserList(t *testing.T) {
+	path := writeList(t, "house.txt", `
+# Names the registry told us about.
+@reason premium hold
+@tlds de, co.uk
+??
+acme            held for a trademark claim
+@tlds *
+shop.example.io # exact domain, whatever the scope
+`)
+
+	lists, err := reserved.Load(config.ReservedOptions{SkipBuiltin: true, Files: []string{path}})
+	require.NoError(t, err)
+
+	match, ok := lists.Check("ab.de")
+	require.True(t, ok)
+	assert.Equal(t, reserved.Match{List: "house", Reason: "premium hold"}, match)
+
+	match, ok = lists.Check("ACME.co.uk.")
+	require.True(t, ok)
+	assert.Equal(t, "held for a trademark claim", match.Reason)
+
+	_, ok = lists.Check("shop.example.io")
+	assert.True(t, ok)
+
+	for _, domain := range []string{"abc.de", "ab.com", "acme.uk", "nic.xyz"} {
+		_, ok := lists.Check(domain)
+		assert.False(t, ok, domain)
+	}
+}
+
+func TestLoad_BuiltinComesFirst(t *testing.T) {
+	path := writeList(t, "mine.txt", "nic\n")
+
+	lists, err := reserved.Load(config.ReservedOptions{Files: []string{path}})
+	require.NoError(t, err)
+
+	match, ok := lists.Check("nic.xyz")
+	require.True(t, ok)
+	assert.Equal(t, "icann", match.List)
+
+	match, ok = lists.Check("nic.com")
+	require.True(t, ok)
+	assert.Equal(t, reserved.Match{List: "mine", Reason: "listed in mine"}, match)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown directive", "@zone de\n", "mine:1: unknown directive @zone"},
+		{"empty reason", "\n@reason\n", "mine:2: @reason needs a text"},
+		{"empty scope", "@tlds\n", "@tlds needs a scope"},
+		{"bad glob", "acme[\n", `bad pattern "acme["`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeList(t, "mine.txt", tt.content)
+			_, err := reserved.Load(config.ReservedOptions{SkipBuiltin: true, Files: []string{path}})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	_, err := reserved.Load(config.ReservedOptions{Files: []string{filepath.Join(t.TempDir(), "missing.txt")}})
+	assert.Error(t, err)
+}
+
+func TestCheck_NilLists(t *testing.T) {
+	var lists *reserved.Lists
+	_, ok := lists.Check("nic.xyz")
+	assert.False(t, ok)
+}
@@ -0,0 +1,344 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brandonyoungdev/tldx/internal/zone"
+)
+
+// DefaultBackendChain is tried, in order, for any TLD without its own chain.
+var DefaultBackendChain = []string{SourceRDAP, SourceDNS, SourceWHOIS}
+
+// ErrNoVerdict matches any error from NoVerdict.
+var ErrNoVerdict = errors.New("no verdict")
+
+// NoVerdictError reports that a backend could not answer for a domain, such
+// as RDAP for a TLD with no RDAP server. The chain moves on to the next
+// backend, and Reason ends up in the details of a fallback verdict. Err is
+// the lookup failure behind it, if any: a chain that ends with one is
+// reported as an error, not as likely available.
+type NoVerdictError struct {
+	Reason string
+	Err    error
+}
+
+func (e *NoVerdictError) Error() string { return e.Reason }
+
+func (e *NoVerdictError) Unwrap() error { return e.Err }
+
+func (e *NoVerdictError) Is(target error) bool { return target == ErrNoVerdict }
+
+func NoVerdict(reason string) error {
+	return &NoVerdictError{Reason: reason}
+}
+
+// BackendFactory builds a backend bound to one ResolverService, so it can use
+// the service's config and injected lookups.
+type BackendFactory func(s *ResolverService) Resolver
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]BackendFactory{}
+)
+
+// RegisterBackend makes a backend available by name to --backend and the
+// [backends] config section. Registering a name twice replaces it.
+func RegisterBackend(name string, factory BackendFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// BackendNames lists the registered backends, sorted.
+func BackendNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return sortedBackendNames()
+}
+
+// sortedBackendNames expects registryMu to be held.
+func sortedBackendNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateBackends rejects an empty chain or one naming an unknown backend.
+func ValidateBackends(chain []string) error {
+	if len(chain) == 0 {
+		return errors.New("backend chain must not be empty")
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, name := range chain {
+		if _, ok := registry[name]; !ok {
+			return fmt.Errorf("unknown backend %q (available: %s)", name, strings.Join(sortedBackendNames(), ", "))
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterBackend(SourceRDAP, func(s *ResolverService) Resolver { return rdapBackend{s} })
+	RegisterBackend(SourceDNS, func(s *ResolverService) Resolver { return dnsBackend{s} })
+	RegisterBackend(SourceWHOIS, func(s *ResolverService) Resolver { return whoisBackend{s} })
+	RegisterBackend(SourceDelegation, func(s *ResolverService) Resolver { return delegationBackend{s} })
+	RegisterBackend(SourceRegistrar, func(s *ResolverService) Resolver { return registrarBackend{s} })
+	RegisterBackend(SourceEPP, func(s *ResolverService) Resolver { return eppBackend{s} })
+	RegisterBackend(SourceZone, func(s *ResolverService) Resolver { return zoneBackend{s} })
+}
+
+// backend returns the named backend for this service, building it on first
+// use. Backends injected with WithBackend take precedence over the registry.
+func (s *ResolverService) backend(name string) (Resolver, error) {
+	s.backendsMu.Lock()
+	defer s.backendsMu.Unlock()
+
+	if b, ok := s.backends[name]; ok {
+		return b, nil
+	}
+	if s.backends == nil {
+		s.backends = make(map[string]Resolver)
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", name)
+	}
+
+	b := factory(s)
+	s.backends[name] = b
+	return b, nil
+}
+
+// chainFor picks the backends for a domain: the most specific per-TLD chain,
+// else the configured default, else DefaultBackendChain.
+func (s *ResolverService) chainFor(domain string) []string {
+	cfg := s.app.Config
+	chain := DefaultBackendChain
+	if len(cfg.Backends) > 0 {
+		chain = cfg.Backends
+	}
+
+	for zone, ok := parentZone(domain); ok; zone, ok = parentZone(zone) {
+		if tldChain, found := cfg.TLDBackends[zone]; found && len(tldChain) > 0 {
+			chain = tldChain
+			break
+		}
+	}
+
+	if cfg.DNSPrescreen && !slices.Contains(chain, SourceDelegation) {
+		chain = append([]string{SourceDelegation}, chain...)
+	}
+	return chain
+}
+
+type rdapBackend struct{ s *ResolverService }
+
+func (b rdapBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	result, err := b.s.withRetry(ctx, domain, func() (CheckResult, error) {
+		return b.s.checkRDAP(ctx, domain)
+	})
+	if err != nil && strings.Contains(err.Error(), "No RDAP servers") {
+		return CheckResult{}, NoVerdict("No RDAP server available")
+	}
+	return result, err
+}
+
+type dnsBackend struct{ s *ResolverService }
+
+func (b dnsBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	resolved, err := b.s.checkIfDNSResolves(ctx, domain)
+	if errors.Is(err, errDNSHijacked) {
+		return CheckResult{}, NoVerdict("DNS resolver rewrites NXDOMAIN")
+	}
+	if resolved {
+		return CheckResult{
+			Registered: true,
+			Details:    fmt.Sprintf("Domain %s has a DNS record, but RDAP is not available", domain),
+			Source:     SourceDNS,
+		}, nil
+	}
+	return CheckResult{}, NoVerdict("DNS did not resolve")
+}
+
+type whoisBackend struct{ s *ResolverService }
+
+// Check only trusts a positive answer. A "not found" body is too easy to get
+// wrong across registries to settle availability on its own.
+func (b whoisBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	result, err := b.s.checkWhois(ctx, domain)
+	if err == nil && result.Registered {
+		return result, nil
+	}
+	return CheckResult{}, NoVerdict("No WHOIS")
+}
+
+type delegationBackend struct{ s *ResolverService }
+
+// Check settles a delegated name as taken. An undelegated name may still be
+// registered, so that is left to the rest of the chain, as is a name whose
+// delegation could not be looked up.
+func (b delegationBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	delegated, err := b.s.checkDelegation(ctx, domain)
+	if err != nil {
+		return CheckResult{}, &NoVerdictError{Reason: "Delegation lookup failed: " + err.Error(), Err: err}
+	}
+	if delegated {
+		return CheckResult{
+			Registered: true,
+			Details:    fmt.Sprintf("Domain %s is delegated by its parent zone", domain),
+			Source:     SourceDelegation,
+		}, nil
+	}
+	return CheckResult{}, NoVerdict("Not delegated in the parent zone")
+}
+
+type registrarBackend struct{ s *ResolverService }
+
+// Check takes the registrar's word either way, and keeps its quote so an
+// available domain carries a price without a second request.
+func (b registrarBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	if b.s.registrar == nil {
+		return CheckResult{}, NoVerdict("No registrar configured")
+	}
+
+	quote, err := b.s.registrar.Check(ctx, domain)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	if !quote.Available {
+		return CheckResult{
+			Registered: true,
+			Details:    fmt.Sprintf("Registrar %s cannot register %s", quote.Pricing.Provider, domain),
+			Source:     SourceRegistrar,
+		}, nil
+	}
+
+	pricing := quote.Pricing
+	return CheckResult{
+		Registered: false,
+		Details:    fmt.Sprintf("Registrar %s can register %s", pricing.Provider, domain),
+		Source:     SourceRegistrar,
+		Pricing:    &pricing,
+	}, nil
+}
+
+type eppBackend struct{ s *ResolverService }
+
+// Check takes the registry's answer either way. An unavailable name carries
+// the registry's reason, which tells "In use" apart from "Reserved".
+func (b eppBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	if b.s.epp == nil {
+		return CheckResult{}, NoVerdict("No EPP server configured")
+	}
+
+	result, err := b.s.epp.Check(ctx, domain)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	if result.Available {
+		return CheckResult{
+			Registered: false,
+			Details:    fmt.Sprintf("Registry reports %s as available", domain),
+			Source:     SourceEPP,
+		}, nil
+	}
+
+	details := fmt.Sprintf("Registry reports %s as unavailable", domain)
+	if result.Reason != "" {
+		details += ": " + result.Reason
+	}
+	return CheckResult{Registered: true, Details: details, Source: SourceEPP}, nil
+}
+
+type zoneBackend struct{ s *ResolverService }
+
+// Check answers from an imported zone file. A name missing from the zone may
+// still be registered without nameservers, so with ConfirmNegatives RDAP
+// gets the last word on it.
+func (b zoneBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	store := b.s.zoneStore(domain)
+	if store == nil {
+		return CheckResult{}, NoVerdict("No zone file imported")
+	}
+
+	label := strings.TrimSuffix(domain, "."+store.Zone)
+	label = label[strings.LastIndex(label, ".")+1:]
+
+	delegated, err := store.Delegated(label)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if delegated {
+		return CheckResult{
+			Registered: true,
+			Details:    fmt.Sprintf("Delegated in the .%s zone file of %s", store.Zone, store.SnapshotDate()),
+			Source:     SourceZone,
+		}, nil
+	}
+
+	details := fmt.Sprintf("Not delegated in the .%s zone file of %s", store.Zone, store.SnapshotDate())
+	if b.s.app.Config.Zone.ConfirmNegatives {
+		result, err := rdapBackend{b.s}.Check(ctx, domain)
+		if err == nil {
+			result.Details = fmt.Sprintf("%s; %s", details, result.Details)
+			return result, nil
+		}
+		if !errors.Is(err, ErrNoVerdict) {
+			return CheckResult{}, err
+		}
+	}
+	return CheckResult{Registered: false, Details: details, Source: SourceZone}, nil
+}
+
+// zoneStore returns the imported zone covering domain, most specific first,
+// or nil. Stores are opened on first use and kept open until Close.
+func (s *ResolverService) zoneStore(domain string) *zone.Store {
+	s.zonesMu.Lock()
+	defer s.zonesMu.Unlock()
+	if s.zones == nil {
+		s.zones = make(map[string]*zone.Store)
+	}
+
+	dir := s.app.Config.Zone.Dir
+	if dir == "" {
+		var err error
+		if dir, err = zone.DefaultDir(); err != nil {
+			return nil
+		}
+	}
+
+	for name, ok := parentZone(domain); ok; name, ok = parentZone(name) {
+		store, loaded := s.zones[name]
+		if !loaded {
+			var err error
+			store, err = zone.Open(dir, name)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("Could not load the imported zone", "zone", name, "error", err)
+			}
+			s.zones[name] = store
+		}
+		if store != nil {
+			return store
+		}
+	}
+	return nil
+}
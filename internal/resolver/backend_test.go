@@ -0,0 +1,482 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/epp"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/brandonyoungdev/tldx/internal/zone"
+)
+
+// fakeBackend records its calls and answers with a fixed result.
+type fakeBackend struct {
+	result resolver.CheckResult
+	err    error
+	calls  []string
+}
+
+func (f *fakeBackend) Check(_ context.Context, domain string) (resolver.CheckResult, error) {
+	f.calls = append(f.calls, domain)
+	return f.result, f.err
+}
+
+func taken() *fakeBackend {
+	return &fakeBackend{result: resolver.CheckResult{Registered: true, Details: "taken"}}
+}
+
+func silent(reason string) *fakeBackend {
+	return &fakeBackend{err: resolver.NoVerdict(reason)}
+}
+
+func TestCheckDomain_BackendChainOrder(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"whois", "rdap"}
+
+	rdapBackend := taken()
+	whoisBackend := silent("No WHOIS")
+
+	s := resolver.NewResolverService(app,
+		resolver.WithBackend("rdap", rdapBackend),
+		resolver.WithBackend("whois", whoisBackend),
+	)
+
+	result, err := s.CheckDomain(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Registered {
+		t.Error("Expected the rdap verdict after whois passed")
+	}
+	if result.Source != "rdap" {
+		t.Errorf("Expected the source to default to the backend name, got %q", result.Source)
+	}
+	if len(whoisBackend.calls) != 1 || len(rdapBackend.calls) != 1 {
+		t.Errorf("Expected one call each, got whois=%d rdap=%d", len(whoisBackend.calls), len(rdapBackend.calls))
+	}
+}
+
+func TestCheckDomain_PerTLDChain(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDBackends = map[string][]string{
+		"de":    {"whois"},
+		"co.uk": {"dns"},
+	}
+
+	rdapBackend := taken()
+	whoisBackend := taken()
+	dnsBackend := taken()
+
+	s := resolver.NewResolverService(app,
+		resolver.WithBackend("rdap", rdapBackend),
+		resolver.WithBackend("whois", whoisBackend),
+		resolver.WithBackend("dns", dnsBackend),
+	)
+
+	for _, domain := range []string{"example.de", "example.co.uk", "example.com"} {
+		if _, err := s.CheckDomain(context.Background(), domain); err != nil {
+			t.Fatalf("%s: unexpected error: %v", domain, err)
+		}
+	}
+
+	if strings.Join(whoisBackend.calls, ",") != "example.de" {
+		t.Errorf("Expected whois for .de only, got %v", whoisBackend.calls)
+	}
+	if strings.Join(dnsBackend.calls, ",") != "example.co.uk" {
+		t.Errorf("Expected dns for .co.uk only, got %v", dnsBackend.calls)
+	}
+	if strings.Join(rdapBackend.calls, ",") != "example.com" {
+		t.Errorf("Expected the default chain for .com, got %v", rdapBackend.calls)
+	}
+}
+
+func TestCheckDomain_NoVerdictFromEveryBackend(t *testing.T) {
+	app := config.NewTldxContext()
+
+	s := resolver.NewResolverService(app,
+		resolver.WithBackend("rdap", silent("No RDAP server available")),
+		resolver.WithBackend("dns", silent("DNS did not resolve")),
+		resolver.WithBackend("whois", silent("No WHOIS")),
+	)
+
+	result, err := s.CheckDomain(context.Background(), "example.xyz")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Registered {
+		t.Error("Expected the fallback verdict to be available")
+	}
+	if result.Source != resolver.SourceFallback {
+		t.Errorf("Expected source %q, got %q", resolver.SourceFallback, result.Source)
+	}
+	want := "No RDAP server available; DNS did not resolve; No WHOIS (likely available)"
+	if result.Details != want {
+		t.Errorf("Expected details %q, got %q", want, result.Details)
+	}
+}
+
+func TestCheckDomain_BackendFailureStopsTheChain(t *testing.T) {
+	app := config.NewTldxContext()
+
+	dnsBackend := taken()
+	s := resolver.NewResolverService(app,
+		resolver.WithBackend("rdap", &fakeBackend{err: errors.New("429 too many requests")}),
+		resolver.WithBackend("dns", dnsBackend),
+	)
+
+	result, err := s.CheckDomain(context.Background(), "example.com")
+	if err == nil || !strings.Contains(err.Error(), "rdap lookup failed") {
+		t.Errorf("Expected the rdap failure to be reported, got %v", err)
+	}
+	if result.Registered {
+		t.Error("A failed lookup must not be reported as registered")
+	}
+	if len(dnsBackend.calls) != 0 {
+		t.Error("A hard failure must not fall through to the next backend")
+	}
+}
+
+func TestCheckDomain_UnknownBackend(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"carrier-pigeon"}
+
+	s := resolver.NewResolverService(app)
+	if _, err := s.CheckDomain(context.Background(), "example.com"); err == nil {
+		t.Error("Expected an unknown backend to fail the lookup")
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	resolver.RegisterBackend("test-static", func(*resolver.ResolverService) resolver.Resolver {
+		return taken()
+	})
+
+	if err := resolver.ValidateBackends([]string{"rdap", "test-static"}); err != nil {
+		t.Fatalf("Expected a registered backend to validate, got %v", err)
+	}
+
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"test-static"}
+
+	result, err := resolver.NewResolverService(app).CheckDomain(context.Background(), "example.com")
+	if err != nil || !result.Registered || result.Source != "test-static" {
+		t.Errorf("Expected the registered backend to answer, got %+v, %v", result, err)
+	}
+}
+
+func TestValidateBackends(t *testing.T) {
+	if err := resolver.ValidateBackends(resolver.DefaultBackendChain); err != nil {
+		t.Errorf("Expected the default chain to validate, got %v", err)
+	}
+	if err := resolver.ValidateBackends(nil); err == nil {
+		t.Error("Expected an empty chain to be rejected")
+	}
+
+	err := resolver.ValidateBackends([]string{"rdap", "carrier-pigeon"})
+	if err == nil || !strings.Contains(err.Error(), "carrier-pigeon") {
+		t.Errorf("Expected the unknown name in the error, got %v", err)
+	}
+}
+
+func TestResolverService_ImplementsResolver(t *testing.T) {
+	var _ resolver.Resolver = resolver.NewResolverService(config.NewTldxContext())
+}
+
+// staticRegistrar returns one quote for every domain.
+type staticRegistrar struct {
+	quote registrar.Quote
+	err   error
+	calls int
+}
+
+func (r *staticRegistrar) Check(_ context.Context, _ string) (registrar.Quote, error) {
+	r.calls++
+	return r.quote, r.err
+}
+
+func TestRegistrarBackend(t *testing.T) {
+	pricing := registrar.Pricing{Registration: 5000, Renewal: 45, Currency: "USD", Premium: true, Provider: "test"}
+
+	t.Run("an available quote is a verdict with a price", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Backends = []string{resolver.SourceRegistrar}
+
+		s := resolver.NewResolverService(app, resolver.WithRegistrar(&staticRegistrar{
+			quote: registrar.Quote{Available: true, Pricing: pricing},
+		}))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Registered || result.Pricing == nil || !result.Pricing.Premium {
+			t.Errorf("Expected an available premium domain, got %+v", result)
+		}
+	})
+
+	t.Run("an unavailable quote means taken", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Backends = []string{resolver.SourceRegistrar}
+
+		s := resolver.NewResolverService(app, resolver.WithRegistrar(&staticRegistrar{
+			quote: registrar.Quote{Available: false, Pricing: pricing},
+		}))
+
+		result, _ := s.CheckDomain(context.Background(), "example.com")
+		if !result.Registered || result.Pricing != nil {
+			t.Errorf("Expected a taken domain without a price, got %+v", result)
+		}
+	})
+
+	t.Run("no registrar configured passes to the next backend", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Backends = []string{resolver.SourceRegistrar, "rdap"}
+
+		s := resolver.NewResolverService(app, resolver.WithBackend("rdap", taken()))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil || result.Source != "rdap" {
+			t.Errorf("Expected the rdap verdict, got %+v, %v", result, err)
+		}
+	})
+}
+
+func TestCheckDomain_PricingEnrichesAvailableDomains(t *testing.T) {
+	pricing := registrar.Pricing{Registration: 9.68, Currency: "USD", Provider: "test"}
+
+	t.Run("available domains get a price", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.CheckPricing = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithBackend("rdap", &fakeBackend{result: resolver.CheckResult{Details: "404"}}),
+			resolver.WithRegistrar(&staticRegistrar{quote: registrar.Quote{Available: true, Pricing: pricing}}),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil || result.Pricing == nil || result.Pricing.Registration != 9.68 {
+			t.Errorf("Expected a USD 9.68 quote, got %+v, %v", result.Pricing, err)
+		}
+	})
+
+	t.Run("taken domains are not quoted", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.CheckPricing = true
+
+		quotes := &staticRegistrar{quote: registrar.Quote{Available: true, Pricing: pricing}}
+		s := resolver.NewResolverService(app,
+			resolver.WithBackend("rdap", taken()),
+			resolver.WithRegistrar(quotes),
+		)
+
+		s.CheckDomain(context.Background(), "example.com")
+		if quotes.calls != 0 {
+			t.Error("Expected no registrar call for a taken domain")
+		}
+	})
+
+	t.Run("a failed quote leaves the verdict intact", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.CheckPricing = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithBackend("rdap", &fakeBackend{result: resolver.CheckResult{Details: "404"}}),
+			resolver.WithRegistrar(&staticRegistrar{err: errors.New("401 Unauthorized")}),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil || result.Registered || result.Pricing != nil {
+			t.Errorf("Expected an unpriced available domain, got %+v, %v", result, err)
+		}
+	})
+}
+
+// staticEPP answers every <check> with one result.
+type staticEPP struct {
+	result epp.Result
+	err    error
+}
+
+func (e staticEPP) Check(_ context.Context, domain string) (epp.Result, error) {
+	r := e.result
+	r.Name = domain
+	return r, e.err
+}
+
+func TestEPPBackend(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{resolver.SourceEPP}
+
+	t.Run("unavailable carries the registry's reason", func(t *testing.T) {
+		s := resolver.NewResolverService(app, resolver.WithEPP(staticEPP{result: epp.Result{Reason: "Reserved"}}))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered || !strings.HasSuffix(result.Details, ": Reserved") || result.Source != resolver.SourceEPP {
+			t.Errorf("Expected a reserved verdict from epp, got %+v", result)
+		}
+	})
+
+	t.Run("available", func(t *testing.T) {
+		s := resolver.NewResolverService(app, resolver.WithEPP(staticEPP{result: epp.Result{Available: true}}))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil || result.Registered {
+			t.Errorf("Expected an available verdict, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("no server configured passes to the next backend", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Backends = []string{resolver.SourceEPP, "rdap"}
+
+		s := resolver.NewResolverService(app, resolver.WithBackend("rdap", taken()))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil || result.Source != "rdap" {
+			t.Errorf("Expected the rdap verdict, got %+v, %v", result, err)
+		}
+	})
+}
+
+func TestCheckDomain_ReservedAfterANegativeVerdict(t *testing.T) {
+	pricing := registrar.Pricing{Registration: 9.68, Currency: "USD", Provider: "test"}
+	lists, err := reserved.Builtin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("a free reserved name is not available", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.CheckPricing = true
+		quotes := &staticRegistrar{quote: registrar.Quote{Available: true, Pricing: pricing}}
+
+		s := resolver.NewResolverService(app,
+			resolver.WithBackend("rdap", &fakeBackend{result: resolver.CheckResult{Details: "404", Source: resolver.SourceRDAP}}),
+			resolver.WithRegistrar(quotes),
+			resolver.WithReservedLists(lists),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "nic.xyz")
+		if err != nil || result.Reserved == nil || result.Reserved.List != "icann" {
+			t.Fatalf("Expected nic.xyz to be reserved by the icann list, got %+v, %v", result, err)
+		}
+		if result.Available() || result.Registered {
+			t.Errorf("A reserved name is neither available nor registered, got %+v", result)
+		}
+		if result.Pricing != nil || quotes.calls != 0 {
+			t.Errorf("A reserved name must not be quoted, got %+v after %d calls", result.Pricing, quotes.calls)
+		}
+	})
+
+	t.Run("registered names are not looked up", func(t *testing.T) {
+		s := resolver.NewResolverService(config.NewTldxContext(),
+			resolver.WithBackend("rdap", taken()),
+			resolver.WithReservedLists(lists),
+		)
+
+		result, _ := s.CheckDomain(context.Background(), "nic.xyz")
+		if result.Reserved != nil || !result.Registered {
+			t.Errorf("Expected a plain taken verdict, got %+v", result)
+		}
+	})
+
+	t.Run("the registry's own answer stands", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Backends = []string{resolver.SourceEPP}
+
+		s := resolver.NewResolverService(app,
+			resolver.WithEPP(staticEPP{result: epp.Result{Available: true}}),
+			resolver.WithReservedLists(lists),
+		)
+
+		result, _ := s.CheckDomain(context.Background(), "nic.xyz")
+		if result.Reserved != nil || !result.Available() {
+			t.Errorf("Expected the EPP verdict to stand, got %+v", result)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.Reserved.Disabled = true
+
+		s := resolver.NewResolverService(app, resolver.WithBackend("rdap", &fakeBackend{}))
+
+		result, _ := s.CheckDomain(context.Background(), "nic.xyz")
+		if result.Reserved != nil {
+			t.Errorf("Expected no list lookup, got %+v", result.Reserved)
+		}
+	})
+}
+
+func TestZoneBackend(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "xyz.zone")
+	zoneFile := "xyz. 900 IN SOA a.nic.xyz. hostmaster.xyz. 2026100103 900 1800 6048000 3600\n" +
+		"acme.xyz. 3600 IN NS ns1.acme.net.\n"
+	if err := os.WriteFile(file, []byte(zoneFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zone.ImportFile(dir, "xyz", file, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	newApp := func(backends ...string) *config.TldxContext {
+		app := config.NewTldxContext()
+		app.Config.Backends = backends
+		app.Config.Zone.Dir = dir
+		app.Config.Reserved.Disabled = true
+		return app
+	}
+
+	t.Run("delegated", func(t *testing.T) {
+		s := resolver.NewResolverService(newApp(resolver.SourceZone))
+		result, err := s.CheckDomain(context.Background(), "acme.xyz")
+		if err != nil || !result.Registered || result.Source != resolver.SourceZone {
+			t.Fatalf("Expected a zone verdict of registered, got %+v, %v", result, err)
+		}
+		if !strings.Contains(result.Details, "2026-10-01") {
+			t.Errorf("Expected the snapshot date in the details, got %q", result.Details)
+		}
+	})
+
+	t.Run("not delegated", func(t *testing.T) {
+		s := resolver.NewResolverService(newApp(resolver.SourceZone))
+		result, err := s.CheckDomain(context.Background(), "sub.other.xyz")
+		if err != nil || result.Registered || result.Source != resolver.SourceZone {
+			t.Errorf("Expected a zone verdict of available, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("no zone passes to the next backend", func(t *testing.T) {
+		s := resolver.NewResolverService(newApp(resolver.SourceZone, "rdap"), resolver.WithBackend("rdap", taken()))
+		result, err := s.CheckDomain(context.Background(), "acme.com")
+		if err != nil || result.Source != "rdap" {
+			t.Errorf("Expected the rdap verdict, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("RDAP confirms negatives", func(t *testing.T) {
+		app := newApp(resolver.SourceZone)
+		app.Config.Zone.ConfirmNegatives = true
+		s := resolver.NewResolverService(app, resolver.WithRDAPQuerier(&mockRDAPQuerier{resp: makeDomainRDAPResponse()}))
+
+		result, err := s.CheckDomain(context.Background(), "other.xyz")
+		if err != nil || !result.Registered || result.Source != resolver.SourceRDAP {
+			t.Fatalf("Expected RDAP to overrule the zone, got %+v, %v", result, err)
+		}
+		if !strings.HasPrefix(result.Details, "Not delegated") {
+			t.Errorf("Expected the zone answer in the details, got %q", result.Details)
+		}
+	})
+}
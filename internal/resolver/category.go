@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Error categories, for counting failed lookups by cause.
+const (
+	ErrorTimeout     = "timeout"
+	ErrorCancelled   = "cancelled"
+	ErrorRateLimited = "rate-limited"
+	ErrorNetwork     = "network"
+	ErrorInvalid     = "invalid"
+	ErrorOther       = "other"
+)
+
+var (
+	rateLimitedPattern = regexp.MustCompile(`(?i)\b429\b|too many requests|rate.?limit`)
+	networkPattern     = regexp.MustCompile(`(?i)connection.*(refused|reset)|no such host|network is unreachable|no route to host`)
+)
+
+// ErrorCategory sorts a lookup error into one of the Error* categories. Most
+// lookup errors arrive wrapped or as plain text from a backend, so this goes
+// by the error's type where it can and its message where it cannot.
+func ErrorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCancelled
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case strings.Contains(strings.ToLower(err.Error()), "timeout"):
+		return ErrorTimeout
+	case rateLimitedPattern.MatchString(err.Error()):
+		return ErrorRateLimited
+	case errors.As(err, &netErr), errors.Is(err, errDNSHijacked), networkPattern.MatchString(err.Error()):
+		return ErrorNetwork
+	case err.Error() == "invalid domain":
+		return ErrorInvalid
+	}
+	return ErrorOther
+}
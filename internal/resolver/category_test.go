@@ -0,0 +1,29 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCategory(t *testing.T) {
+	for err, want := range map[error]string{
+		nil:                      "",
+		context.DeadlineExceeded: resolver.ErrorTimeout,
+		fmt.Errorf("rdap lookup failed: %w", context.DeadlineExceeded): resolver.ErrorTimeout,
+		errors.New("read udp: i/o timeout"):                            resolver.ErrorTimeout,
+		context.Canceled:                                               resolver.ErrorCancelled,
+		errors.New("HTTP 429 Too Many Requests"):                       resolver.ErrorRateLimited,
+		&net.DNSError{Err: "no such host", Name: "rdap.example"}:       resolver.ErrorNetwork,
+		errors.New("dial tcp: connection refused"):                     resolver.ErrorNetwork,
+		errors.New("invalid domain"):                                   resolver.ErrorInvalid,
+		errors.New("WHOIS lookup error: EOF"):                          resolver.ErrorOther,
+	} {
+		assert.Equal(t, want, resolver.ErrorCategory(err), "%v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// delegationChecker asks a TLD's authoritative nameservers whether a name is
+// delegated. A referral from the parent zone means the name is registered;
+// NXDOMAIN means it is not in the zone, which RDAP still has to confirm since
+// registered-but-undelegated names exist.
+type delegationChecker struct {
+	client *dns.Client
+	// tcp repeats a query whose UDP answer came back truncated.
+	tcp      *dns.Client
+	lookupNS func(ctx context.Context, zone string) ([]string, error)
+
+	mu          sync.Mutex
+	nameservers map[string][]string
+}
+
+// delegationUDPSize is the EDNS0 buffer size advertised, the one DNS Flag
+// Day 2020 settled on to avoid fragmentation.
+const delegationUDPSize = 1232
+
+func newDelegationChecker(lookupNS func(context.Context, string) ([]string, error)) *delegationChecker {
+	return &delegationChecker{
+		client:      &dns.Client{},
+		tcp:         &dns.Client{Net: "tcp"},
+		lookupNS:    lookupNS,
+		nameservers: make(map[string][]string),
+	}
+}
+
+// NewDelegationLookup returns the pre-screen used when none is injected,
+// finding each TLD's nameservers through r.
+func NewDelegationLookup(r DNSResolver) func(context.Context, string) (bool, error) {
+	return newDelegationChecker(r.LookupNS).IsDelegated
+}
+
+// parentZone returns everything after the first label, e.g. "co.uk" for
+// "example.co.uk".
+func parentZone(domain string) (string, bool) {
+	domain = strings.TrimSuffix(domain, ".")
+	_, parent, ok := strings.Cut(domain, ".")
+	if !ok || parent == "" {
+		return "", false
+	}
+	return parent, true
+}
+
+// zoneNameservers returns host:port addresses for the zone's authoritative
+// servers, looked up once per zone and cached for the life of the checker.
+func (c *delegationChecker) zoneNameservers(ctx context.Context, zone string) ([]string, error) {
+	c.mu.Lock()
+	cached, ok := c.nameservers[zone]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	records, err := c.lookupNS(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("lookup nameservers for %s: %w", zone, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no nameservers found for %s", zone)
+	}
+
+	servers := make([]string, 0, len(records))
+	for _, host := range records {
+		servers = append(servers, net.JoinHostPort(strings.TrimSuffix(host, "."), "53"))
+	}
+
+	c.mu.Lock()
+	c.nameservers[zone] = servers
+	c.mu.Unlock()
+
+	return servers, nil
+}
+
+func (c *delegationChecker) IsDelegated(ctx context.Context, domain string) (bool, error) {
+	zone, ok := parentZone(domain)
+	if !ok {
+		return false, errors.New("domain has no parent zone")
+	}
+
+	servers, err := c.zoneNameservers(ctx, zone)
+	if err != nil {
+		return false, err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), dns.TypeNS)
+	// Authoritative servers answer for their own zone only; asking for
+	// recursion just earns a REFUSED from some registries.
+	msg.RecursionDesired = false
+	// A referral with glue can outgrow 512 bytes.
+	msg.SetEdns0(delegationUDPSize, false)
+
+	var lastErr error
+	for _, server := range servers {
+		resp, _, err := c.client.ExchangeContext(ctx, msg, server)
+		if err == nil && resp.Truncated {
+			// A truncated referral may have lost its NS records.
+			resp, _, err = c.tcp.ExchangeContext(ctx, msg, server)
+		}
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			continue
+		}
+		return delegatedFromResponse(resp, domain)
+	}
+
+	return false, fmt.Errorf("no nameserver for %s answered: %w", zone, lastErr)
+}
+
+// delegatedFromResponse reads a parent-zone answer. NS records owned by the
+// name, in either the answer or the authority section, are a delegation.
+func delegatedFromResponse(resp *dns.Msg, domain string) (bool, error) {
+	if resp.Truncated {
+		return false, errors.New("nameserver returned a truncated answer")
+	}
+	switch resp.Rcode {
+	case dns.RcodeNameError:
+		return false, nil
+	case dns.RcodeSuccess:
+	default:
+		return false, fmt.Errorf("nameserver returned %s", dns.RcodeToString[resp.Rcode])
+	}
+
+	owner := dns.Fqdn(domain)
+	for _, section := range [][]dns.RR{resp.Answer, resp.Ns} {
+		for _, rr := range section {
+			if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, owner) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkDelegation runs the pre-screen. An error means "no verdict", never
+// "available".
+func (s *ResolverService) checkDelegation(ctx context.Context, domain string) (bool, error) {
+	check := s.delegation.IsDelegated
+	if s.delegationFn != nil {
+		check = s.delegationFn
+	}
+
+	started := time.Now()
+	delegated, err := check(ctx, domain)
+	detail := "not in the parent zone"
+	if delegated {
+		detail = "delegated by the parent zone"
+	}
+	traceStep(ctx, SourceDelegation, TraceLookup, detail, started, err)
+	return delegated, err
+}
@@ -0,0 +1,174 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestParentZone(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+		ok     bool
+	}{
+		{"example.com", "com", true},
+		{"example.co.uk", "co.uk", true},
+		{"example.com.", "com", true},
+		{"com", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parentZone(tt.domain)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parentZone(%q) = %q, %v; want %q, %v", tt.domain, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func nsRecord(t *testing.T, owner string) dns.RR {
+	t.Helper()
+	rr, err := dns.NewRR(owner + " 172800 IN NS ns1.example.net.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+func TestDelegatedFromResponse(t *testing.T) {
+	t.Run("referral in the authority section", func(t *testing.T) {
+		resp := &dns.Msg{Ns: []dns.RR{nsRecord(t, "Example.com.")}}
+		delegated, err := delegatedFromResponse(resp, "example.com")
+		if err != nil || !delegated {
+			t.Errorf("expected a delegation, got %v, %v", delegated, err)
+		}
+	})
+
+	t.Run("nxdomain", func(t *testing.T) {
+		resp := &dns.Msg{}
+		resp.Rcode = dns.RcodeNameError
+		delegated, err := delegatedFromResponse(resp, "example.com")
+		if err != nil || delegated {
+			t.Errorf("expected no delegation, got %v, %v", delegated, err)
+		}
+	})
+
+	t.Run("ns records for another owner", func(t *testing.T) {
+		resp := &dns.Msg{Ns: []dns.RR{nsRecord(t, "com.")}}
+		if delegated, _ := delegatedFromResponse(resp, "example.com"); delegated {
+			t.Error("the zone's own NS records are not a delegation of the name")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		resp := &dns.Msg{}
+		resp.Truncated = true
+		if _, err := delegatedFromResponse(resp, "example.com"); err == nil {
+			t.Error("a truncated answer must not be read as no delegation")
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		resp := &dns.Msg{}
+		resp.Rcode = dns.RcodeRefused
+		if _, err := delegatedFromResponse(resp, "example.com"); err == nil {
+			t.Error("expected a REFUSED answer to be an error")
+		}
+	})
+}
+
+// startZoneServer serves a parent zone that delegates only the given names.
+func startZoneServer(t *testing.T, delegated ...string) string {
+	t.Helper()
+
+	known := make(map[string]bool, len(delegated))
+	for _, name := range delegated {
+		known[dns.Fqdn(name)] = true
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		name := req.Question[0].Name
+		if known[name] {
+			resp.Ns = []dns.RR{nsRecord(t, name)}
+		} else {
+			resp.Rcode = dns.RcodeNameError
+		}
+		w.WriteMsg(resp)
+	})
+
+	server := &dns.Server{PacketConn: pc, Handler: handler}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	return pc.LocalAddr().String()
+}
+
+func TestDelegationChecker_IsDelegated(t *testing.T) {
+	addr := startZoneServer(t, "taken.test")
+
+	checker := newDelegationChecker(nil)
+	checker.nameservers["test"] = []string{addr}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	delegated, err := checker.IsDelegated(ctx, "taken.test")
+	if err != nil || !delegated {
+		t.Errorf("taken.test: expected a delegation, got %v, %v", delegated, err)
+	}
+
+	delegated, err = checker.IsDelegated(ctx, "free.test")
+	if err != nil || delegated {
+		t.Errorf("free.test: expected no delegation, got %v, %v", delegated, err)
+	}
+}
+
+func TestDelegationChecker_RetriesTruncatedAnswersOverTCP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truncated := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Truncated = true
+		w.WriteMsg(resp)
+	})
+	referral := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Ns = []dns.RR{nsRecord(t, req.Question[0].Name)}
+		w.WriteMsg(resp)
+	})
+	udp := &dns.Server{PacketConn: pc, Handler: truncated}
+	tcp := &dns.Server{Listener: ln, Handler: referral}
+	go udp.ActivateAndServe()
+	go tcp.ActivateAndServe()
+	t.Cleanup(func() { udp.Shutdown(); tcp.Shutdown() })
+
+	checker := newDelegationChecker(nil)
+	checker.nameservers["test"] = []string{pc.LocalAddr().String()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	delegated, err := checker.IsDelegated(ctx, "taken.test")
+	if err != nil || !delegated {
+		t.Errorf("expected the TCP retry to find the delegation, got %v, %v", delegated, err)
+	}
+}
@@ -0,0 +1,297 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSResolver performs the recursive lookups used by the dns backend, the
+// for-sale TXT lookup and the delegation pre-screen's nameserver discovery.
+type DNSResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupNS(ctx context.Context, zone string) ([]string, error)
+}
+
+// systemResolver is the operating system's resolver.
+type systemResolver struct{ r net.Resolver }
+
+func (s *systemResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return s.r.LookupHost(ctx, host)
+}
+
+func (s *systemResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return s.r.LookupTXT(ctx, name)
+}
+
+func (s *systemResolver) LookupNS(ctx context.Context, zone string) ([]string, error) {
+	records, err := s.r.LookupNS(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(records))
+	for _, ns := range records {
+		hosts = append(hosts, ns.Host)
+	}
+	return hosts, nil
+}
+
+// NewDNSResolver returns a resolver that sends every query to server:
+//
+//	1.1.1.1, 1.1.1.1:53, udp://1.1.1.1   plain DNS over UDP, retried over TCP when truncated
+//	tcp://1.1.1.1                        plain DNS over TCP
+//	tls://dns.quad9.net                  DNS-over-TLS, port 853 by default
+//	https://cloudflare-dns.com/dns-query DNS-over-HTTPS (RFC 8484)
+//
+// An empty server means the system resolver. client is used for DoH; nil
+// means http.DefaultClient.
+func NewDNSResolver(server string, client *http.Client) (DNSResolver, error) {
+	if server == "" {
+		return &systemResolver{}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(server, "://")
+	if !ok {
+		scheme, rest = "udp", server
+	}
+
+	switch strings.ToLower(scheme) {
+	case "udp", "tcp":
+		addr, err := withDefaultPort(rest, "53")
+		if err != nil {
+			return nil, err
+		}
+		return &dnsServerResolver{exchange: plainExchange(addr, strings.ToLower(scheme))}, nil
+	case "tls":
+		addr, err := withDefaultPort(rest, "853")
+		if err != nil {
+			return nil, err
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		c := &dns.Client{Net: "tcp-tls", TLSConfig: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		return &dnsServerResolver{exchange: clientExchange(c, addr)}, nil
+	case "https":
+		if _, err := url.Parse(server); err != nil {
+			return nil, fmt.Errorf("invalid DNS-over-HTTPS URL %q: %w", server, err)
+		}
+		if client == nil {
+			client = http.DefaultClient
+		}
+		return &dnsServerResolver{exchange: dohExchange(client, server)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported DNS server scheme %q (use udp, tcp, tls or https)", scheme)
+	}
+}
+
+func withDefaultPort(hostport, port string) (string, error) {
+	hostport = strings.TrimSuffix(hostport, "/")
+	if hostport == "" {
+		return "", errors.New("DNS server address is empty")
+	}
+	if _, _, err := net.SplitHostPort(hostport); err == nil {
+		return hostport, nil
+	}
+	// A bare IPv6 address or hostname.
+	return net.JoinHostPort(strings.Trim(hostport, "[]"), port), nil
+}
+
+type exchangeFunc func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error)
+
+func clientExchange(c *dns.Client, addr string) exchangeFunc {
+	return func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+		resp, _, err := c.ExchangeContext(ctx, msg, addr)
+		return resp, err
+	}
+}
+
+// plainExchange retries a truncated UDP answer over TCP, as stub resolvers do.
+func plainExchange(addr, network string) exchangeFunc {
+	tcp := clientExchange(&dns.Client{Net: "tcp"}, addr)
+	if network == "tcp" {
+		return tcp
+	}
+	udp := clientExchange(&dns.Client{Net: "udp"}, addr)
+	return func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+		resp, err := udp(ctx, msg)
+		if err == nil && resp.Truncated {
+			return tcp(ctx, msg)
+		}
+		return resp, err
+	}
+}
+
+func dohExchange(client *http.Client, endpoint string) exchangeFunc {
+	return func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+		// RFC 8484 asks for ID 0 so answers are cacheable.
+		msg.Id = 0
+		packed, err := msg.Pack()
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(packed))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/dns-message")
+		req.Header.Set("Accept", "application/dns-message")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("DNS-over-HTTPS returned %s", resp.Status)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+		if err != nil {
+			return nil, err
+		}
+
+		answer := new(dns.Msg)
+		if err := answer.Unpack(body); err != nil {
+			return nil, fmt.Errorf("DNS-over-HTTPS returned an invalid message: %w", err)
+		}
+		return answer, nil
+	}
+}
+
+// dnsServerResolver sends queries to one configured server.
+type dnsServerResolver struct {
+	exchange exchangeFunc
+}
+
+// query returns the answer records of type qtype. NXDOMAIN is reported the
+// way net.Resolver reports it, so callers treat both resolvers alike.
+func (r *dnsServerResolver) query(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+
+	resp, err := r.exchange(ctx, msg)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, IsTimeout: ctx.Err() != nil}
+	}
+
+	switch resp.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	default:
+		return nil, &net.DNSError{Err: "server returned " + dns.RcodeToString[resp.Rcode], Name: name}
+	}
+
+	var records []dns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype {
+			records = append(records, rr)
+		}
+	}
+	return records, nil
+}
+
+func (r *dnsServerResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	var addrs []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		records, err := r.query(ctx, host, qtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range records {
+			switch rr := rr.(type) {
+			case *dns.A:
+				addrs = append(addrs, rr.A.String())
+			case *dns.AAAA:
+				addrs = append(addrs, rr.AAAA.String())
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (r *dnsServerResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, err := r.query(ctx, name, dns.TypeTXT)
+	if err != nil {
+		return nil, err
+	}
+	// Like net.Resolver, join a record's character-strings into one value.
+	txts := make([]string, 0, len(records))
+	for _, rr := range records {
+		txts = append(txts, strings.Join(rr.(*dns.TXT).Txt, ""))
+	}
+	return txts, nil
+}
+
+func (r *dnsServerResolver) LookupNS(ctx context.Context, zone string) ([]string, error) {
+	records, err := r.query(ctx, zone, dns.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(records))
+	for _, rr := range records {
+		hosts = append(hosts, rr.(*dns.NS).Ns)
+	}
+	return hosts, nil
+}
+
+const canaryTimeout = 5 * time.Second
+
+// errDNSHijacked is returned for host lookups once the canary has caught the
+// resolver inventing answers.
+var errDNSHijacked = errors.New("DNS resolver answers for nonexistent names")
+
+// dnsResolver returns the configured resolver, or the system one for a
+// service not built by NewResolverService.
+func (s *ResolverService) dnsResolver() DNSResolver {
+	if s.dns == nil {
+		return &systemResolver{}
+	}
+	return s.dns
+}
+
+// canaryName is a random name under .com, very unlikely to be registered, so
+// any address returned for it was almost certainly made up by the resolver.
+func canaryName() string {
+	var b [12]byte
+	rand.Read(b[:]) //nolint:errcheck // crypto/rand.Read never fails
+	return "tldx-canary-" + hex.EncodeToString(b[:]) + ".com"
+}
+
+// resolverHijacksNXDOMAIN asks for a name that cannot exist, once per
+// service. Filtering and "search assist" resolvers answer such names with
+// their own addresses, which would make every unregistered name look taken.
+func (s *ResolverService) resolverHijacksNXDOMAIN(ctx context.Context) bool {
+	s.canaryOnce.Do(func() {
+		// Not bound to the first caller's deadline, whose answer every later
+		// lookup shares.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), canaryTimeout)
+		defer cancel()
+
+		name := canaryName()
+		addrs, err := s.dnsResolver().LookupHost(ctx, name)
+		if err == nil && len(addrs) > 0 {
+			s.hijacked = true
+			slog.Warn("The DNS resolver answers for names that do not exist; ignoring DNS answers. Set dns_server or --dns-server to use another resolver.",
+				"canary", name, "answer", addrs)
+		}
+	})
+	return s.hijacked
+}
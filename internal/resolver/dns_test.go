@@ -0,0 +1,234 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/miekg/dns"
+)
+
+// recursiveHandler answers like a recursive resolver for a fixed set of
+// records, and NXDOMAIN for everything else.
+func recursiveHandler(t *testing.T, records ...string) dns.Handler {
+	t.Helper()
+
+	var rrs []dns.RR
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	return dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		q := req.Question[0]
+
+		known := false
+		for _, rr := range rrs {
+			if !strings.EqualFold(rr.Header().Name, q.Name) {
+				continue
+			}
+			known = true
+			if rr.Header().Rrtype == q.Qtype {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+		if !known {
+			resp.Rcode = dns.RcodeNameError
+		}
+		w.WriteMsg(resp)
+	})
+}
+
+func startRecursiveServer(t *testing.T, network string, handler dns.Handler) string {
+	t.Helper()
+
+	server := &dns.Server{Handler: handler}
+	switch network {
+	case "udp":
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.PacketConn = pc
+	case "tcp":
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.Listener = ln
+	}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	if server.PacketConn != nil {
+		return server.PacketConn.LocalAddr().String()
+	}
+	return server.Listener.Addr().String()
+}
+
+var testRecords = []string{
+	"taken.test. 300 IN A 192.0.2.1",
+	"taken.test. 300 IN AAAA 2001:db8::1",
+	`_for-sale.taken.test. 300 IN TXT "v=FORSALE1;" "fval=USD100"`,
+	"test. 300 IN NS ns1.test.",
+}
+
+func exerciseResolver(t *testing.T, r DNSResolver) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	addrs, err := r.LookupHost(ctx, "taken.test")
+	if err != nil || strings.Join(addrs, ",") != "192.0.2.1,2001:db8::1" {
+		t.Errorf("LookupHost: got %v, %v", addrs, err)
+	}
+
+	_, err = r.LookupHost(ctx, "free.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("LookupHost: expected a not-found DNSError for NXDOMAIN, got %v", err)
+	}
+
+	txts, err := r.LookupTXT(ctx, "_for-sale.taken.test")
+	if err != nil || len(txts) != 1 || txts[0] != "v=FORSALE1;fval=USD100" {
+		t.Errorf("LookupTXT: expected one joined record, got %q, %v", txts, err)
+	}
+
+	hosts, err := r.LookupNS(ctx, "test")
+	if err != nil || len(hosts) != 1 || hosts[0] != "ns1.test." {
+		t.Errorf("LookupNS: got %v, %v", hosts, err)
+	}
+}
+
+func TestDNSResolver_PlainDNS(t *testing.T) {
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			addr := startRecursiveServer(t, network, recursiveHandler(t, testRecords...))
+
+			server := addr
+			if network == "tcp" {
+				server = "tcp://" + addr
+			}
+			r, err := NewDNSResolver(server, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exerciseResolver(t, r)
+		})
+	}
+}
+
+func TestDNSResolver_DoH(t *testing.T) {
+	handler := recursiveHandler(t, testRecords...)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		msg := new(dns.Msg)
+		if err := msg.Unpack(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rec := &recordingWriter{}
+		handler.ServeDNS(rec, msg)
+		packed, _ := rec.msg.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(packed)
+	}))
+	t.Cleanup(srv.Close)
+
+	r, err := NewDNSResolver(srv.URL+"/dns-query", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseResolver(t, r)
+}
+
+// recordingWriter captures the message a dns.Handler writes.
+type recordingWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (w *recordingWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func TestNewDNSResolver_Specs(t *testing.T) {
+	valid := []string{"", "1.1.1.1", "1.1.1.1:5353", "udp://1.1.1.1", "tcp://[2606:4700::1111]:53", "tls://dns.quad9.net", "https://cloudflare-dns.com/dns-query"}
+	for _, spec := range valid {
+		if _, err := NewDNSResolver(spec, nil); err != nil {
+			t.Errorf("%q: unexpected error %v", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"quic://dns.example", "tcp://"} {
+		if _, err := NewDNSResolver(spec, nil); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+
+	if got, _ := withDefaultPort("dns.quad9.net", "853"); got != "dns.quad9.net:853" {
+		t.Errorf("expected the default port to be added, got %q", got)
+	}
+}
+
+// answerEverything is a resolver that invents an address for any name, like
+// a filtering resolver that sinkholes unknown names.
+type answerEverything struct{ DNSResolver }
+
+func (answerEverything) LookupHost(context.Context, string) ([]string, error) {
+	return []string{"198.51.100.7"}, nil
+}
+
+func TestCheckDomain_DetectsNXDOMAINHijacking(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{SourceDNS}
+
+	s := NewResolverService(app, WithDNSResolver(answerEverything{}))
+
+	result, err := s.CheckDomain(context.Background(), "surely-unregistered.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Registered {
+		t.Error("A hijacking resolver's answer must not mark the domain taken")
+	}
+	if !strings.Contains(result.Details, "rewrites NXDOMAIN") {
+		t.Errorf("Expected the hijack in the details, got %q", result.Details)
+	}
+}
+
+func TestCheckDomain_HonestResolverPassesCanary(t *testing.T) {
+	addr := startRecursiveServer(t, "udp", recursiveHandler(t, testRecords...))
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{SourceDNS}
+	app.Config.DNSServer = addr
+
+	s := NewResolverService(app)
+
+	result, err := s.CheckDomain(context.Background(), "taken.test")
+	if err != nil || !result.Registered || result.Source != SourceDNS {
+		t.Errorf("Expected the configured server's answer, got %+v, %v", result, err)
+	}
+	if s.hijacked {
+		t.Error("An NXDOMAIN for the canary must not be reported as hijacking")
+	}
+}
@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+)
+
+// WithOrderedResults makes CheckDomainsStreaming send results in the order
+// their specs were dispatched rather than as lookups finish, so that a
+// consumer stopping after N results gets the first N by the composer's
+// order. A result that finishes early waits for the ones before it.
+func WithOrderedResults() ResolverOption {
+	return func(s *ResolverService) { s.ordered = true }
+}
+
+// sequencer passes results on to out, in dispatch order when ordered. Each
+// dispatched spec takes a ticket and settles it exactly once, with a result
+// or with nil when it has none. Skipped specs take no ticket, so they never
+// hold the others up.
+type sequencer struct {
+	out     chan<- DomainResult
+	ordered bool
+
+	// issued is only touched by the dispatching goroutine.
+	issued int
+
+	mu   sync.Mutex
+	sent int
+	held map[int]*DomainResult
+}
+
+func newSequencer(out chan<- DomainResult, ordered bool) *sequencer {
+	return &sequencer{out: out, ordered: ordered, held: make(map[int]*DomainResult)}
+}
+
+func (q *sequencer) ticket() int {
+	q.issued++
+	return q.issued - 1
+}
+
+// settle hands over the result for ticket t, sending it and any held results
+// it was blocking. It reports false once ctx is done; what was not sent by
+// then is dropped.
+func (q *sequencer) settle(ctx context.Context, t int, result *DomainResult) bool {
+	if !q.ordered {
+		return result == nil || q.send(ctx, *result)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.held[t] = result
+	for {
+		next, ok := q.held[q.sent]
+		if !ok {
+			return true
+		}
+		delete(q.held, q.sent)
+		q.sent++
+		if next != nil && !q.send(ctx, *next) {
+			return false
+		}
+	}
+}
+
+func (q *sequencer) send(ctx context.Context, result DomainResult) bool {
+	select {
+	case q.out <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/brandonyoungdev/tldx/internal/prefilter"
+)
+
+// WithPrefilter replaces the filter loaded from the prefilter config. The
+// service consults and teaches it, but leaves saving it to the caller.
+func WithPrefilter(f *prefilter.Filter) ResolverOption {
+	return func(s *ResolverService) { s.prefilter = f }
+}
+
+// PrefilterStats counts what the prefilter did for a service's runs.
+type PrefilterStats struct {
+	// Skipped domains were reported taken without a lookup.
+	Skipped int
+	// Deferred domains were moved to the end of the run.
+	Deferred int
+	// Saved is the lookups not made: the skipped domains, and the deferred
+	// ones the run stopped before reaching.
+	Saved int
+	// Learned is the taken domains added to the filter.
+	Learned int
+}
+
+type prefilterCounters struct {
+	skipped, deferred, unreached, learned atomic.Int64
+}
+
+// PrefilterStats reports the prefilter's counts so far.
+func (s *ResolverService) PrefilterStats() PrefilterStats {
+	c := &s.prefilterCounts
+	return PrefilterStats{
+		Skipped:  int(c.skipped.Load()),
+		Deferred: int(c.deferred.Load()),
+		Saved:    int(c.skipped.Load() + c.unreached.Load()),
+		Learned:  int(c.learned.Load()),
+	}
+}
+
+// openPrefilter loads the filter named by the prefilter config.
+func (s *ResolverService) openPrefilter() {
+	opts := s.app.Config.Prefilter
+	if err := prefilter.Validate(opts); err != nil {
+		slog.Warn("Prefilter unavailable", "error", err)
+		return
+	}
+	path := opts.Path
+	if path == "" {
+		var err error
+		if path, err = prefilter.DefaultPath(); err != nil {
+			slog.Warn("Prefilter unavailable", "error", err)
+			return
+		}
+	}
+
+	if opts.Reset {
+		if err := prefilter.Reset(path); err != nil {
+			slog.Warn("Could not reset the prefilter", "error", err)
+		}
+	}
+	f, fresh, err := prefilter.Open(path, opts.Size, opts.FalsePositiveRate, opts.MaxAge)
+	if err != nil {
+		slog.Warn("Prefilter unavailable", "error", err)
+		return
+	}
+	if fresh {
+		slog.Debug("Starting a new prefilter", "path", path, "size", opts.Size)
+	} else if f.Count() > f.Capacity() {
+		slog.Warn("The prefilter holds more domains than it was sized for; raise its size to keep false positives rare",
+			"domains", f.Count(), "size", f.Capacity())
+	}
+	s.prefilter = f
+	s.prefilterPath = path
+}
+
+// prefiltered reports whether the filter has seen domain taken.
+func (s *ResolverService) prefiltered(domain string) bool {
+	return s.prefilter.Has(domain)
+}
+
+// learnTaken adds a domain a lookup found registered, unless the sources
+// disagreed on it.
+func (s *ResolverService) learnTaken(domain string, result CheckResult) {
+	if !result.Registered || result.Confidence == ConfidenceConflicting {
+		return
+	}
+	if s.prefilter.Add(domain) {
+		s.prefilterCounts.learned.Add(1)
+	}
+}
+
+// skipPrefiltered answers for a probably-taken domain without a lookup. The
+// filter has false positives, so the details say it was not looked up. It
+// reports false if ctx ended first.
+func (s *ResolverService) skipPrefiltered(ctx context.Context, seq *sequencer, spec DomainSpec) bool {
+	result := DomainResult{
+		Domain:  spec.Domain,
+		Details: "Probably taken (prefilter)",
+		Keyword: spec.Keyword,
+		Prefix:  spec.Prefix,
+		Suffix:  spec.Suffix,
+		TLD:     spec.TLD,
+		Source:  SourcePrefilter,
+	}
+	if !seq.settle(ctx, seq.ticket(), &result) {
+		return false
+	}
+	s.prefilterCounts.skipped.Add(1)
+	return true
+}
+
+// savePrefilter writes what this service learned back to disk.
+func (s *ResolverService) savePrefilter() error {
+	if s.prefilterPath == "" {
+		return nil
+	}
+	return s.prefilter.Save(s.prefilterPath)
+}
@@ -4,26 +4,53 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"math/rand/v2"
-	"net"
 	"net/http"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/epp"
 	"github.com/brandonyoungdev/tldx/internal/forsale"
+	"github.com/brandonyoungdev/tldx/internal/prefilter"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/reserved"
+	"github.com/brandonyoungdev/tldx/internal/transport"
 	"github.com/brandonyoungdev/tldx/internal/validate"
+	"github.com/brandonyoungdev/tldx/internal/zone"
 	"github.com/likexian/whois"
 	whoisparser "github.com/likexian/whois-parser"
 	"github.com/openrdap/rdap"
 	"github.com/openrdap/rdap/bootstrap"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const forSaleTimeout = 5 * time.Second
 
+// Verdict sources, reported on every result.
+const (
+	SourceRDAP       = "rdap"
+	SourceDelegation = "dns-delegation"
+	SourceDNS        = "dns"
+	SourceWHOIS      = "whois"
+	SourceRegistrar  = "registrar"
+	SourceEPP        = "epp"
+	SourceZone       = "zone"
+	// SourcePrefilter marks a domain reported taken because the prefilter
+	// saw it taken before, without a lookup.
+	SourcePrefilter = "prefilter"
+	// SourceFallback marks the "likely available" verdict given when no
+	// source could confirm either way.
+	SourceFallback = "fallback"
+)
+
 // rdapQuerier abstracts the RDAP client, allowing injection in tests.
 type rdapQuerier interface {
 	Do(req *rdap.Request) (*rdap.Response, error)
@@ -47,18 +74,93 @@ func WithDNSLookup(fn func(context.Context, string) ([]string, error)) ResolverO
 	return func(s *ResolverService) { s.dnsLookupFn = fn }
 }
 
+// WithDelegationLookup injects a custom NS delegation check (for testing).
+func WithDelegationLookup(fn func(context.Context, string) (bool, error)) ResolverOption {
+	return func(s *ResolverService) { s.delegationFn = fn }
+}
+
+// WithBackend installs r as the named backend for this service only,
+// replacing a registered backend of the same name.
+func WithBackend(name string, r Resolver) ResolverOption {
+	return func(s *ResolverService) { s.backends[name] = r }
+}
+
+// WithRegistrar injects a registrar pricing provider, replacing the one
+// built from the [registrar] config.
+func WithRegistrar(p registrar.Provider) ResolverOption {
+	return func(s *ResolverService) { s.registrar = p }
+}
+
+// EPPChecker answers EPP <check> queries; *epp.Session implements it.
+type EPPChecker interface {
+	Check(ctx context.Context, domain string) (epp.Result, error)
+}
+
+// WithEPP injects an EPP client, replacing the session built from the [epp]
+// config.
+func WithEPP(c EPPChecker) ResolverOption {
+	return func(s *ResolverService) { s.epp = c }
+}
+
+// WithDNSResolver replaces the resolver built from the dns_server config.
+func WithDNSResolver(r DNSResolver) ResolverOption {
+	return func(s *ResolverService) { s.dns = r }
+}
+
+// WithReservedLists replaces the lists loaded from the reserved config.
+func WithReservedLists(l *reserved.Lists) ResolverOption {
+	return func(s *ResolverService) { s.reserved = l }
+}
+
+// WithSkip makes CheckDomainsStreaming drop any spec for which skip reports
+// true when its turn comes, without a lookup or a result. skip must be safe
+// for concurrent use.
+func WithSkip(skip func(DomainSpec) bool) ResolverOption {
+	return func(s *ResolverService) { s.skip = skip }
+}
+
 // WithTXTLookup injects a custom DNS TXT lookup function (for testing).
 func WithTXTLookup(fn func(context.Context, string) ([]string, error)) ResolverOption {
 	return func(s *ResolverService) { s.txtLookupFn = fn }
 }
 
 type ResolverService struct {
-	httpClient  *http.Client
-	app         *config.TldxContext
-	rdapQuerier rdapQuerier
-	whoisFn     func(string, ...string) (string, error)
-	dnsLookupFn func(context.Context, string) ([]string, error)
-	txtLookupFn func(context.Context, string) ([]string, error)
+	httpClient   *http.Client
+	app          *config.TldxContext
+	rdapQuerier  rdapQuerier
+	whoisFn      func(string, ...string) (string, error)
+	dnsLookupFn  func(context.Context, string) ([]string, error)
+	txtLookupFn  func(context.Context, string) ([]string, error)
+	delegation   *delegationChecker
+	delegationFn func(context.Context, string) (bool, error)
+
+	dns        DNSResolver
+	canaryOnce sync.Once
+	hijacked   bool
+
+	tldSemsMu sync.Mutex
+	tldSems   map[string]chan struct{}
+
+	registrar registrar.Provider
+	epp       EPPChecker
+	reserved  *reserved.Lists
+
+	zonesMu sync.Mutex
+	zones   map[string]*zone.Store
+
+	skip    func(DomainSpec) bool
+	ordered bool
+
+	prefilter       *prefilter.Filter
+	prefilterPath   string
+	prefilterCounts prefilterCounters
+
+	backendsMu sync.Mutex
+	backends   map[string]Resolver
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry
 }
 
 type DomainSpec struct {
@@ -70,33 +172,64 @@ type DomainSpec struct {
 }
 
 type DomainResult struct {
-	Domain    string        `json:"domain"`
-	Available bool          `json:"available"`
-	Details   string        `json:"details,omitempty"`
-	Error     error         `json:"error,omitempty"`
-	Keyword   string        `json:"keyword,omitempty"`
-	Prefix    string        `json:"prefix,omitempty"`
-	Suffix    string        `json:"suffix,omitempty"`
-	TLD       string        `json:"tld,omitempty"`
-	ForSale   *forsale.Info `json:"for_sale,omitempty"`
+	Domain    string             `json:"domain"`
+	Available bool               `json:"available"`
+	Details   string             `json:"details,omitempty"`
+	Error     error              `json:"error,omitempty"`
+	Keyword   string             `json:"keyword,omitempty"`
+	Prefix    string             `json:"prefix,omitempty"`
+	Suffix    string             `json:"suffix,omitempty"`
+	TLD       string             `json:"tld,omitempty"`
+	ForSale   *forsale.Info      `json:"for_sale,omitempty"`
+	Source    string             `json:"source,omitempty"`
+	Pricing   *registrar.Pricing `json:"pricing,omitempty"`
+	Reserved  *reserved.Match    `json:"reserved,omitempty"`
+	// Confidence and Verdicts are set in --verify mode only.
+	Confidence string          `json:"confidence,omitempty"`
+	Verdicts   []SourceVerdict `json:"verdicts,omitempty"`
+	// Duration is how long the lookup took, and Retries how many attempts
+	// it retried. They feed the run's stats and are not encoded.
+	Duration time.Duration `json:"-"`
+	Retries  int           `json:"-"`
 }
 
 type EncodableDomainResult struct {
-	Domain    string        `json:"domain"`
-	Available bool          `json:"available"`
-	Details   string        `json:"details,omitempty"`
-	Error     string        `json:"error,omitempty"`
-	Keyword   string        `json:"keyword,omitempty"`
-	Prefix    string        `json:"prefix,omitempty"`
-	Suffix    string        `json:"suffix,omitempty"`
-	TLD       string        `json:"tld,omitempty"`
-	ForSale   *forsale.Info `json:"for_sale,omitempty"`
+	Domain    string             `json:"domain"`
+	Available bool               `json:"available"`
+	Details   string             `json:"details,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Keyword   string             `json:"keyword,omitempty"`
+	Prefix    string             `json:"prefix,omitempty"`
+	Suffix    string             `json:"suffix,omitempty"`
+	TLD       string             `json:"tld,omitempty"`
+	ForSale   *forsale.Info      `json:"for_sale,omitempty"`
+	Source    string             `json:"source,omitempty"`
+	Pricing   *registrar.Pricing `json:"pricing,omitempty"`
+	Reserved  *reserved.Match    `json:"reserved,omitempty"`
+	// Confidence and Verdicts are set in --verify mode only.
+	Confidence string          `json:"confidence,omitempty"`
+	Verdicts   []SourceVerdict `json:"verdicts,omitempty"`
 }
 
 type CheckResult struct {
 	Registered bool
 	Details    string
 	ForSale    *forsale.Info
+	// Source names the lookup that produced the verdict, e.g. SourceRDAP.
+	Source  string
+	Pricing *registrar.Pricing
+	// Reserved is set when a reserved-name list holds back a domain the
+	// lookup found free; such a domain is not available.
+	Reserved *reserved.Match
+	// Confidence and Verdicts are set by --verify, see verifyDomain.
+	Confidence string
+	Verdicts   []SourceVerdict
+}
+
+// Available reports whether the domain can be registered: no source found it
+// registered and no list reserves it.
+func (r CheckResult) Available() bool {
+	return !r.Registered && r.Reserved == nil
 }
 
 func (result DomainResult) AsEncodable() EncodableDomainResult {
@@ -114,46 +247,127 @@ func (result DomainResult) AsEncodable() EncodableDomainResult {
 		Suffix:    result.Suffix,
 		TLD:       result.TLD,
 		ForSale:   result.ForSale,
+		Source:    result.Source,
+		Pricing:   result.Pricing,
+		Reserved:  result.Reserved,
+
+		Confidence: result.Confidence,
+		Verdicts:   result.Verdicts,
 	}
 }
 
+// Resolver answers whether one domain is registered. Each availability
+// backend implements it, and so does ResolverService as a whole.
 type Resolver interface {
-	Check(domain string) (*CheckResult, error)
+	Check(ctx context.Context, domain string) (CheckResult, error)
 }
 
 func NewResolverService(app *config.TldxContext, opts ...ResolverOption) *ResolverService {
 	s := &ResolverService{
-		app:        app,
-		httpClient: &http.Client{},
+		app:      app,
+		backends: make(map[string]Resolver),
+	}
+	s.delegation = newDelegationChecker(func(ctx context.Context, zone string) ([]string, error) {
+		return s.dnsResolver().LookupNS(ctx, zone)
+	})
+	client, err := transport.NewHTTPClient(app.Config.Transport)
+	if err != nil {
+		slog.Warn("Invalid transport settings, using defaults", "error", err)
+		client, _ = transport.NewHTTPClient(config.TransportOptions{})
 	}
+	s.httpClient = client
+
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.telemetry = newTelemetry(s.tracerProvider, s.meterProvider)
+	if s.whoisFn == nil {
+		s.whoisFn = NewWhoisFetcher(app.Config.Transport)
+	}
+	if s.dns == nil {
+		r, err := NewDNSResolver(app.Config.DNSServer, s.httpClient)
+		if err != nil {
+			slog.Warn("Invalid DNS server, using the system resolver", "error", err)
+			r, _ = NewDNSResolver("", nil)
+		}
+		s.dns = r
+	}
+	if s.registrar == nil && app.Config.Registrar.Provider != "" {
+		if p, err := registrar.New(app.Config.Registrar, s.httpClient); err == nil {
+			s.registrar = p
+		} else {
+			slog.Warn("Registrar pricing unavailable", "error", err)
+		}
+	}
+	if s.reserved == nil && !app.Config.Reserved.Disabled {
+		if l, err := reserved.Load(app.Config.Reserved); err == nil {
+			s.reserved = l
+		} else {
+			slog.Warn("Reserved-name lists unavailable", "error", err)
+		}
+	}
+	if s.prefilter == nil && app.Config.Prefilter.Enabled {
+		s.openPrefilter()
+	}
+	if s.epp == nil && app.Config.EPP.Server != "" {
+		if session, err := epp.NewSession(app.Config.EPP, nil); err == nil {
+			s.epp = session
+		} else {
+			slog.Warn("EPP backend unavailable", "error", err)
+		}
+	}
 	return s
 }
 
-func (s *ResolverService) withRetry(ctx context.Context, fn func() (CheckResult, error)) (CheckResult, error) {
+// Close saves the prefilter and releases long-lived connections and files,
+// such as the EPP session and the imported zones. The service must not be
+// used afterwards.
+func (s *ResolverService) Close() error {
+	err := s.savePrefilter()
+	if c, ok := s.epp.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	s.zonesMu.Lock()
+	defer s.zonesMu.Unlock()
+	for _, store := range s.zones {
+		if store != nil {
+			err = errors.Join(err, store.Close())
+		}
+	}
+	s.zones = nil
+	return err
+}
+
+// withRetry calls fn until it succeeds or fails for good, using the retry
+// settings for domain's TLD.
+func (s *ResolverService) withRetry(ctx context.Context, domain string, fn func() (CheckResult, error)) (CheckResult, error) {
 	var lastErr error
-	backoff := s.app.Config.InitialBackoff
-	maxBackoff := s.app.Config.MaxBackoff
+	policy := s.policyFor(domain)
+	backoff := policy.initialBackoff
+	maxBackoff := policy.maxBackoff
 
-	for attempt := 0; attempt <= s.app.Config.MaxRetries; attempt++ {
+	for attempt := 0; attempt <= policy.maxRetries; attempt++ {
 		select {
 		case <-ctx.Done():
 			return CheckResult{}, ctx.Err()
 		default:
+			started := time.Now()
 			result, err := fn()
+			traceStep(ctx, "", TraceAttempt, fmt.Sprintf("attempt %d of %d", attempt+1, policy.maxRetries+1), started, err)
 			if err == nil {
 				return result, nil
 			}
 
-			if !isRetryable(err) || attempt == s.app.Config.MaxRetries {
+			if !isRetryable(err) || attempt == policy.maxRetries {
 				return CheckResult{}, err
 			}
 
 			lastErr = err
 
+			countRetry(ctx)
+			s.telemetry.retried(ctx, domain)
 			sleep := time.Duration(rand.Float64() * float64(backoff))
+			traceStep(ctx, "", TraceBackoff, fmt.Sprintf("retryable error, waiting %s", sleep.Round(time.Millisecond)), time.Time{}, nil)
 			select {
 			case <-time.After(sleep):
 				// Sleep completed
@@ -161,13 +375,22 @@ func (s *ResolverService) withRetry(ctx context.Context, fn func() (CheckResult,
 				return CheckResult{}, ctx.Err()
 			}
 
-			backoff = min(time.Duration(float64(backoff)*s.app.Config.BackoffFactor), maxBackoff)
+			backoff = min(time.Duration(float64(backoff)*policy.backoffFactor), maxBackoff)
 		}
 	}
 
 	return CheckResult{}, lastErr
 }
 
+type retriesKey struct{}
+
+// countRetry adds a retry to the count carried by ctx, if any.
+func countRetry(ctx context.Context) {
+	if n, ok := ctx.Value(retriesKey{}).(*atomic.Int32); ok {
+		n.Add(1)
+	}
+}
+
 var retryablePatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)timeout`),
 	regexp.MustCompile(`(?i)connection.*refused`),
@@ -186,80 +409,170 @@ func isRetryable(err error) bool {
 	return false
 }
 
-func (s *ResolverService) CheckDomain(ctx context.Context, domain string) (CheckResult, error) {
-	result, err := s.checkDomain(ctx, domain)
+// Check implements Resolver.
+func (s *ResolverService) Check(ctx context.Context, domain string) (CheckResult, error) {
+	return s.CheckDomain(ctx, domain)
+}
+
+func (s *ResolverService) CheckDomain(ctx context.Context, domain string) (result CheckResult, err error) {
+	ctx, end := s.telemetry.startCheck(ctx, domain)
+	defer func() { end(result, err) }()
+
+	result, err = s.checkDomain(ctx, domain)
+	if err == nil && !result.Registered {
+		result.Reserved = s.checkReserved(ctx, domain, result.Source)
+	}
 	if err == nil && result.Registered && s.app.Config.CheckForSale {
 		result.ForSale = s.checkForSale(ctx, domain)
 	}
+	if err == nil && result.Available() && result.Pricing == nil && s.app.Config.CheckPricing {
+		result.Pricing = s.checkPricing(ctx, domain)
+	}
 	return result, err
 }
 
+// checkPricing is additive like checkForSale: a failed or negative quote
+// returns nil and leaves the verdict alone.
+func (s *ResolverService) checkPricing(ctx context.Context, domain string) *registrar.Pricing {
+	if s.registrar == nil {
+		return nil
+	}
+	started := time.Now()
+	quote, err := s.registrar.Check(ctx, domain)
+	if err != nil || !quote.Available {
+		traceStep(ctx, "pricing", TraceLookup, "no price quoted", started, err)
+		return nil
+	}
+	traceStep(ctx, "pricing", TraceLookup, fmt.Sprintf("quoted %.2f %s", quote.Pricing.Registration, quote.Pricing.Currency), started, nil)
+	return &quote.Pricing
+}
+
+// checkReserved looks a free domain up in the reserved-name lists. A
+// registrar or registry answer already knows what can be registered, so it is
+// taken as is.
+func (s *ResolverService) checkReserved(ctx context.Context, domain, source string) *reserved.Match {
+	if s.reserved == nil || source == SourceRegistrar || source == SourceEPP {
+		return nil
+	}
+	match, ok := s.reserved.Check(domain)
+	if !ok {
+		traceStep(ctx, "reserved", TraceLookup, "not on a reserved-name list", time.Time{}, nil)
+		return nil
+	}
+	traceStep(ctx, "reserved", TraceVerdict, "reserved: "+match.String(), time.Time{}, nil)
+	return &match
+}
+
 // checkForSale is additive: any failure returns nil rather than an error, so a
 // missing or slow TXT answer can't change the availability verdict.
 func (s *ResolverService) checkForSale(ctx context.Context, domain string) *forsale.Info {
 	lookup := s.txtLookupFn
 	if lookup == nil {
-		resolver := net.Resolver{}
-		lookup = resolver.LookupTXT
+		lookup = s.dnsResolver().LookupTXT
 	}
 
 	timeout := forSaleTimeout
-	if s.app.Config.ContextTimeout > 0 {
-		timeout = min(timeout, s.app.Config.ContextTimeout)
+	if t := s.policyFor(domain).contextTimeout; t > 0 {
+		timeout = min(timeout, t)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	started := time.Now()
 	info, ok, err := forsale.Lookup(ctx, domain, lookup)
 	if err != nil || !ok {
+		traceStep(ctx, "for-sale", TraceLookup, "no _for-sale record", started, err)
 		return nil
 	}
+	traceStep(ctx, "for-sale", TraceLookup, "_for-sale record found", started, nil)
 	return &info
 }
 
+// checkDomain walks the backend chain. The first verdict wins; a backend with
+// no verdict passes to the next; any other failure stops the chain, since a
+// later backend guessing "available" would be worse than reporting an error.
 func (s *ResolverService) checkDomain(ctx context.Context, domain string) (CheckResult, error) {
 	if !validate.IsValidDomainOrKeyword(domain) {
 		return CheckResult{}, errors.New("invalid domain")
 	}
-
-	rdapResult, err := s.withRetry(ctx, func() (CheckResult, error) {
-		return s.checkRDAP(ctx, domain)
-	})
-	if err == nil {
-		return rdapResult, nil
+	if s.app.Config.Verify {
+		return s.verifyDomain(ctx, domain)
 	}
 
-	if strings.Contains(err.Error(), "No RDAP servers") {
-		// dns fallback
-		dnsResolved, _ := s.checkIfDNSResolves(ctx, domain)
+	chain := s.chainFor(domain)
+	traceStep(ctx, "", TraceChain, strings.Join(chain, " → "), time.Time{}, nil)
 
-		if dnsResolved {
-			return CheckResult{
-				Registered: true,
-				Details:    fmt.Sprintf("Domain %s has a DNS record, but RDAP is not available", domain),
-			}, nil
+	var reasons []string
+	var failures []error
+	for _, name := range chain {
+		backend, err := s.backend(name)
+		if err != nil {
+			return CheckResult{Details: "This domain has unknown status"}, err
+		}
+
+		started := time.Now()
+		lookupCtx, endLookup := s.telemetry.startLookup(ctx, name, domain)
+		result, err := backend.Check(lookupCtx, domain)
+		if err == nil {
+			endLookup(registeredOutcome(result.Registered), nil)
+			if result.Source == "" {
+				result.Source = name
+			}
+			traceStep(ctx, name, TraceVerdict, verdictDetail(result), started, nil)
+			return result, nil
 		}
 
-		whoisResult, whoisErr := s.checkWhois(ctx, domain)
-		if whoisErr == nil && whoisResult.Registered {
-			return whoisResult, nil
+		if ctx.Err() != nil {
+			endLookup(outcomeError, ctx.Err())
+			traceStep(ctx, name, TraceError, "", started, ctx.Err())
+			return CheckResult{}, ctx.Err()
+		}
+
+		var noVerdict *NoVerdictError
+		if errors.As(err, &noVerdict) {
+			endLookup(outcomeNoVerdict, nil)
+			traceStep(ctx, name, TraceNoVerdict, noVerdict.Reason, started, nil)
+			reasons = append(reasons, noVerdict.Reason)
+			if noVerdict.Err != nil {
+				failures = append(failures, fmt.Errorf("%s lookup failed: %w", name, noVerdict.Err))
+			}
+			continue
 		}
 
+		endLookup(outcomeError, err)
+		traceStep(ctx, name, TraceError, "stopping the chain", started, err)
 		return CheckResult{
 			Registered: false,
-			Details:    "No RDAP server available; DNS did not resolve; No WHOIS (likely available)",
-		}, nil
+			Details:    "This domain has unknown status",
+		}, fmt.Errorf("%s lookup failed: %w", name, err)
 	}
 
-	if ctx.Err() != nil {
-		return CheckResult{}, ctx.Err()
+	if len(failures) > 0 {
+		// A failed lookup might have found the name registered.
+		err := errors.Join(failures...)
+		traceStep(ctx, SourceFallback, TraceError, "no backend had a verdict", time.Time{}, err)
+		return CheckResult{Details: "This domain has unknown status"}, err
 	}
 
+	traceStep(ctx, SourceFallback, TraceVerdict, "no backend had a verdict; reporting likely available", time.Time{}, nil)
 	return CheckResult{
 		Registered: false,
-		Details:    "This domain has unknown status",
-	}, fmt.Errorf("checkRDAP failed: %w", err)
+		Details:    strings.Join(reasons, "; ") + " (likely available)",
+		Source:     SourceFallback,
+	}, nil
+}
+
+// verdictDetail describes a backend's verdict for the trace.
+func verdictDetail(result CheckResult) string {
+	verdict := "available"
+	if result.Registered {
+		verdict = "registered"
+	}
+	if result.Details == "" {
+		return verdict
+	}
+	return verdict + ": " + result.Details
 }
 
 func (s *ResolverService) checkRDAP(ctx context.Context, domain string) (CheckResult, error) {
@@ -282,6 +595,7 @@ func (s *ResolverService) checkRDAP(ctx context.Context, domain string) (CheckRe
 			return CheckResult{
 				Registered: false,
 				Details:    "RDAP is not found or doesn't exist",
+				Source:     SourceRDAP,
 			}, nil
 		}
 
@@ -295,25 +609,36 @@ func (s *ResolverService) checkRDAP(ctx context.Context, domain string) (CheckRe
 		return CheckResult{
 			Registered: false,
 			Details:    "No RDAP response available",
+			Source:     SourceRDAP,
 		}, nil
 	}
 
 	return CheckResult{
 		Registered: true,
 		Details:    fmt.Sprintf("Rdap registered: %s", domainResponse.Status),
+		Source:     SourceRDAP,
 	}, nil
 }
 
 func (s *ResolverService) checkIfDNSResolves(ctx context.Context, domain string) (bool, error) {
-	if s.dnsLookupFn != nil {
-		ips, err := s.dnsLookupFn(ctx, domain)
-		if err != nil {
-			return false, err
+	lookup := s.dnsLookupFn
+	if lookup == nil {
+		if s.resolverHijacksNXDOMAIN(ctx) {
+			traceStep(ctx, SourceDNS, TraceLookup, "the resolver answers for names that do not exist", time.Time{}, errDNSHijacked)
+			return false, errDNSHijacked
+		}
+		lookup = s.dnsResolver().LookupHost
+	}
+
+	started := time.Now()
+	ips, err := lookup(ctx, domain)
+	if tracing(ctx) {
+		detail := "A/AAAA " + domain
+		if err == nil {
+			detail += " → " + strings.Join(ips, ", ")
 		}
-		return len(ips) > 0, nil
+		traceStep(ctx, SourceDNS, TraceLookup, detail, started, err)
 	}
-	resolver := net.Resolver{}
-	ips, err := resolver.LookupHost(ctx, domain)
 	if err != nil {
 		return false, err
 	}
@@ -333,6 +658,7 @@ func (s *ResolverService) checkWhois(ctx context.Context, domain string) (CheckR
 		whoisFetch = s.whoisFn
 	}
 
+	started := time.Now()
 	go func() {
 		raw, err := whoisFetch(domain)
 		resultCh <- result{raw: raw, err: err}
@@ -343,6 +669,7 @@ func (s *ResolverService) checkWhois(ctx context.Context, domain string) (CheckR
 	case <-ctx.Done():
 		return CheckResult{Registered: false}, ctx.Err()
 	case res := <-resultCh:
+		traceStep(ctx, SourceWHOIS, TraceLookup, fmt.Sprintf("query %s (%d bytes)", domain, len(res.raw)), started, res.err)
 		if res.err != nil {
 			// Fallback: detect "not found" in raw whois text if err is nil but body says unregistered
 			if strings.Contains(strings.ToLower(res.err.Error()), "no whois server") {
@@ -365,6 +692,7 @@ func (s *ResolverService) checkWhois(ctx context.Context, domain string) (CheckR
 			return CheckResult{
 				Registered: false,
 				Details:    "Domain not registered (WHOIS says not found)",
+				Source:     SourceWHOIS,
 			}, nil
 		}
 
@@ -402,52 +730,75 @@ func (s *ResolverService) checkWhois(ctx context.Context, domain string) (CheckR
 	return CheckResult{
 		Registered: true,
 		Details:    details,
+		Source:     SourceWHOIS,
 	}, nil
 }
 
+// CheckDomainsStreaming checks specs concurrently and sends each result as it
+// comes in. With a prefilter, domains it saw taken before are reported
+// without a lookup, or checked after all others in defer mode.
 func (s *ResolverService) CheckDomainsStreaming(ctx context.Context, specs []DomainSpec) <-chan DomainResult {
 	resultChan := make(chan DomainResult)
 
 	go func() {
 		defer close(resultChan)
 
+		seq := newSequencer(resultChan, s.ordered)
 		var wg sync.WaitGroup
 		limit := s.app.Config.ConcurrencyLimit
 		if limit <= 0 {
 			limit = runtime.NumCPU()
 		}
-		sem := make(chan struct{}, limit)
+		lim := newLimiter(limit, s.app.Config.AdaptiveConcurrency)
 
-	loop:
-		for _, spec := range specs {
-			select {
-			case <-ctx.Done():
-				break loop
-			default:
+		dispatch := func(spec DomainSpec) bool {
+			if err := lim.acquire(ctx); err != nil {
+				return false
 			}
-
-			select {
-			case sem <- struct{}{}:
-			case <-ctx.Done():
-				break loop
+			// A slot freed by a cancelled lookup must not start another.
+			if ctx.Err() != nil {
+				lim.release(false)
+				return false
 			}
 			wg.Add(1)
+			ticket := seq.ticket()
 
 			go func() {
-				defer func() {
-					<-sem
-					wg.Done()
-				}()
+				var err error
+				var result *DomainResult
+				// Released before the result is settled, which may wait
+				// on earlier lookups in ordered mode.
+				defer wg.Done()
+				defer func() { seq.settle(ctx, ticket, result) }()
+				defer func() { lim.release(err != nil) }()
+
+				releaseTLD, err := s.acquireTLD(ctx, spec.Domain)
+				if err != nil {
+					return
+				}
+				defer releaseTLD()
 
-				checkCtx, cancel := context.WithTimeout(ctx, s.app.Config.ContextTimeout)
+				// The answers that came in while this spec waited may
+				// have made it unwanted.
+				if s.skipped(spec) {
+					return
+				}
+
+				checkCtx, cancel := context.WithTimeout(ctx, s.policyFor(spec.Domain).contextTimeout)
 				defer cancel()
+				var retries atomic.Int32
+				checkCtx = context.WithValue(checkCtx, retriesKey{}, &retries)
 
+				started := time.Now()
 				checkResult, err := s.CheckDomain(checkCtx, spec.Domain)
+				elapsed := time.Since(started)
+				if err == nil {
+					s.learnTaken(spec.Domain, checkResult)
+				}
 
-				select {
-				case resultChan <- DomainResult{
+				result = &DomainResult{
 					Domain:    spec.Domain,
-					Available: !checkResult.Registered,
+					Available: checkResult.Available(),
 					Details:   checkResult.Details,
 					Error:     err,
 					Keyword:   spec.Keyword,
@@ -455,25 +806,129 @@ func (s *ResolverService) CheckDomainsStreaming(ctx context.Context, specs []Dom
 					Suffix:    spec.Suffix,
 					TLD:       spec.TLD,
 					ForSale:   checkResult.ForSale,
-				}:
-				case <-ctx.Done():
-					// Context cancelled, don't send result
-					return
+					Source:    checkResult.Source,
+					Pricing:   checkResult.Pricing,
+					Reserved:  checkResult.Reserved,
+
+					Confidence: checkResult.Confidence,
+					Verdicts:   checkResult.Verdicts,
+
+					Duration: elapsed,
+					Retries:  int(retries.Load()),
 				}
 			}()
+			return true
+		}
+
+		var deferred []DomainSpec
+		stopped := false
+	loop:
+		for _, spec := range specs {
+			select {
+			case <-ctx.Done():
+				stopped = true
+				break loop
+			default:
+			}
+
+			if s.skipped(spec) {
+				continue
+			}
+			if s.prefiltered(spec.Domain) {
+				if s.app.Config.Prefilter.Mode == prefilter.ModeDefer {
+					deferred = append(deferred, spec)
+					continue
+				}
+				if !s.skipPrefiltered(ctx, seq, spec) {
+					stopped = true
+					break loop
+				}
+				continue
+			}
+
+			if !dispatch(spec) {
+				stopped = true
+				break loop
+			}
 		}
 
+		s.prefilterCounts.deferred.Add(int64(len(deferred)))
+		reached := 0
+		for _, spec := range deferred {
+			if stopped || ctx.Err() != nil {
+				break
+			}
+			if !s.skipped(spec) && !dispatch(spec) {
+				break
+			}
+			reached++
+		}
+		s.prefilterCounts.unreached.Add(int64(len(deferred) - reached))
+
 		wg.Wait()
 	}()
 
 	return resultChan
 }
 
-func (s ResolverService) QueryDomainContext(ctx context.Context, domain string) (*rdap.Domain, error) {
+func (s *ResolverService) skipped(spec DomainSpec) bool {
+	return s.skip != nil && s.skip(spec)
+}
+
+// traceRDAP records which servers bootstrap chose and how each answered.
+func traceRDAP(ctx context.Context, resp *rdap.Response) {
+	if resp == nil || !tracing(ctx) {
+		return
+	}
+
+	if answer := resp.BootstrapAnswer; answer != nil {
+		urls := make([]string, 0, len(answer.URLs))
+		for _, u := range answer.URLs {
+			urls = append(urls, u.String())
+		}
+		detail := fmt.Sprintf("%s → %s", answer.Entry, strings.Join(urls, ", "))
+		if answer.Entry == "" {
+			detail = "no RDAP service registered for " + answer.Query
+		}
+		traceStep(ctx, SourceRDAP, TraceBootstrap, detail, time.Time{}, nil)
+	}
+
+	for _, h := range resp.HTTP {
+		detail := "GET " + h.URL
+		if h.Response != nil {
+			detail += " → " + h.Response.Status
+		}
+		traceStep(ctx, SourceRDAP, TraceRequest, detail, time.Now().Add(-h.Duration), h.Error)
+	}
+}
+
+// NewRDAPClient returns the RDAP client used when no querier is injected. Its
+// bootstrap lookups go through client too.
+func NewRDAPClient(client *http.Client) *rdap.Client {
+	return &rdap.Client{
+		Bootstrap: &bootstrap.Client{
+			HTTP: client,
+		},
+		HTTP: client,
+	}
+}
+
+// NewWhoisFetcher returns the WHOIS fetch function used when none is
+// injected, dialing through the configured proxy.
+func NewWhoisFetcher(opts config.TransportOptions) func(string, ...string) (string, error) {
+	dialer, err := transport.NewWhoisDialer(opts)
+	if err != nil {
+		slog.Warn("Invalid WHOIS proxy, connecting directly", "error", err)
+		dialer, _ = transport.NewWhoisDialer(config.TransportOptions{})
+	}
+	return whois.NewClient().SetDialer(dialer).Whois
+}
+
+func (s *ResolverService) QueryDomainContext(ctx context.Context, domain string) (*rdap.Domain, error) {
 	req := &rdap.Request{
 		Type:    rdap.DomainRequest,
 		Query:   domain,
-		Timeout: s.app.Config.ContextTimeout,
+		Timeout: s.policyFor(domain).contextTimeout,
 	}
 
 	req = req.WithContext(ctx)
@@ -482,15 +937,11 @@ func (s ResolverService) QueryDomainContext(ctx context.Context, domain string)
 	if s.rdapQuerier != nil {
 		q = s.rdapQuerier
 	} else {
-		q = &rdap.Client{
-			Bootstrap: &bootstrap.Client{
-				HTTP: s.httpClient,
-			},
-			HTTP: s.httpClient,
-		}
+		q = NewRDAPClient(s.httpClient)
 	}
 
 	resp, err := q.Do(req)
+	traceRDAP(ctx, resp)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch RDAP data: %w", err)
@@ -31,7 +31,7 @@ func TestWithRetry_StopsWhenTheContextIsCancelledDuringBackoff(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	attempts := 0
-	_, err := svc.withRetry(ctx, func() (CheckResult, error) {
+	_, err := svc.withRetry(ctx, "", func() (CheckResult, error) {
 		attempts++
 		cancel()
 		return CheckResult{}, errors.New("i/o timeout")
@@ -51,7 +51,7 @@ func TestWithRetry_NoAttemptsConfigured(t *testing.T) {
 
 	svc := NewResolverService(app)
 
-	result, err := svc.withRetry(context.Background(), func() (CheckResult, error) {
+	result, err := svc.withRetry(context.Background(), "", func() (CheckResult, error) {
 		t.Fatal("the function must not be called when no attempt is allowed")
 		return CheckResult{}, nil
 	})
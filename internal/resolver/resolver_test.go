@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -376,6 +378,43 @@ func TestWithRetry_RetryOnRetryableError(t *testing.T) {
 	}
 }
 
+func TestCheckDomainsStreaming_CountsRetriesAndTime(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.MaxRetries = 2
+	app.Config.InitialBackoff = 1 * time.Millisecond
+	app.Config.MaxBackoff = 5 * time.Millisecond
+	app.Config.BackoffFactor = 2.0
+
+	var attempts atomic.Int32
+	mock := &mockRDAPQuerierFunc{
+		fn: func(_ *rdap.Request) (*rdap.Response, error) {
+			if attempts.Add(1) <= 2 {
+				return nil, fmt.Errorf("connection timeout")
+			}
+			return nil, fmt.Errorf("object does not exist.")
+		},
+	}
+
+	s := resolver.NewResolverService(app, resolver.WithRDAPQuerier(mock))
+	var results []resolver.DomainResult
+	for result := range s.CheckDomainsStreaming(context.Background(), []resolver.DomainSpec{{Domain: "retry-test.com"}}) {
+		results = append(results, result)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].Error != nil {
+		t.Errorf("Expected no error after retries, got: %v", results[0].Error)
+	}
+	if results[0].Retries != 2 {
+		t.Errorf("Expected 2 retries, got %d", results[0].Retries)
+	}
+	if results[0].Duration <= 0 {
+		t.Errorf("Expected the lookup to be timed, got %s", results[0].Duration)
+	}
+}
+
 func TestCheckDomain_ForSale(t *testing.T) {
 	registered := &mockRDAPQuerier{resp: makeDomainRDAPResponse()}
 	notFound := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
@@ -517,3 +556,157 @@ func TestCheckDomain_ForSale(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckDomain_DNSPrescreen(t *testing.T) {
+	notFound := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
+
+	delegated := func(_ context.Context, _ string) (bool, error) { return true, nil }
+	undelegated := func(_ context.Context, _ string) (bool, error) { return false, nil }
+
+	t.Run("a delegated name is taken without asking RDAP", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.DNSPrescreen = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithRDAPQuerier(&mockRDAPQuerierFunc{fn: func(*rdap.Request) (*rdap.Response, error) {
+				t.Fatal("RDAP must not be queried for a delegated name")
+				return nil, nil
+			}}),
+			resolver.WithDelegationLookup(delegated),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "taken-domain.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered {
+			t.Error("Expected a delegated domain to be registered")
+		}
+		if result.Source != resolver.SourceDelegation {
+			t.Errorf("Expected source %q, got %q", resolver.SourceDelegation, result.Source)
+		}
+	})
+
+	t.Run("an undelegated name is confirmed over RDAP", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.DNSPrescreen = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithRDAPQuerier(notFound),
+			resolver.WithDelegationLookup(undelegated),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "available-domain.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Registered {
+			t.Error("Expected the domain to be available")
+		}
+		if result.Source != resolver.SourceRDAP {
+			t.Errorf("Expected source %q, got %q", resolver.SourceRDAP, result.Source)
+		}
+	})
+
+	t.Run("a failed pre-screen falls through to RDAP", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.DNSPrescreen = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithRDAPQuerier(&mockRDAPQuerier{resp: makeDomainRDAPResponse()}),
+			resolver.WithDelegationLookup(func(_ context.Context, _ string) (bool, error) {
+				return false, errors.New("i/o timeout")
+			}),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "taken-domain.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered || result.Source != resolver.SourceRDAP {
+			t.Errorf("Expected an RDAP-registered verdict, got %+v", result)
+		}
+	})
+
+	t.Run("a failed pre-screen with no other verdict is an error, not undelegated", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.Backends = []string{resolver.SourceDelegation}
+
+		s := resolver.NewResolverService(app,
+			resolver.WithDelegationLookup(func(_ context.Context, _ string) (bool, error) {
+				return false, errors.New("i/o timeout")
+			}),
+		)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err == nil {
+			t.Fatalf("Expected an error, got %+v", result)
+		}
+		if !strings.Contains(err.Error(), "i/o timeout") {
+			t.Errorf("Expected the lookup failure in the error, got: %v", err)
+		}
+		if strings.Contains(result.Details, "Not delegated") || result.Source == resolver.SourceFallback {
+			t.Errorf("Expected no not-delegated fallback verdict, got %+v", result)
+		}
+	})
+
+	t.Run("an undelegated name with no other verdict is likely available", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.Backends = []string{resolver.SourceDelegation}
+
+		s := resolver.NewResolverService(app, resolver.WithDelegationLookup(undelegated))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Source != resolver.SourceFallback || !strings.Contains(result.Details, "Not delegated") {
+			t.Errorf("Expected a not-delegated fallback verdict, got %+v", result)
+		}
+	})
+
+	t.Run("does nothing when the flag is off", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+
+		called := false
+		s := resolver.NewResolverService(app,
+			resolver.WithRDAPQuerier(notFound),
+			resolver.WithDelegationLookup(func(ctx context.Context, domain string) (bool, error) {
+				called = true
+				return delegated(ctx, domain)
+			}),
+		)
+
+		result, _ := s.CheckDomain(context.Background(), "available-domain.com")
+		if called {
+			t.Error("Expected no pre-screen when --dns-prescreen is off")
+		}
+		if result.Registered {
+			t.Error("Expected the RDAP verdict to stand")
+		}
+	})
+
+	t.Run("streaming results carry the source", func(t *testing.T) {
+		app := config.NewTldxContext()
+		app.Config.MaxRetries = 0
+		app.Config.DNSPrescreen = true
+
+		s := resolver.NewResolverService(app,
+			resolver.WithRDAPQuerier(notFound),
+			resolver.WithDelegationLookup(delegated),
+		)
+
+		specs := []resolver.DomainSpec{{Domain: "taken-domain.com", Keyword: "taken", TLD: "com"}}
+		for result := range s.CheckDomainsStreaming(context.Background(), specs) {
+			if got := result.AsEncodable().Source; got != resolver.SourceDelegation {
+				t.Errorf("Expected source %q on the streamed result, got %q", resolver.SourceDelegation, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package resolver_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/prefilter"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+// takenSet answers registered for the domains in it and records every call.
+type takenSet struct {
+	mu    sync.Mutex
+	taken []string
+	calls []string
+}
+
+func (b *takenSet) Check(_ context.Context, domain string) (resolver.CheckResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, domain)
+	return resolver.CheckResult{Registered: slices.Contains(b.taken, domain), Source: "rdap"}, nil
+}
+
+func specsFor(domains ...string) []resolver.DomainSpec {
+	specs := make([]resolver.DomainSpec, 0, len(domains))
+	for _, d := range domains {
+		specs = append(specs, resolver.DomainSpec{Domain: d})
+	}
+	return specs
+}
+
+func schedulingApp(mode string) *config.TldxContext {
+	app := config.NewTldxContext()
+	app.Config.ConcurrencyLimit = 1
+	app.Config.Reserved.Disabled = true
+	app.Config.Prefilter.Mode = mode
+	return app
+}
+
+func TestCheckDomainsStreaming_PrefilterLearnsAndSkips(t *testing.T) {
+	filter := prefilter.New(100, 0.01)
+	backend := &takenSet{taken: []string{"acme.com"}}
+
+	first := resolver.NewResolverService(schedulingApp(prefilter.ModeSkip),
+		resolver.WithBackend("rdap", backend), resolver.WithPrefilter(filter))
+	for range first.CheckDomainsStreaming(context.Background(), specsFor("acme.com", "free.com")) {
+	}
+	if stats := first.PrefilterStats(); stats.Learned != 1 || stats.Saved != 0 {
+		t.Fatalf("expected the first run to learn acme.com only, got %+v", stats)
+	}
+
+	backend.calls = nil
+	second := resolver.NewResolverService(schedulingApp(prefilter.ModeSkip),
+		resolver.WithBackend("rdap", backend), resolver.WithPrefilter(filter))
+	var results []resolver.DomainResult
+	for r := range second.CheckDomainsStreaming(context.Background(), specsFor("acme.com", "free.com")) {
+		results = append(results, r)
+	}
+
+	if !slices.Equal(backend.calls, []string{"free.com"}) {
+		t.Errorf("expected only free.com to be looked up, got %v", backend.calls)
+	}
+	if results[0].Domain != "acme.com" || results[0].Available || results[0].Source != resolver.SourcePrefilter ||
+		results[0].Details != "Probably taken (prefilter)" {
+		t.Errorf("expected acme.com reported probably taken by the prefilter, got %+v", results[0])
+	}
+	if stats := second.PrefilterStats(); stats.Skipped != 1 || stats.Saved != 1 || stats.Learned != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// conflictingBackend finds every domain registered, but with its sources in
+// disagreement, as --verify reports it.
+type conflictingBackend struct{}
+
+func (conflictingBackend) Check(context.Context, string) (resolver.CheckResult, error) {
+	return resolver.CheckResult{Registered: true, Source: "rdap", Confidence: resolver.ConfidenceConflicting}, nil
+}
+
+func TestCheckDomainsStreaming_PrefilterLearnsOnlyConfirmedTaken(t *testing.T) {
+	filter := prefilter.New(100, 0.01)
+	s := resolver.NewResolverService(schedulingApp(prefilter.ModeSkip),
+		resolver.WithBackend("rdap", conflictingBackend{}), resolver.WithPrefilter(filter))
+	for range s.CheckDomainsStreaming(context.Background(), specsFor("acme.com")) {
+	}
+
+	if filter.Has("acme.com") || s.PrefilterStats().Learned != 0 {
+		t.Errorf("a domain the sources disagree on must not be learned, got %+v", s.PrefilterStats())
+	}
+}
+
+func TestCheckDomainsStreaming_PrefilterDefers(t *testing.T) {
+	filter := prefilter.New(100, 0.01)
+	filter.Add("acme.com")
+	backend := &takenSet{taken: []string{"acme.com"}}
+
+	s := resolver.NewResolverService(schedulingApp(prefilter.ModeDefer),
+		resolver.WithBackend("rdap", backend), resolver.WithPrefilter(filter))
+	for range s.CheckDomainsStreaming(context.Background(), specsFor("acme.com", "free.com", "other.com")) {
+	}
+
+	if !slices.Equal(backend.calls, []string{"free.com", "other.com", "acme.com"}) {
+		t.Errorf("expected acme.com to be checked last, got %v", backend.calls)
+	}
+	if stats := s.PrefilterStats(); stats.Deferred != 1 || stats.Saved != 0 {
+		t.Errorf("a deferred domain that was reached saves nothing, got %+v", stats)
+	}
+}
+
+// blockingBackend holds every lookup until its context ends.
+type blockingBackend struct{ started chan struct{} }
+
+func (b blockingBackend) Check(ctx context.Context, _ string) (resolver.CheckResult, error) {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return resolver.CheckResult{}, ctx.Err()
+}
+
+func TestCheckDomainsStreaming_DeferredDomainsCountAsSavedWhenCutShort(t *testing.T) {
+	filter := prefilter.New(100, 0.01)
+	filter.Add("acme.com")
+	backend := blockingBackend{started: make(chan struct{}, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := resolver.NewResolverService(schedulingApp(prefilter.ModeDefer),
+		resolver.WithBackend("rdap", backend), resolver.WithPrefilter(filter))
+	results := s.CheckDomainsStreaming(ctx, specsFor("acme.com", "free.com"))
+	<-backend.started
+	// As --limit does once it has enough.
+	cancel()
+	for range results {
+	}
+
+	if stats := s.PrefilterStats(); stats.Deferred != 1 || stats.Saved != 1 {
+		t.Errorf("expected the unreached deferred domain to count as saved, got %+v", stats)
+	}
+}
+
+func TestCheckDomainsStreaming_Skip(t *testing.T) {
+	backend := &takenSet{}
+	s := resolver.NewResolverService(schedulingApp(""),
+		resolver.WithBackend("rdap", backend),
+		resolver.WithSkip(func(spec resolver.DomainSpec) bool { return spec.Domain == "b.com" }))
+
+	var domains []string
+	for r := range s.CheckDomainsStreaming(context.Background(), specsFor("a.com", "b.com", "c.com")) {
+		domains = append(domains, r.Domain)
+	}
+
+	if !slices.Equal(domains, []string{"a.com", "c.com"}) || !slices.Equal(backend.calls, domains) {
+		t.Errorf("expected b.com to be neither looked up nor reported, got results %v and calls %v", domains, backend.calls)
+	}
+}
+
+// reversingBackend finishes its lookups in the reverse of the given order:
+// each waits for the one after it.
+type reversingBackend struct {
+	order []string
+	done  map[string]chan struct{}
+}
+
+func newReversingBackend(order ...string) *reversingBackend {
+	b := &reversingBackend{order: order, done: make(map[string]chan struct{})}
+	for _, domain := range order {
+		b.done[domain] = make(chan struct{})
+	}
+	return b
+}
+
+func (b *reversingBackend) Check(ctx context.Context, domain string) (resolver.CheckResult, error) {
+	i := slices.Index(b.order, domain)
+	if i+1 < len(b.order) {
+		select {
+		case <-b.done[b.order[i+1]]:
+		case <-ctx.Done():
+			return resolver.CheckResult{}, ctx.Err()
+		}
+	}
+	close(b.done[domain])
+	return resolver.CheckResult{Source: "rdap"}, nil
+}
+
+func TestCheckDomainsStreaming_OrderedResults(t *testing.T) {
+	filter := prefilter.New(100, 0.01)
+	filter.Add("e.com")
+	app := schedulingApp(prefilter.ModeSkip)
+	app.Config.ConcurrencyLimit = 4
+
+	s := resolver.NewResolverService(app,
+		resolver.WithBackend("rdap", newReversingBackend("a.com", "c.com", "d.com")),
+		resolver.WithPrefilter(filter),
+		resolver.WithSkip(func(spec resolver.DomainSpec) bool { return spec.Domain == "b.com" }),
+		resolver.WithOrderedResults())
+
+	var domains []string
+	for r := range s.CheckDomainsStreaming(context.Background(), specsFor("a.com", "b.com", "c.com", "e.com", "d.com")) {
+		domains = append(domains, r.Domain)
+	}
+
+	if !slices.Equal(domains, []string{"a.com", "c.com", "e.com", "d.com"}) {
+		t.Errorf("expected results in spec order, without the skipped b.com, got %v", domains)
+	}
+}
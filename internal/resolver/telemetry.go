@@ -0,0 +1,153 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer and meter the resolver reports to.
+const instrumentationName = "github.com/brandonyoungdev/tldx/internal/resolver"
+
+// Lookup outcomes, recorded on each backend lookup's span and metrics.
+const (
+	outcomeRegistered = "registered"
+	outcomeAvailable  = "available"
+	outcomeNoVerdict  = "no-verdict"
+	outcomeError      = "error"
+)
+
+// WithTracerProvider reports spans to tp instead of the global provider.
+func WithTracerProvider(tp trace.TracerProvider) ResolverOption {
+	return func(s *ResolverService) { s.tracerProvider = tp }
+}
+
+// WithMeterProvider reports metrics to mp instead of the global provider.
+func WithMeterProvider(mp metric.MeterProvider) ResolverOption {
+	return func(s *ResolverService) { s.meterProvider = mp }
+}
+
+// telemetry is the resolver's OpenTelemetry instrumentation: a span per
+// domain checked and per backend lookup, and these metrics:
+//
+//	tldx.lookups          lookups by backend, registry and outcome
+//	tldx.lookup.duration  lookup latency in seconds, by backend and registry
+//	tldx.lookup.errors    failed lookups by backend, registry and category
+//	tldx.lookup.retries   retried attempts by registry
+//
+// A registry is the zone a domain is registered under, e.g. "com" or
+// "co.uk". Without a provider configured, the global no-op ones make all of
+// it free.
+type telemetry struct {
+	tracer   trace.Tracer
+	lookups  metric.Int64Counter
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	retries  metric.Int64Counter
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+	// The meter hands back a working no-op instrument along with any error,
+	// so a bad one is only worth a warning.
+	var errs [4]error
+	t.lookups, errs[0] = meter.Int64Counter("tldx.lookups",
+		metric.WithDescription("Availability lookups by backend, registry and outcome"))
+	t.duration, errs[1] = meter.Float64Histogram("tldx.lookup.duration", metric.WithUnit("s"),
+		metric.WithDescription("Time a backend lookup took, retries included"))
+	t.errors, errs[2] = meter.Int64Counter("tldx.lookup.errors",
+		metric.WithDescription("Failed availability lookups by backend, registry and error category"))
+	t.retries, errs[3] = meter.Int64Counter("tldx.lookup.retries",
+		metric.WithDescription("Lookup attempts retried after a transient error"))
+	if err := errors.Join(errs[:]...); err != nil {
+		slog.Warn("Some lookup metrics are unavailable", "error", err)
+	}
+	return t
+}
+
+// registryOf is the zone domain is registered under, or domain itself for a
+// bare TLD.
+func registryOf(domain string) string {
+	if zone, ok := parentZone(domain); ok {
+		return zone
+	}
+	return domain
+}
+
+// startCheck starts the span of checking one domain. end records the verdict.
+func (t *telemetry) startCheck(ctx context.Context, domain string) (context.Context, func(CheckResult, error)) {
+	ctx, span := t.tracer.Start(ctx, "tldx.check", trace.WithAttributes(
+		attribute.String("tldx.domain", domain),
+		attribute.String("tldx.registry", registryOf(domain)),
+	))
+	return ctx, func(result CheckResult, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(
+				attribute.Bool("tldx.available", result.Available()),
+				attribute.String("tldx.source", result.Source),
+			)
+		}
+		span.End()
+	}
+}
+
+// startLookup starts the span of one backend's lookup of domain. end records
+// its outcome, one of the outcome* constants, and its metrics.
+func (t *telemetry) startLookup(ctx context.Context, backend, domain string) (context.Context, func(outcome string, err error)) {
+	registry := registryOf(domain)
+	ctx, span := t.tracer.Start(ctx, "tldx.lookup "+backend, trace.WithAttributes(
+		attribute.String("tldx.backend", backend),
+		attribute.String("tldx.domain", domain),
+		attribute.String("tldx.registry", registry),
+	))
+	started := time.Now()
+
+	return ctx, func(outcome string, err error) {
+		attrs := []attribute.KeyValue{
+			attribute.String("backend", backend),
+			attribute.String("registry", registry),
+		}
+		t.duration.Record(ctx, time.Since(started).Seconds(), metric.WithAttributes(attrs...))
+		t.lookups.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("outcome", outcome))...))
+		span.SetAttributes(attribute.String("tldx.outcome", outcome))
+		if err != nil {
+			category := ErrorCategory(err)
+			t.errors.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("category", category))...))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.SetAttributes(attribute.String("tldx.error.category", category))
+		}
+		span.End()
+	}
+}
+
+func registeredOutcome(registered bool) string {
+	if registered {
+		return outcomeRegistered
+	}
+	return outcomeAvailable
+}
+
+// retried counts a retried attempt at a lookup of domain.
+func (t *telemetry) retried(ctx context.Context, domain string) {
+	trace.SpanFromContext(ctx).AddEvent("retry")
+	t.retries.Add(ctx, 1, metric.WithAttributes(attribute.String("registry", registryOf(domain))))
+}
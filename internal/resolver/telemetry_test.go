@@ -0,0 +1,143 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/openrdap/rdap"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newInstrumented returns a service reporting to in-memory exporters.
+func newInstrumented(t *testing.T, app *config.TldxContext, opts ...resolver.ResolverOption) (*resolver.ResolverService, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	opts = append(opts,
+		resolver.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))),
+		resolver.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	return resolver.NewResolverService(app, opts...), spans, reader
+}
+
+// sums returns the data points of the counter called name, keyed by their
+// attributes.
+func sums(t *testing.T, reader *sdkmetric.ManualReader, name string) map[attribute.Distinct]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	points := map[attribute.Distinct]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				points[dp.Attributes.Equivalent()] = dp.Value
+			}
+		}
+	}
+	return points
+}
+
+func key(kvs ...attribute.KeyValue) attribute.Distinct {
+	set := attribute.NewSet(kvs...)
+	return set.Equivalent()
+}
+
+func TestTelemetry_SpansPerCheckAndLookup(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"whois", "rdap"}
+
+	s, spans, _ := newInstrumented(t, app,
+		resolver.WithBackend("whois", silent("No WHOIS")),
+		resolver.WithBackend("rdap", taken()),
+	)
+	if _, err := s.CheckDomain(context.Background(), "example.com"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	got := spans.GetSpans()
+	if len(got) != 3 {
+		t.Fatalf("Expected a check span and two lookup spans, got %d", len(got))
+	}
+	// Spans end innermost first.
+	names := []string{got[0].Name, got[1].Name, got[2].Name}
+	want := []string{"tldx.lookup whois", "tldx.lookup rdap", "tldx.check"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Expected spans %v, got %v", want, names)
+		}
+	}
+	check := got[2]
+	for _, lookup := range got[:2] {
+		if lookup.Parent.SpanID() != check.SpanContext.SpanID() {
+			t.Errorf("Expected %s to be a child of the check", lookup.Name)
+		}
+	}
+	for _, kv := range check.Attributes {
+		if kv.Key == "tldx.source" && kv.Value.AsString() != "rdap" {
+			t.Errorf("Expected the check to record the rdap verdict, got %q", kv.Value.AsString())
+		}
+	}
+}
+
+func TestTelemetry_LookupMetrics(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"rdap"}
+
+	s, _, reader := newInstrumented(t, app,
+		resolver.WithBackend("rdap", &fakeBackend{err: errors.New("read: i/o timeout")}),
+	)
+	s.CheckDomain(context.Background(), "example.co.uk") //nolint:errcheck
+	s.CheckDomain(context.Background(), "other.co.uk")   //nolint:errcheck
+
+	lookups := sums(t, reader, "tldx.lookups")
+	want := key(attribute.String("backend", "rdap"), attribute.String("registry", "co.uk"), attribute.String("outcome", "error"))
+	if lookups[want] != 2 {
+		t.Errorf("Expected 2 failed rdap lookups for co.uk, got %v", lookups)
+	}
+
+	errs := sums(t, reader, "tldx.lookup.errors")
+	want = key(attribute.String("backend", "rdap"), attribute.String("registry", "co.uk"), attribute.String("category", resolver.ErrorTimeout))
+	if errs[want] != 2 {
+		t.Errorf("Expected 2 timeouts for co.uk, got %v", errs)
+	}
+}
+
+func TestTelemetry_Retries(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.MaxRetries = 1
+	app.Config.InitialBackoff = time.Millisecond
+	app.Config.MaxBackoff = time.Millisecond
+	app.Config.BackoffFactor = 1
+
+	attempts := 0
+	s, _, reader := newInstrumented(t, app, resolver.WithRDAPQuerier(&mockRDAPQuerierFunc{
+		fn: func(_ *rdap.Request) (*rdap.Response, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("connection timeout")
+			}
+			return nil, errors.New("object does not exist.")
+		},
+	}))
+	if _, err := s.CheckDomain(context.Background(), "retry-test.com"); err != nil {
+		t.Fatalf("Expected no error after the retry, got: %v", err)
+	}
+
+	retries := sums(t, reader, "tldx.lookup.retries")
+	if got := retries[key(attribute.String("registry", "com"))]; got != 1 {
+		t.Errorf("Expected 1 retry for com, got %v", retries)
+	}
+}
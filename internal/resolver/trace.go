@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Trace records the steps of a lookup for "tldx explain". Attach one to the
+// context with WithTrace; without it the resolver records nothing.
+type Trace struct {
+	start time.Time
+
+	mu    sync.Mutex
+	steps []TraceStep
+}
+
+// TraceStep is one thing the resolver did. At is when it finished, measured
+// from the start of the trace; Duration is set for steps that took time.
+type TraceStep struct {
+	At       time.Duration
+	Source   string
+	Event    string
+	Detail   string
+	Duration time.Duration
+	Err      error
+}
+
+// Trace events.
+const (
+	TraceChain     = "chain"
+	TraceBootstrap = "bootstrap"
+	TraceRequest   = "request"
+	TraceAttempt   = "attempt"
+	TraceBackoff   = "backoff"
+	TraceLookup    = "lookup"
+	TraceNoVerdict = "no-verdict"
+	TraceVerdict   = "verdict"
+	TraceError     = "error"
+)
+
+func NewTrace() *Trace {
+	return &Trace{start: time.Now()}
+}
+
+// Steps returns the steps recorded so far, in the order they finished.
+func (t *Trace) Steps() []TraceStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.steps)
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context under which lookups are recorded to t.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// traceStep records a step if ctx carries a trace. started is when the step
+// began, or the zero time for an instant step.
+func traceStep(ctx context.Context, source, event, detail string, started time.Time, err error) {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	if t == nil {
+		return
+	}
+
+	now := time.Now()
+	step := TraceStep{At: now.Sub(t.start), Source: source, Event: event, Detail: detail, Err: err}
+	if !started.IsZero() {
+		step.Duration = now.Sub(started)
+	}
+
+	t.mu.Lock()
+	t.steps = append(t.steps, step)
+	t.mu.Unlock()
+}
+
+// tracing reports whether ctx carries a trace, for steps that are costly to
+// describe.
+func tracing(ctx context.Context) bool {
+	_, ok := ctx.Value(traceKey{}).(*Trace)
+	return ok
+}
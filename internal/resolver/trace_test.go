@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/openrdap/rdap"
+	"github.com/openrdap/rdap/bootstrap"
+)
+
+// flakyRDAP times out once, then answers like a real client would, with the
+// bootstrap answer and the HTTP exchange attached.
+type flakyRDAP struct{ calls int }
+
+func (f *flakyRDAP) Do(_ *rdap.Request) (*rdap.Response, error) {
+	f.calls++
+	if f.calls == 1 {
+		return nil, errors.New("i/o timeout")
+	}
+	server, _ := url.Parse("https://rdap.example.test/")
+	return &rdap.Response{
+		Object: &rdap.Domain{LDHName: "acme.test"},
+		BootstrapAnswer: &bootstrap.Answer{
+			Query: "acme.test",
+			Entry: "test",
+			URLs:  []*url.URL{server},
+		},
+		HTTP: []*rdap.HTTPResponse{{
+			URL:      "https://rdap.example.test/domain/acme.test",
+			Response: &http.Response{Status: "200 OK", StatusCode: http.StatusOK},
+			Duration: 3 * time.Millisecond,
+		}},
+	}, nil
+}
+
+func TestTrace_RecordsTheLookup(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{SourceRDAP}
+	app.Config.MaxRetries = 1
+	app.Config.InitialBackoff = time.Millisecond
+	svc := NewResolverService(app, WithRDAPQuerier(&flakyRDAP{}))
+
+	trace := NewTrace()
+	result, err := svc.CheckDomain(WithTrace(context.Background(), trace), "acme.test")
+	if err != nil || !result.Registered {
+		t.Fatalf("expected a registered verdict, got %+v, %v", result, err)
+	}
+
+	var events []string
+	for _, step := range trace.Steps() {
+		events = append(events, step.Event)
+	}
+	want := []string{TraceChain, TraceAttempt, TraceBackoff, TraceBootstrap, TraceRequest, TraceAttempt, TraceVerdict}
+	if strings.Join(events, " ") != strings.Join(want, " ") {
+		t.Fatalf("events: got %v, want %v", events, want)
+	}
+
+	steps := trace.Steps()
+	if steps[1].Err == nil {
+		t.Error("the failed attempt must carry its error")
+	}
+	if !strings.Contains(steps[3].Detail, "rdap.example.test") {
+		t.Errorf("the bootstrap step must name the server, got %q", steps[3].Detail)
+	}
+	if !strings.Contains(steps[4].Detail, "200 OK") || steps[4].Duration < 3*time.Millisecond {
+		t.Errorf("the request step must carry status and latency, got %+v", steps[4])
+	}
+	if last := steps[len(steps)-1]; last.Source != SourceRDAP {
+		t.Errorf("the verdict must name its source, got %+v", last)
+	}
+	for i := 1; i < len(steps); i++ {
+		if steps[i].At < steps[i-1].At {
+			t.Errorf("steps must be in the order they finished: %+v before %+v", steps[i-1], steps[i])
+		}
+	}
+}
+
+func TestTrace_NothingRecordedWithoutATrace(t *testing.T) {
+	if tracing(context.Background()) {
+		t.Error("a bare context must not trace")
+	}
+	// Must not panic.
+	traceStep(context.Background(), SourceDNS, TraceLookup, "", time.Now(), nil)
+}
@@ -0,0 +1,253 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+// retryPolicy is the effective retry and timeout settings for one domain.
+type retryPolicy struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	backoffFactor  float64
+	contextTimeout time.Duration
+}
+
+// tldTuning returns the most specific per-TLD override for domain, walking
+// parent zones the way chainFor does.
+func (s *ResolverService) tldTuning(domain string) (zone string, t config.Tuning, ok bool) {
+	for zone, found := parentZone(domain); found; zone, found = parentZone(zone) {
+		if t, ok := s.app.Config.TLDTuning[zone]; ok {
+			return zone, t, true
+		}
+	}
+	return "", config.Tuning{}, false
+}
+
+func (s *ResolverService) policyFor(domain string) retryPolicy {
+	cfg := s.app.Config
+	p := retryPolicy{
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		backoffFactor:  cfg.BackoffFactor,
+		contextTimeout: cfg.ContextTimeout,
+	}
+
+	_, t, ok := s.tldTuning(domain)
+	if !ok {
+		return p
+	}
+	if t.MaxRetries != nil {
+		p.maxRetries = *t.MaxRetries
+	}
+	if t.InitialBackoff != nil {
+		p.initialBackoff = *t.InitialBackoff
+	}
+	if t.MaxBackoff != nil {
+		p.maxBackoff = *t.MaxBackoff
+	}
+	if t.BackoffFactor != nil {
+		p.backoffFactor = *t.BackoffFactor
+	}
+	if t.ContextTimeout != nil {
+		p.contextTimeout = *t.ContextTimeout
+	}
+	return p
+}
+
+// LookupTimeout is the time allowed for checking domain, retries included,
+// after any per-TLD override. CheckDomainsStreaming applies it to each
+// lookup; a caller of CheckDomain applies it itself.
+func (s *ResolverService) LookupTimeout(domain string) time.Duration {
+	return s.policyFor(domain).contextTimeout
+}
+
+// acquireTLD takes a slot from the domain's per-TLD limit, if it has one.
+// The returned func releases it.
+func (s *ResolverService) acquireTLD(ctx context.Context, domain string) (func(), error) {
+	zone, t, ok := s.tldTuning(domain)
+	if !ok || t.ConcurrencyLimit == nil || *t.ConcurrencyLimit <= 0 {
+		return func() {}, nil
+	}
+
+	s.tldSemsMu.Lock()
+	if s.tldSems == nil {
+		s.tldSems = make(map[string]chan struct{})
+	}
+	sem, found := s.tldSems[zone]
+	if !found {
+		sem = make(chan struct{}, *t.ConcurrencyLimit)
+		s.tldSems[zone] = sem
+	}
+	s.tldSemsMu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Adaptive concurrency is additive-increase, multiplicative-decrease: after
+// every adaptiveWindow lookups, a failure rate above adaptiveBackOff halves
+// the limit, and one below adaptiveRecover raises it by one.
+const (
+	adaptiveWindow  = 20
+	adaptiveBackOff = 0.2
+	adaptiveRecover = 0.05
+)
+
+// limiter bounds the number of lookups in flight. With adaptive set, the
+// bound moves between 1 and max depending on how many lookups fail.
+type limiter struct {
+	mu       sync.Mutex
+	limit    int
+	max      int
+	adaptive bool
+	inFlight int
+	// wake is closed, and replaced, whenever a slot may have opened.
+	wake chan struct{}
+
+	done, failed int
+}
+
+func newLimiter(max int, adaptive bool) *limiter {
+	return &limiter{limit: max, max: max, adaptive: adaptive, wake: make(chan struct{})}
+}
+
+func (l *limiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot and records whether the lookup failed.
+func (l *limiter) release(failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if l.adaptive {
+		l.done++
+		if failed {
+			l.failed++
+		}
+		if l.done >= adaptiveWindow {
+			l.adjust()
+		}
+	}
+
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// adjust applies the window's failure rate. Callers hold l.mu.
+func (l *limiter) adjust() {
+	rate := float64(l.failed) / float64(l.done)
+	previous := l.limit
+
+	switch {
+	case rate > adaptiveBackOff:
+		l.limit = max(1, l.limit/2)
+	case rate < adaptiveRecover && l.limit < l.max:
+		l.limit++
+	}
+	if l.limit != previous {
+		slog.Debug("Adjusted lookup concurrency", "from", previous, "to", l.limit, "failure_rate", rate)
+	}
+
+	l.done, l.failed = 0, 0
+}
+
+func (l *limiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// ValidateTuning rejects settings the resolver cannot run with, globally and
+// for each TLD override.
+func ValidateTuning(cfg *config.TldxConfigOptions) error {
+	global := config.Tuning{
+		MaxRetries:       &cfg.MaxRetries,
+		InitialBackoff:   &cfg.InitialBackoff,
+		MaxBackoff:       &cfg.MaxBackoff,
+		BackoffFactor:    &cfg.BackoffFactor,
+		ContextTimeout:   &cfg.ContextTimeout,
+		ConcurrencyLimit: &cfg.ConcurrencyLimit,
+	}
+	if err := validateTuning(global, cfg); err != nil {
+		return err
+	}
+
+	tlds := make([]string, 0, len(cfg.TLDTuning))
+	for tld := range cfg.TLDTuning {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+
+	for _, tld := range tlds {
+		if err := validateTuning(cfg.TLDTuning[tld], cfg); err != nil {
+			return fmt.Errorf(".%s: %w", tld, err)
+		}
+	}
+	return nil
+}
+
+func validateTuning(t config.Tuning, cfg *config.TldxConfigOptions) error {
+	var errs []error
+	if t.MaxRetries != nil && *t.MaxRetries < 0 {
+		errs = append(errs, errors.New("retries must not be negative"))
+	}
+	if t.InitialBackoff != nil && *t.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("initial_backoff must be positive"))
+	}
+	if t.MaxBackoff != nil && *t.MaxBackoff <= 0 {
+		errs = append(errs, errors.New("max_backoff must be positive"))
+	}
+	if t.BackoffFactor != nil && *t.BackoffFactor < 1 {
+		errs = append(errs, errors.New("backoff_factor must be at least 1"))
+	}
+	if t.ContextTimeout != nil && *t.ContextTimeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	if t.ConcurrencyLimit != nil && *t.ConcurrencyLimit <= 0 {
+		errs = append(errs, errors.New("concurrency must be positive"))
+	}
+
+	initial, maxBackoff := cfg.InitialBackoff, cfg.MaxBackoff
+	if t.InitialBackoff != nil {
+		initial = *t.InitialBackoff
+	}
+	if t.MaxBackoff != nil {
+		maxBackoff = *t.MaxBackoff
+	}
+	if initial > 0 && maxBackoff > 0 && maxBackoff < initial {
+		errs = append(errs, fmt.Errorf("max_backoff (%s) is shorter than initial_backoff (%s)", maxBackoff, initial))
+	}
+
+	return errors.Join(errs...)
+}
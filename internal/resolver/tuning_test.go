@@ -0,0 +1,220 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestPolicyFor_MostSpecificTLDOverride(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDTuning = map[string]config.Tuning{
+		"uk":    {MaxRetries: ptr(1)},
+		"co.uk": {ContextTimeout: ptr(40 * time.Second)},
+	}
+	s := &ResolverService{app: app}
+
+	p := s.policyFor("acme.co.uk")
+	if p.contextTimeout != 40*time.Second {
+		t.Errorf("expected the co.uk timeout, got %s", p.contextTimeout)
+	}
+	if p.maxRetries != app.Config.MaxRetries {
+		t.Errorf("co.uk sets no retries, so the global value applies; got %d", p.maxRetries)
+	}
+
+	if p := s.policyFor("acme.uk"); p.maxRetries != 1 || p.contextTimeout != app.Config.ContextTimeout {
+		t.Errorf("acme.uk: got %+v", p)
+	}
+	if p := s.policyFor("acme.com"); p.maxRetries != app.Config.MaxRetries {
+		t.Errorf("acme.com must use the globals, got %+v", p)
+	}
+}
+
+func TestLookupTimeout_UsesTheTLDTimeout(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.TLDTuning = map[string]config.Tuning{"de": {ContextTimeout: ptr(2 * time.Second)}}
+	s := &ResolverService{app: app}
+
+	if got := s.LookupTimeout("acme.de"); got != 2*time.Second {
+		t.Errorf("expected the .de timeout, got %s", got)
+	}
+	if got := s.LookupTimeout("acme.com"); got != app.Config.ContextTimeout {
+		t.Errorf("expected the global timeout for .com, got %s", got)
+	}
+}
+
+func TestWithRetry_UsesTheTLDRetries(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.InitialBackoff = time.Millisecond
+	app.Config.TLDTuning = map[string]config.Tuning{"de": {MaxRetries: ptr(0)}}
+	s := &ResolverService{app: app}
+
+	var calls int
+	_, err := s.withRetry(context.Background(), "acme.de", func() (CheckResult, error) {
+		calls++
+		return CheckResult{}, context.DeadlineExceeded
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single attempt for .de, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestLimiter_FixedLimit(t *testing.T) {
+	l := newLimiter(2, false)
+	ctx := context.Background()
+	l.acquire(ctx)
+	l.acquire(ctx)
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(short); err == nil {
+		t.Fatal("a third acquire must wait for a release")
+	}
+
+	for range 3 * adaptiveWindow {
+		l.release(true)
+		l.acquire(ctx)
+	}
+	if l.current() != 2 {
+		t.Errorf("failures must not move a fixed limit, got %d", l.current())
+	}
+}
+
+func TestLimiter_ReleaseWakesAWaiter(t *testing.T) {
+	l := newLimiter(1, false)
+	l.acquire(context.Background())
+
+	acquired := make(chan struct{})
+	go func() {
+		l.acquire(context.Background())
+		close(acquired)
+	}()
+
+	l.release(false)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the waiter was not woken by release")
+	}
+}
+
+func TestLimiter_AdaptsToFailures(t *testing.T) {
+	l := newLimiter(8, true)
+	run := func(failed bool) {
+		for range adaptiveWindow {
+			l.acquire(context.Background())
+			l.release(failed)
+		}
+	}
+
+	run(true)
+	if got := l.current(); got != 4 {
+		t.Fatalf("expected the limit halved to 4, got %d", got)
+	}
+	run(true)
+	run(true)
+	run(true)
+	if got := l.current(); got != 1 {
+		t.Fatalf("the limit must not drop below 1, got %d", got)
+	}
+
+	for range 10 {
+		run(false)
+	}
+	if got := l.current(); got != 8 {
+		t.Errorf("expected the limit to recover to the configured 8, got %d", got)
+	}
+}
+
+// probeBackend records how many checks run at once, per TLD.
+type probeBackend struct {
+	mu      sync.Mutex
+	current map[string]int
+	peak    map[string]int
+}
+
+func (p *probeBackend) Check(ctx context.Context, domain string) (CheckResult, error) {
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+
+	p.mu.Lock()
+	p.current[tld]++
+	p.peak[tld] = max(p.peak[tld], p.current[tld])
+	p.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.current[tld]--
+	p.mu.Unlock()
+	return CheckResult{Registered: true, Source: "probe"}, nil
+}
+
+func TestCheckDomainsStreaming_PerTLDConcurrency(t *testing.T) {
+	app := config.NewTldxContext()
+	app.Config.Backends = []string{"probe"}
+	app.Config.ConcurrencyLimit = 8
+	app.Config.TLDTuning = map[string]config.Tuning{"de": {ConcurrencyLimit: ptr(1)}}
+
+	probe := &probeBackend{current: map[string]int{}, peak: map[string]int{}}
+	s := NewResolverService(app, WithBackend("probe", probe))
+
+	var specs []DomainSpec
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		specs = append(specs, DomainSpec{Domain: name + ".de"}, DomainSpec{Domain: name + ".com"})
+	}
+
+	var got atomic.Int32
+	for range s.CheckDomainsStreaming(context.Background(), specs) {
+		got.Add(1)
+	}
+
+	if got.Load() != int32(len(specs)) {
+		t.Errorf("expected %d results, got %d", len(specs), got.Load())
+	}
+	if probe.peak["de"] != 1 {
+		t.Errorf("expected at most one .de check at a time, saw %d", probe.peak["de"])
+	}
+	if probe.peak["com"] < 2 {
+		t.Errorf("expected .com checks to run in parallel, saw %d", probe.peak["com"])
+	}
+}
+
+func TestValidateTuning(t *testing.T) {
+	valid := config.NewTldxContext().Config
+	valid.TLDTuning = map[string]config.Tuning{"de": {ConcurrencyLimit: ptr(2)}}
+	if err := ValidateTuning(valid); err != nil {
+		t.Errorf("defaults must validate, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*config.TldxConfigOptions)
+		want   string
+	}{
+		{"negative retries", func(c *config.TldxConfigOptions) { c.MaxRetries = -1 }, "retries"},
+		{"zero timeout", func(c *config.TldxConfigOptions) { c.ContextTimeout = 0 }, "timeout"},
+		{"zero concurrency", func(c *config.TldxConfigOptions) { c.ConcurrencyLimit = 0 }, "concurrency"},
+		{"shrinking backoff", func(c *config.TldxConfigOptions) { c.BackoffFactor = 0.5 }, "backoff_factor"},
+		{"max below initial", func(c *config.TldxConfigOptions) { c.MaxBackoff = time.Second }, "shorter than initial_backoff"},
+		{"tld override", func(c *config.TldxConfigOptions) {
+			c.TLDTuning = map[string]config.Tuning{"de": {InitialBackoff: ptr(10 * time.Second)}}
+		}, ".de: max_backoff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewTldxContext().Config
+			tt.modify(cfg)
+			err := ValidateTuning(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Confidence levels reported in --verify mode.
+const (
+	// ConfidenceConfirmed means at least two sources agree and none disagree.
+	ConfidenceConfirmed = "confirmed"
+	// ConfidenceLikely means only one source, or none, had a verdict.
+	ConfidenceLikely = "likely"
+	// ConfidenceConflicting means the sources disagree. The domain is reported
+	// as taken, since registering it may not be possible.
+	ConfidenceConflicting = "conflicting"
+)
+
+// SourceConsensus marks a verdict reached by --verify from several sources.
+const SourceConsensus = "consensus"
+
+// Per-source verdicts recorded in --verify mode.
+const (
+	VerdictRegistered = "registered"
+	VerdictAvailable  = "available"
+	VerdictNone       = "no-verdict"
+	VerdictError      = "error"
+)
+
+// VerifySources are queried in parallel for every domain in --verify mode.
+var VerifySources = []string{SourceRDAP, SourceDelegation, SourceWHOIS}
+
+// SourceVerdict is one source's answer in --verify mode.
+type SourceVerdict struct {
+	Source  string `json:"source"`
+	Verdict string `json:"verdict"`
+	Details string `json:"details,omitempty"`
+}
+
+// verifyDomain asks every source in VerifySources and combines their answers.
+// Unlike the backend chain, a WHOIS "not found" counts as a vote for
+// available. An undelegated name has no verdict, since a registered name can
+// be held without nameservers.
+func (s *ResolverService) verifyDomain(ctx context.Context, domain string) (CheckResult, error) {
+	verdicts := make([]SourceVerdict, len(VerifySources))
+	traceStep(ctx, "", TraceChain, "verify: "+strings.Join(VerifySources, " + ")+" in parallel", time.Time{}, nil)
+
+	var wg sync.WaitGroup
+	for i, source := range VerifySources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started := time.Now()
+			lookupCtx, endLookup := s.telemetry.startLookup(ctx, source, domain)
+			verdicts[i] = s.verifySource(lookupCtx, source, domain)
+			endLookup(verdicts[i].outcome())
+			traceStep(ctx, source, TraceVerdict, verdicts[i].Verdict+": "+verdicts[i].Details, started, nil)
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return CheckResult{}, ctx.Err()
+	}
+	result, err := consensus(verdicts)
+	traceStep(ctx, SourceConsensus, TraceVerdict, verdictDetail(result), time.Time{}, err)
+	return result, err
+}
+
+func (s *ResolverService) verifySource(ctx context.Context, source, domain string) SourceVerdict {
+	v := SourceVerdict{Source: source}
+
+	switch source {
+	case SourceRDAP:
+		result, err := rdapBackend{s}.Check(ctx, domain)
+		var noVerdict *NoVerdictError
+		switch {
+		case errors.As(err, &noVerdict):
+			v.Verdict, v.Details = VerdictNone, noVerdict.Reason
+		case err != nil:
+			v.Verdict, v.Details = VerdictError, err.Error()
+		default:
+			v.Verdict, v.Details = registeredVerdict(result.Registered), result.Details
+		}
+
+	case SourceDelegation:
+		delegated, err := s.checkDelegation(ctx, domain)
+		switch {
+		case err != nil:
+			v.Verdict, v.Details = VerdictError, err.Error()
+		case delegated:
+			v.Verdict, v.Details = VerdictRegistered, "Delegated by the parent zone"
+		default:
+			v.Verdict, v.Details = VerdictNone, "Not delegated in the parent zone"
+		}
+
+	case SourceWHOIS:
+		result, err := s.checkWhois(ctx, domain)
+		switch {
+		case err != nil:
+			v.Verdict, v.Details = VerdictError, err.Error()
+		case result.Source == "":
+			// No WHOIS server for the TLD.
+			v.Verdict, v.Details = VerdictNone, result.Details
+		default:
+			v.Verdict, v.Details = registeredVerdict(result.Registered), result.Details
+		}
+	}
+
+	return v
+}
+
+// outcome is the verdict as a lookup outcome for telemetry, with the error
+// it reported.
+func (v SourceVerdict) outcome() (string, error) {
+	switch v.Verdict {
+	case VerdictRegistered:
+		return outcomeRegistered, nil
+	case VerdictAvailable:
+		return outcomeAvailable, nil
+	case VerdictError:
+		return outcomeError, errors.New(v.Details)
+	}
+	return outcomeNoVerdict, nil
+}
+
+func registeredVerdict(registered bool) string {
+	if registered {
+		return VerdictRegistered
+	}
+	return VerdictAvailable
+}
+
+// consensus folds the per-source verdicts into one result. A disagreement is
+// reported as taken, because some source believes the name is registered.
+func consensus(verdicts []SourceVerdict) (CheckResult, error) {
+	result := CheckResult{Source: SourceConsensus, Verdicts: verdicts}
+
+	var registered, available, errored []string
+	for _, v := range verdicts {
+		switch v.Verdict {
+		case VerdictRegistered:
+			registered = append(registered, v.Source)
+		case VerdictAvailable:
+			available = append(available, v.Source)
+		case VerdictError:
+			errored = append(errored, fmt.Sprintf("%s: %s", v.Source, v.Details))
+		}
+	}
+
+	switch {
+	case len(registered) > 0 && len(available) > 0:
+		result.Registered = true
+		result.Confidence = ConfidenceConflicting
+		result.Details = fmt.Sprintf("Sources disagree: %s say registered, %s say available",
+			strings.Join(registered, ", "), strings.Join(available, ", "))
+	case len(registered)+len(available) == 0 && len(errored) > 0:
+		result.Details = "This domain has unknown status"
+		return result, fmt.Errorf("every source failed: %s", strings.Join(errored, "; "))
+	case len(registered)+len(available) >= 2:
+		result.Registered = len(registered) > 0
+		result.Confidence = ConfidenceConfirmed
+		result.Details = agreement(result.Registered, registered, available)
+	default:
+		result.Registered = len(registered) > 0
+		result.Confidence = ConfidenceLikely
+		if len(registered)+len(available) == 0 {
+			result.Details = "No source had a verdict (likely available)"
+		} else {
+			result.Details = agreement(result.Registered, registered, available)
+		}
+	}
+	return result, nil
+}
+
+func agreement(registered bool, registeredBy, availableBy []string) string {
+	if registered {
+		return "Registered according to " + strings.Join(registeredBy, ", ")
+	}
+	return "Available according to " + strings.Join(availableBy, ", ")
+}
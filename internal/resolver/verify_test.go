@@ -0,0 +1,122 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+)
+
+const whoisRegistered = `Domain Name: EXAMPLE.COM
+Registrar: Test Registrar
+Creation Date: 2020-01-01
+`
+
+func verifyService(rdapQuerier *mockRDAPQuerier, delegated bool, delegationErr error, whois string, whoisErr error) *resolver.ResolverService {
+	app := config.NewTldxContext()
+	app.Config.MaxRetries = 0
+	app.Config.Verify = true
+
+	return resolver.NewResolverService(app,
+		resolver.WithRDAPQuerier(rdapQuerier),
+		resolver.WithDelegationLookup(func(_ context.Context, _ string) (bool, error) {
+			return delegated, delegationErr
+		}),
+		resolver.WithWhoisFetcher(func(_ string, _ ...string) (string, error) {
+			return whois, whoisErr
+		}),
+	)
+}
+
+func verdictsOf(result resolver.CheckResult) string {
+	parts := make([]string, 0, len(result.Verdicts))
+	for _, v := range result.Verdicts {
+		parts = append(parts, v.Source+"="+v.Verdict)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestCheckDomain_Verify(t *testing.T) {
+	registered := &mockRDAPQuerier{resp: makeDomainRDAPResponse()}
+	notFound := &mockRDAPQuerier{err: fmt.Errorf("object does not exist.")}
+	noServer := &mockRDAPQuerier{err: fmt.Errorf("No RDAP servers found for domain")}
+
+	t.Run("agreeing sources confirm a taken domain", func(t *testing.T) {
+		s := verifyService(registered, true, nil, whoisRegistered, nil)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered || result.Confidence != resolver.ConfidenceConfirmed {
+			t.Errorf("Expected a confirmed taken verdict, got %+v", result)
+		}
+		if got := verdictsOf(result); got != "rdap=registered,dns-delegation=registered,whois=registered" {
+			t.Errorf("Unexpected verdicts: %s", got)
+		}
+		if result.Source != resolver.SourceConsensus {
+			t.Errorf("Expected source %q, got %q", resolver.SourceConsensus, result.Source)
+		}
+	})
+
+	t.Run("agreeing sources confirm an available domain", func(t *testing.T) {
+		s := verifyService(notFound, false, nil, "No match for domain", nil)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Registered || result.Confidence != resolver.ConfidenceConfirmed {
+			t.Errorf("Expected a confirmed available verdict, got %+v", result)
+		}
+		if got := verdictsOf(result); got != "rdap=available,dns-delegation=no-verdict,whois=available" {
+			t.Errorf("An undelegated name should have no verdict, got: %s", got)
+		}
+	})
+
+	t.Run("disagreement is flagged and reported as taken", func(t *testing.T) {
+		s := verifyService(registered, false, nil, "No match for domain", nil)
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered || result.Confidence != resolver.ConfidenceConflicting {
+			t.Errorf("Expected a conflicting taken verdict, got %+v", result)
+		}
+		if !strings.Contains(result.Details, "disagree") {
+			t.Errorf("Expected the disagreement in the details, got %q", result.Details)
+		}
+		if got := verdictsOf(result); got != "rdap=registered,dns-delegation=no-verdict,whois=available" {
+			t.Errorf("Unexpected verdicts: %s", got)
+		}
+	})
+
+	t.Run("a single answering source is only likely", func(t *testing.T) {
+		s := verifyService(noServer, true, nil, "", errors.New("connection refused"))
+
+		result, err := s.CheckDomain(context.Background(), "example.xyz")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Registered || result.Confidence != resolver.ConfidenceLikely {
+			t.Errorf("Expected a likely taken verdict, got %+v", result)
+		}
+	})
+
+	t.Run("every source failing is an error", func(t *testing.T) {
+		s := verifyService(&mockRDAPQuerier{err: errors.New("503")}, false, errors.New("i/o timeout"), "", errors.New("connection refused"))
+
+		result, err := s.CheckDomain(context.Background(), "example.com")
+		if err == nil {
+			t.Fatal("Expected an error when no source answered")
+		}
+		if result.Registered {
+			t.Error("A failed lookup must not be reported as registered")
+		}
+	})
+}
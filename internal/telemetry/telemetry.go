@@ -0,0 +1,132 @@
+// Package telemetry sets up where tldx sends its OpenTelemetry spans and
+// metrics: a Prometheus endpoint, an OTLP collector, or both.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// MetricsPath is where the Prometheus endpoint serves metrics.
+const MetricsPath = "/metrics"
+
+// endpointEnv turns OTLP exporting on without --otlp-endpoint.
+const endpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// Shutdown flushes what is buffered and stops the exporters and the metrics
+// endpoint.
+type Shutdown func(context.Context) error
+
+// Setup installs global tracer and meter providers that export as opts say,
+// for the resolver to report to. With nothing to export to it installs
+// nothing and returns a no-op Shutdown.
+func Setup(ctx context.Context, opts config.TelemetryOptions, version string) (Shutdown, error) {
+	otlp := opts.OTLPEndpoint != "" || os.Getenv(endpointEnv) != ""
+	if opts.MetricsAddr == "" && !otlp {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "tldx"),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: %w", err)
+	}
+
+	var shutdowns []func(context.Context) error
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			errs = append(errs, shutdowns[i](ctx))
+		}
+		return errors.Join(errs...)
+	}
+	fail := func(err error) (Shutdown, error) {
+		shutdown(ctx) //nolint:errcheck // already failing
+		return nil, fmt.Errorf("telemetry: %w", err)
+	}
+
+	meterOpts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	if opts.MetricsAddr != "" {
+		ln, err := net.Listen("tcp", opts.MetricsAddr)
+		if err != nil {
+			return fail(fmt.Errorf("metrics endpoint: %w", err))
+		}
+		reader, stop, err := serveMetrics(ln)
+		if err != nil {
+			ln.Close()
+			return fail(err)
+		}
+		shutdowns = append(shutdowns, stop)
+		meterOpts = append(meterOpts, sdkmetric.WithReader(reader))
+	}
+
+	if otlp {
+		var traceOpts []otlptracehttp.Option
+		var metricOpts []otlpmetrichttp.Option
+		if opts.OTLPEndpoint != "" {
+			traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(opts.OTLPEndpoint))
+			metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(opts.OTLPEndpoint))
+		}
+		spans, err := otlptracehttp.New(ctx, traceOpts...)
+		if err != nil {
+			return fail(err)
+		}
+		tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spans), sdktrace.WithResource(res))
+		shutdowns = append(shutdowns, tp.Shutdown)
+		otel.SetTracerProvider(tp)
+
+		metrics, err := otlpmetrichttp.New(ctx, metricOpts...)
+		if err != nil {
+			return fail(err)
+		}
+		meterOpts = append(meterOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metrics)))
+	}
+
+	mp := sdkmetric.NewMeterProvider(meterOpts...)
+	// The meter provider goes first on shutdown, so its last collection
+	// reaches the exporters before they stop.
+	shutdowns = append(shutdowns, mp.Shutdown)
+	otel.SetMeterProvider(mp)
+	return shutdown, nil
+}
+
+// serveMetrics serves a Prometheus endpoint on ln and returns the reader
+// that feeds it.
+func serveMetrics(ln net.Listener) (sdkmetric.Reader, func(context.Context) error, error) {
+	registry := prometheus.NewRegistry()
+	reader, err := otelprom.New(otelprom.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics endpoint stopped", "error", err)
+		}
+	}()
+	slog.Debug("Serving metrics", "url", "http://"+ln.Addr().String()+MetricsPath)
+	return reader, srv.Shutdown, nil
+}
@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/openrdap/rdap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+type notFound struct{}
+
+func (notFound) Do(*rdap.Request) (*rdap.Response, error) {
+	return nil, fmt.Errorf("object does not exist.")
+}
+
+func TestSetup_NothingConfigured(t *testing.T) {
+	t.Setenv(endpointEnv, "")
+	before := otel.GetMeterProvider()
+
+	shutdown, err := Setup(context.Background(), config.TelemetryOptions{}, "test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, before, otel.GetMeterProvider(), "no provider is installed")
+}
+
+func TestSetup_MetricsAddrInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	_, err = Setup(context.Background(), config.TelemetryOptions{MetricsAddr: ln.Addr().String()}, "test")
+	assert.ErrorContains(t, err, "metrics endpoint")
+}
+
+func TestServeMetrics(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	reader, stop, err := serveMetrics(ln)
+	require.NoError(t, err)
+	defer stop(context.Background()) //nolint:errcheck
+
+	app := config.NewTldxContext()
+	app.Config.MaxRetries = 0
+	s := resolver.NewResolverService(app,
+		resolver.WithRDAPQuerier(notFound{}),
+		resolver.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	_, err = s.CheckDomain(context.Background(), "example.com")
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + ln.Addr().String() + MetricsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `tldx_lookups_total{backend="rdap",otel_scope_name=`)
+	assert.Contains(t, string(body), `outcome="available",registry="com"`)
+	assert.Contains(t, string(body), "tldx_lookup_duration_seconds_bucket")
+}
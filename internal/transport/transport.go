@@ -0,0 +1,153 @@
+// Package transport builds the network clients every lookup shares, so proxy,
+// TLS and connection settings apply the same way to RDAP bootstrap, RDAP,
+// WHOIS and the other HTTP APIs.
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"golang.org/x/net/proxy"
+)
+
+// DefaultUserAgent is sent when no user_agent is configured.
+const DefaultUserAgent = "tldx (+https://github.com/brandonyoungdev/tldx)"
+
+const (
+	dialTimeout = 10 * time.Second
+	// pingTimeout bounds the HTTP/2 keepalive ping before the connection is
+	// dropped.
+	pingTimeout = 15 * time.Second
+)
+
+// NewHTTPClient returns a client honoring opts. Without an explicit proxy it
+// follows HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func NewHTTPClient(opts config.TransportOptions) (*http.Client, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyURL, err := parseProxy(opts.Proxy, "http", "https", "socks5", "socks5h")
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	} else {
+		t.Proxy = http.ProxyFromEnvironment
+	}
+
+	if opts.CAFile != "" {
+		pool, err := certPool(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	if opts.MaxIdleConns > 0 {
+		t.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.HTTP2Keepalive > 0 {
+		t.HTTP2 = &http.HTTP2Config{
+			SendPingTimeout: opts.HTTP2Keepalive,
+			PingTimeout:     pingTimeout,
+		}
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return &http.Client{Transport: &userAgentTransport{base: t, userAgent: userAgent}}, nil
+}
+
+// userAgentTransport sets the User-Agent on requests that have none.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewWhoisDialer returns the dialer for WHOIS's port-43 connections: the
+// configured SOCKS5 proxy, then a socks5:// --proxy, then ALL_PROXY from the
+// environment, else a direct connection. WHOIS is plain TCP, so an HTTP proxy
+// cannot carry it.
+func NewWhoisDialer(opts config.TransportOptions) (proxy.Dialer, error) {
+	direct := &net.Dialer{Timeout: dialTimeout}
+
+	spec := opts.WhoisProxy
+	if spec == "" && strings.HasPrefix(strings.ToLower(opts.Proxy), "socks5") {
+		spec = opts.Proxy
+	}
+	if spec == "" {
+		return proxy.FromEnvironmentUsing(direct), nil
+	}
+
+	proxyURL, err := parseProxy(spec, "socks5", "socks5h")
+	if err != nil {
+		return nil, fmt.Errorf("whois proxy: %w", err)
+	}
+	return proxy.FromURL(proxyURL, direct)
+}
+
+// Validate reports the first problem in opts.
+func Validate(opts config.TransportOptions) error {
+	if _, err := NewHTTPClient(opts); err != nil {
+		return err
+	}
+	_, err := NewWhoisDialer(opts)
+	return err
+}
+
+func parseProxy(spec string, schemes ...string) (*url.URL, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", spec, err)
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) && u.Host != "" {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid proxy %q: want %s://host:port", spec, strings.Join(schemes, ":// or "))
+}
+
+func certPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
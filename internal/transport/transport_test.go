@@ -0,0 +1,178 @@
+package transport_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient_UserAgent(t *testing.T) {
+	var got atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.UserAgent())
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := transport.NewHTTPClient(config.TransportOptions{})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, transport.DefaultUserAgent, got.Load())
+
+	client, err = transport.NewHTTPClient(config.TransportOptions{UserAgent: "acme-bot/1.0"})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "acme-bot/1.0", got.Load())
+}
+
+func TestNewHTTPClient_ExplicitProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy sees the absolute target URL.
+		proxied.Store(r.URL.String())
+		w.Write([]byte("via proxy"))
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := transport.NewHTTPClient(config.TransportOptions{Proxy: proxy.URL})
+	require.NoError(t, err)
+
+	resp, err := client.Get("http://rdap.example.invalid/domain/acme.com")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, "via proxy", string(body))
+	assert.Equal(t, "http://rdap.example.invalid/domain/acme.com", proxied.Load())
+}
+
+func TestNewHTTPClient_CAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	client, err := transport.NewHTTPClient(config.TransportOptions{})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	assert.Error(t, err, "a private CA must not be trusted by default")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, pemBytes, 0o600))
+
+	client, err = transport.NewHTTPClient(config.TransportOptions{CAFile: caFile})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	assert.NoError(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, transport.Validate(config.TransportOptions{}))
+	assert.NoError(t, transport.Validate(config.TransportOptions{Proxy: "socks5://127.0.0.1:1080"}))
+
+	assert.ErrorContains(t, transport.Validate(config.TransportOptions{Proxy: "ftp://proxy:21"}), "invalid proxy")
+	assert.ErrorContains(t, transport.Validate(config.TransportOptions{WhoisProxy: "http://proxy:3128"}), "whois proxy")
+	assert.ErrorContains(t, transport.Validate(config.TransportOptions{CAFile: "/nonexistent/ca.pem"}), "CA bundle")
+}
+
+// startSOCKS5 runs a minimal no-auth SOCKS5 proxy that counts CONNECTs.
+func startSOCKS5(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	var connects atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+
+				// Greeting: version, method count, methods.
+				header := make([]byte, 2)
+				io.ReadFull(r, header)
+				io.ReadFull(r, make([]byte, header[1]))
+				conn.Write([]byte{5, 0})
+
+				// Request: version, CONNECT, reserved, address type.
+				req := make([]byte, 4)
+				io.ReadFull(r, req)
+				var host string
+				switch req[3] {
+				case 1:
+					ip := make([]byte, 4)
+					io.ReadFull(r, ip)
+					host = net.IP(ip).String()
+				case 3:
+					n, _ := r.ReadByte()
+					name := make([]byte, n)
+					io.ReadFull(r, name)
+					host = string(name)
+				}
+				portBytes := make([]byte, 2)
+				io.ReadFull(r, portBytes)
+				port := binary.BigEndian.Uint16(portBytes)
+
+				target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+				if err != nil {
+					conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				connects.Add(1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+				go io.Copy(target, r)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+	return ln.Addr().String(), &connects
+}
+
+func TestNewWhoisDialer_SOCKS5(t *testing.T) {
+	whoisServer, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { whoisServer.Close() })
+	go func() {
+		conn, err := whoisServer.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("Domain Name: ACME.COM\r\n"))
+	}()
+
+	proxyAddr, connects := startSOCKS5(t)
+	dialer, err := transport.NewWhoisDialer(config.TransportOptions{WhoisProxy: "socks5://" + proxyAddr})
+	require.NoError(t, err)
+
+	conn, err := dialer.Dial("tcp", whoisServer.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.Write([]byte("acme.com\r\n"))
+	line, _ := bufio.NewReader(conn).ReadString('\n')
+	assert.Equal(t, "Domain Name: ACME.COM\r\n", line)
+	assert.Equal(t, int32(1), connects.Load())
+}
@@ -0,0 +1,516 @@
+// Package tui is the interactive mode behind --tui: a table of results that
+// fills in as lookups answer, with a filter, sorting, prefix/suffix/TLD
+// toggles that re-run the search, and a shortlist written out on exit in the
+// chosen --format.
+package tui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/composer"
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/domain"
+	"github.com/brandonyoungdev/tldx/internal/output"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Checker streams the results for specs until ctx ends, like
+// resolver.ResolverService.CheckDomainsStreaming.
+type Checker func(ctx context.Context, specs []resolver.DomainSpec) <-chan resolver.DomainResult
+
+// Run composes the domains for args, checks them in the TUI, and writes the
+// shortlist to stdout in the configured format once the user quits with q.
+// It reports whether the shortlist holds an available domain.
+func Run(ctx context.Context, app *config.TldxContext, args []string, opts ...resolver.ResolverOption) (bool, error) {
+	specs, warnings := composer.NewComposerService(app).Compile(args)
+
+	resolverService := resolver.NewResolverService(app, opts...)
+	defer resolverService.Close()
+
+	m := New(ctx, app, specs, resolverService.CheckDomainsStreaming)
+	for _, warning := range warnings {
+		m.warnings = append(m.warnings, warning.Error())
+	}
+
+	stats := output.NewStats(len(specs))
+	final, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	m.stop()
+	if err != nil {
+		return false, fmt.Errorf("tui: %w", err)
+	}
+	m = final.(*Model)
+	if !m.export {
+		return false, nil
+	}
+
+	m.countStats(stats)
+	outputWriter := output.GetOutputWriter(app, stats)
+	found := m.Export(outputWriter)
+	outputWriter.Flush()
+	output.WriteStats(app.Config, stats, os.Stdout, os.Stderr)
+	return found, nil
+}
+
+// Toggle kinds.
+const (
+	togglePrefix = "prefix"
+	toggleSuffix = "suffix"
+	toggleTLD    = "tld"
+)
+
+// toggle switches the domains built with one prefix, suffix or TLD on or off.
+type toggle struct {
+	kind, value string
+	on          bool
+}
+
+type pane int
+
+const (
+	paneTable pane = iota
+	paneToggles
+)
+
+// Model is the bubbletea model of the TUI.
+type Model struct {
+	ctx   context.Context
+	app   *config.TldxContext
+	check Checker
+
+	specs    []resolver.DomainSpec
+	byDomain map[string]resolver.DomainSpec
+	toggles  []toggle
+	warnings []string
+
+	// run numbers the current batch of lookups; results of an earlier one
+	// that was cancelled by a toggle are told apart by it.
+	run     int
+	cancel  context.CancelFunc
+	running bool
+
+	answers map[string]resolver.DomainResult
+	// arrived lists domains in the order their answers came in.
+	arrived []string
+	// favorites lists the shortlist in the order it was marked.
+	favorites []string
+
+	filter        string
+	filtering     bool
+	sortBy        sortKey
+	descending    bool
+	onlyAvailable bool
+	shortlistOnly bool
+
+	focus        pane
+	cursor       int
+	offset       int
+	toggleCursor int
+	width        int
+	height       int
+
+	styles styles
+	export bool
+}
+
+type resultMsg struct {
+	run    int
+	ch     <-chan resolver.DomainResult
+	result resolver.DomainResult
+}
+
+type runDoneMsg struct{ run int }
+
+// New returns the model for specs. Lookups start with Init.
+func New(ctx context.Context, app *config.TldxContext, specs []resolver.DomainSpec, check Checker) *Model {
+	m := &Model{
+		ctx:           ctx,
+		app:           app,
+		check:         check,
+		specs:         specs,
+		byDomain:      make(map[string]resolver.DomainSpec, len(specs)),
+		answers:       make(map[string]resolver.DomainResult),
+		onlyAvailable: app.Config.OnlyAvailable,
+		height:        24,
+		width:         100,
+		styles:        newStyles(output.NewStyleService(app).IsNoColor()),
+	}
+
+	seen := make(map[string]bool)
+	add := func(kind, value string) {
+		if value == "" || seen[kind+"\x00"+value] {
+			return
+		}
+		seen[kind+"\x00"+value] = true
+		m.toggles = append(m.toggles, toggle{kind: kind, value: value, on: true})
+	}
+	for _, prefix := range app.Config.Prefixes {
+		add(togglePrefix, prefix)
+	}
+	for _, suffix := range app.Config.Suffixes {
+		add(toggleSuffix, suffix)
+	}
+	for _, spec := range specs {
+		m.byDomain[spec.Domain] = spec
+		add(toggleTLD, spec.TLD)
+	}
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.start()
+}
+
+// start cancels any lookups under way and checks the enabled domains that
+// have no answer yet.
+func (m *Model) start() tea.Cmd {
+	m.stop()
+	m.run++
+
+	var pending []resolver.DomainSpec
+	for _, spec := range m.specs {
+		if _, ok := m.answers[spec.Domain]; !ok && m.enabled(spec) {
+			pending = append(pending, spec)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+	m.running = true
+	return wait(m.run, m.check(ctx, pending))
+}
+
+func (m *Model) stop() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.running = false
+}
+
+func wait(run int, ch <-chan resolver.DomainResult) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-ch
+		if !ok {
+			return runDoneMsg{run: run}
+		}
+		return resultMsg{run: run, ch: ch, result: result}
+	}
+}
+
+// enabled reports whether every toggle spec was built from is on.
+func (m *Model) enabled(spec resolver.DomainSpec) bool {
+	for _, t := range m.toggles {
+		if t.on {
+			continue
+		}
+		switch {
+		case t.kind == togglePrefix && spec.Prefix == t.value,
+			t.kind == toggleSuffix && spec.Suffix == t.value,
+			t.kind == toggleTLD && spec.TLD == t.value:
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case resultMsg:
+		stale := msg.run != m.run
+		// A cancelled run's last answers are kept, unless cancelling them
+		// is what made them fail.
+		if !stale || msg.result.Error == nil {
+			m.record(msg.result)
+		}
+		if !stale {
+			return m, wait(msg.run, msg.ch)
+		}
+	case runDoneMsg:
+		if msg.run == m.run {
+			m.running = false
+		}
+	case tea.KeyMsg:
+		return m, m.key(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) record(result resolver.DomainResult) {
+	if _, ok := m.answers[result.Domain]; !ok {
+		m.arrived = append(m.arrived, result.Domain)
+	}
+	m.answers[result.Domain] = result
+}
+
+func (m *Model) key(msg tea.KeyMsg) tea.Cmd {
+	if msg.Type == tea.KeyCtrlC {
+		return tea.Quit
+	}
+	if m.filtering {
+		m.filterKey(msg)
+		return nil
+	}
+	if m.focus == paneToggles {
+		return m.toggleKey(msg)
+	}
+
+	switch msg.String() {
+	case "q":
+		m.export = true
+		return tea.Quit
+	case "tab":
+		if len(m.toggles) > 0 {
+			m.focus = paneToggles
+		}
+	case "/":
+		m.filtering = true
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.pageSize())
+	case "pgdown":
+		m.move(m.pageSize())
+	case "home", "g":
+		m.move(-len(m.arrived))
+	case "end", "G":
+		m.move(len(m.arrived))
+	case " ", "f":
+		if rows := m.rows(); m.cursor < len(rows) {
+			m.toggleFavorite(rows[m.cursor].Domain)
+		}
+	case "s":
+		m.sortBy = (m.sortBy + 1) % sortKeys
+	case "S":
+		m.descending = !m.descending
+	case "a":
+		m.onlyAvailable = !m.onlyAvailable
+		m.move(0)
+	case "v":
+		m.shortlistOnly = !m.shortlistOnly
+		m.move(0)
+	case "r":
+		return m.retry()
+	}
+	return nil
+}
+
+func (m *Model) filterKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += strings.ToLower(string(msg.Runes))
+	}
+	m.move(0)
+}
+
+func (m *Model) toggleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q":
+		m.export = true
+		return tea.Quit
+	case "tab", "esc":
+		m.focus = paneTable
+	case "left", "up", "h", "k":
+		m.toggleCursor = max(m.toggleCursor-1, 0)
+	case "right", "down", "l", "j":
+		m.toggleCursor = min(m.toggleCursor+1, len(m.toggles)-1)
+	case " ", "enter":
+		m.toggles[m.toggleCursor].on = !m.toggles[m.toggleCursor].on
+		m.move(0)
+		return m.start()
+	}
+	return nil
+}
+
+// retry forgets the errored answers and checks those domains again.
+func (m *Model) retry() tea.Cmd {
+	kept := m.arrived[:0]
+	for _, d := range m.arrived {
+		if m.answers[d].Error != nil {
+			delete(m.answers, d)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	m.arrived = kept
+	m.move(0)
+	return m.start()
+}
+
+func (m *Model) toggleFavorite(d string) {
+	if i := slices.Index(m.favorites, d); i >= 0 {
+		m.favorites = slices.Delete(m.favorites, i, i+1)
+	} else {
+		m.favorites = append(m.favorites, d)
+	}
+	if m.shortlistOnly {
+		m.move(0)
+	}
+}
+
+func (m *Model) favorite(d string) bool {
+	return slices.Contains(m.favorites, d)
+}
+
+// move shifts the cursor by delta rows and keeps it on screen.
+func (m *Model) move(delta int) {
+	n := len(m.rows())
+	m.cursor = max(min(m.cursor+delta, n-1), 0)
+	page := m.pageSize()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+page {
+		m.offset = m.cursor - page + 1
+	}
+	m.offset = max(min(m.offset, n-page), 0)
+}
+
+// Progress is how many of the enabled domains have an answer.
+func (m *Model) Progress() (done, total int) {
+	for _, spec := range m.specs {
+		if !m.enabled(spec) {
+			continue
+		}
+		total++
+		if _, ok := m.answers[spec.Domain]; ok {
+			done++
+		}
+	}
+	return done, total
+}
+
+// Shortlist is the answers marked favorite, in the order they were marked.
+func (m *Model) Shortlist() []resolver.DomainResult {
+	out := make([]resolver.DomainResult, 0, len(m.favorites))
+	for _, d := range m.favorites {
+		out = append(out, m.answers[d])
+	}
+	return out
+}
+
+// Export writes the shortlist to w and reports whether it holds an available
+// domain. The caller flushes w.
+func (m *Model) Export(w output.ResultOutput) bool {
+	found := false
+	for _, result := range m.Shortlist() {
+		w.Write(result)
+		found = found || result.Available
+	}
+	return found
+}
+
+// countStats counts every answer into stats, for --show-stats.
+func (m *Model) countStats(stats *output.Stats) {
+	for _, d := range m.arrived {
+		stats.Add(m.answers[d])
+	}
+	stats.Finish()
+}
+
+// rows is the answers on show, filtered and sorted.
+func (m *Model) rows() []resolver.DomainResult {
+	rows := make([]resolver.DomainResult, 0, len(m.arrived))
+	for _, d := range m.arrived {
+		result := m.answers[d]
+		if spec, ok := m.byDomain[d]; ok && !m.enabled(spec) {
+			continue
+		}
+		if m.onlyAvailable && !(result.Available && domain.WithinMaxPrice(m.app.Config, result)) {
+			continue
+		}
+		if m.shortlistOnly && !m.favorite(d) {
+			continue
+		}
+		if m.filter != "" && !strings.Contains(d, m.filter) {
+			continue
+		}
+		rows = append(rows, result)
+	}
+
+	if m.sortBy != sortArrival {
+		slices.SortStableFunc(rows, func(a, b resolver.DomainResult) int {
+			return m.sortBy.compare(a, b)
+		})
+	}
+	if m.descending {
+		slices.Reverse(rows)
+	}
+	return rows
+}
+
+type sortKey int
+
+const (
+	sortArrival sortKey = iota
+	sortDomain
+	sortStatus
+	sortPrice
+	sortLength
+	sortKeys
+)
+
+func (k sortKey) String() string {
+	return [...]string{"arrival", "domain", "status", "price", "length"}[k]
+}
+
+func (k sortKey) compare(a, b resolver.DomainResult) int {
+	switch k {
+	case sortDomain:
+		return cmp.Compare(a.Domain, b.Domain)
+	case sortStatus:
+		return cmp.Or(cmp.Compare(statusRank(a), statusRank(b)), cmp.Compare(a.Domain, b.Domain))
+	case sortPrice:
+		// Unquoted domains go last.
+		pa, pb := a.Pricing, b.Pricing
+		switch {
+		case pa == nil && pb == nil:
+			return cmp.Compare(a.Domain, b.Domain)
+		case pa == nil:
+			return 1
+		case pb == nil:
+			return -1
+		}
+		return cmp.Or(cmp.Compare(pa.Registration, pb.Registration), cmp.Compare(a.Domain, b.Domain))
+	case sortLength:
+		return cmp.Or(cmp.Compare(len(a.Domain), len(b.Domain)), cmp.Compare(a.Domain, b.Domain))
+	}
+	return 0
+}
+
+// statusRank orders results best first: available, for sale, reserved,
+// taken, errored.
+func statusRank(result resolver.DomainResult) int {
+	switch {
+	case result.Error != nil:
+		return 4
+	case result.Available:
+		return 0
+	case result.ForSale != nil:
+		return 1
+	case result.Reserved != nil:
+		return 2
+	default:
+		return 3
+	}
+}
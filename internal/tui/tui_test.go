@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brandonyoungdev/tldx/internal/config"
+	"github.com/brandonyoungdev/tldx/internal/registrar"
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChecker answers every spec at once: the domains in available are
+// free, the rest taken. It records each batch it was asked for.
+type fakeChecker struct {
+	available map[string]bool
+	prices    map[string]float64
+	batches   [][]string
+}
+
+func (f *fakeChecker) check(_ context.Context, specs []resolver.DomainSpec) <-chan resolver.DomainResult {
+	ch := make(chan resolver.DomainResult, len(specs))
+	var batch []string
+	for _, spec := range specs {
+		batch = append(batch, spec.Domain)
+		result := resolver.DomainResult{
+			Domain:    spec.Domain,
+			Available: f.available[spec.Domain],
+			Keyword:   spec.Keyword,
+			Prefix:    spec.Prefix,
+			Suffix:    spec.Suffix,
+			TLD:       spec.TLD,
+		}
+		if price, ok := f.prices[spec.Domain]; ok {
+			result.Pricing = &registrar.Pricing{Currency: "USD", Registration: price}
+		}
+		ch <- result
+	}
+	close(ch)
+	f.batches = append(f.batches, batch)
+	return ch
+}
+
+func spec(keyword, prefix, tld string) resolver.DomainSpec {
+	return resolver.DomainSpec{Domain: prefix + keyword + "." + tld, Keyword: keyword, Prefix: prefix, TLD: tld}
+}
+
+func newTestModel(t *testing.T, f *fakeChecker) *Model {
+	t.Helper()
+	app := &config.TldxContext{Config: &config.TldxConfigOptions{Prefixes: []string{"get"}, NoColor: true}}
+	specs := []resolver.DomainSpec{
+		spec("stripe", "", "com"), spec("stripe", "", "io"),
+		spec("stripe", "get", "com"), spec("stripe", "get", "io"),
+	}
+	m := New(context.Background(), app, specs, f.check)
+	drain(m, m.Init())
+	return m
+}
+
+// drain runs cmd and everything it leads to.
+func drain(m *Model, cmd tea.Cmd) {
+	for cmd != nil {
+		_, cmd = m.Update(cmd())
+	}
+}
+
+func press(m *Model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "space":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func domains(rows []resolver.DomainResult) []string {
+	var out []string
+	for _, r := range rows {
+		out = append(out, r.Domain)
+	}
+	return out
+}
+
+func TestModel_ChecksEverythingAndShowsProgress(t *testing.T) {
+	f := &fakeChecker{available: map[string]bool{"getstripe.io": true}}
+	m := newTestModel(t, f)
+
+	done, total := m.Progress()
+	assert.Equal(t, 4, done)
+	assert.Equal(t, 4, total)
+	assert.False(t, m.running)
+	assert.Len(t, m.rows(), 4)
+
+	view := m.View()
+	assert.Contains(t, view, "4/4 done")
+	assert.Contains(t, view, "1 available")
+	assert.Contains(t, view, "getstripe.io")
+}
+
+func TestModel_FilterAsYouType(t *testing.T) {
+	m := newTestModel(t, &fakeChecker{})
+
+	press(m, "/", "g", "e", "t")
+	assert.True(t, m.filtering)
+	assert.Equal(t, []string{"getstripe.com", "getstripe.io"}, domains(m.rows()))
+
+	press(m, "enter")
+	assert.False(t, m.filtering)
+	assert.Len(t, m.rows(), 2, "enter keeps the filter")
+
+	press(m, "/", "esc")
+	assert.Len(t, m.rows(), 4, "esc clears it")
+}
+
+func TestModel_Sort(t *testing.T) {
+	f := &fakeChecker{
+		available: map[string]bool{"stripe.io": true, "getstripe.com": true},
+		prices:    map[string]float64{"stripe.io": 40, "getstripe.com": 12},
+	}
+	m := newTestModel(t, f)
+
+	press(m, "s")
+	assert.Equal(t, sortDomain, m.sortBy)
+	assert.Equal(t, []string{"getstripe.com", "getstripe.io", "stripe.com", "stripe.io"}, domains(m.rows()))
+
+	press(m, "s", "s")
+	assert.Equal(t, sortPrice, m.sortBy)
+	assert.Equal(t, []string{"getstripe.com", "stripe.io"}, domains(m.rows())[:2], "cheapest first, unquoted last")
+
+	press(m, "S")
+	assert.Equal(t, "getstripe.com", domains(m.rows())[3])
+}
+
+func TestModel_ToggleReruns(t *testing.T) {
+	f := &fakeChecker{}
+	app := &config.TldxContext{Config: &config.TldxConfigOptions{Prefixes: []string{"get"}, NoColor: true}}
+	specs := []resolver.DomainSpec{spec("stripe", "", "com"), spec("stripe", "get", "com"), spec("stripe", "", "io")}
+	m := New(context.Background(), app, specs, f.check)
+	require.Equal(t, []toggle{{togglePrefix, "get", true}, {toggleTLD, "com", true}, {toggleTLD, "io", true}}, m.toggles)
+
+	// Switch off the prefix before anything is checked.
+	m.focus = paneToggles
+	m.toggles[0].on = false
+	drain(m, m.Init())
+	assert.Equal(t, [][]string{{"stripe.com", "stripe.io"}}, f.batches)
+	assert.Equal(t, []string{"stripe.com", "stripe.io"}, domains(m.rows()))
+
+	// Switching it back on checks only what was never checked.
+	drain(m, press(m, "space"))
+	assert.Equal(t, []string{"getstripe.com"}, f.batches[1])
+	assert.Len(t, m.rows(), 3)
+
+	// Switching a TLD off hides it without a lookup.
+	press(m, "l", "l")
+	drain(m, press(m, "space"))
+	assert.Len(t, f.batches, 2)
+	assert.Equal(t, []string{"stripe.com", "getstripe.com"}, domains(m.rows()))
+	done, total := m.Progress()
+	assert.Equal(t, 2, done)
+	assert.Equal(t, 2, total)
+}
+
+func TestModel_ShortlistAndExport(t *testing.T) {
+	f := &fakeChecker{available: map[string]bool{"stripe.io": true}}
+	m := newTestModel(t, f)
+
+	press(m, "j", "space")  // stripe.io
+	press(m, "j", "j", "f") // getstripe.io
+	assert.Equal(t, []string{"stripe.io", "getstripe.io"}, m.favorites)
+
+	press(m, "v")
+	assert.Equal(t, []string{"stripe.io", "getstripe.io"}, domains(m.rows()))
+	assert.Contains(t, m.View(), "[shortlist]")
+
+	press(m, "f") // unmark the row under the cursor
+	assert.Len(t, m.favorites, 1)
+
+	w := &recordingOutput{}
+	assert.True(t, m.Export(w), "stripe.io is available")
+	assert.Equal(t, []string{"stripe.io"}, domains(w.results))
+
+	cmd := press(m, "q")
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	assert.True(t, m.export)
+}
+
+func TestModel_CtrlCQuitsWithoutExport(t *testing.T) {
+	m := newTestModel(t, &fakeChecker{})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	assert.False(t, m.export)
+}
+
+func TestModel_AvailableOnly(t *testing.T) {
+	f := &fakeChecker{available: map[string]bool{"stripe.io": true}}
+	m := newTestModel(t, f)
+
+	press(m, "a")
+	assert.Equal(t, []string{"stripe.io"}, domains(m.rows()))
+	assert.Contains(t, m.View(), "[available only]")
+}
+
+type recordingOutput struct {
+	results []resolver.DomainResult
+}
+
+func (o *recordingOutput) Write(result resolver.DomainResult) { o.results = append(o.results, result) }
+func (o *recordingOutput) Flush()                             {}
@@ -0,0 +1,229 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonyoungdev/tldx/internal/resolver"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type styles struct {
+	title, dim, cursor, selected lipgloss.Style
+	available, taken, forSale    lipgloss.Style
+	reserved, errored            lipgloss.Style
+}
+
+// newStyles uses the colors of the text output; with noColor every style is
+// plain and only the cursor marker shows the selection.
+func newStyles(noColor bool) styles {
+	if noColor {
+		return styles{}
+	}
+	color := func(c string) lipgloss.Style { return lipgloss.NewStyle().Foreground(lipgloss.Color(c)) }
+	return styles{
+		title:     color("14").Bold(true), // cyan
+		dim:       color("8"),
+		cursor:    lipgloss.NewStyle().Reverse(true),
+		selected:  color("11").Bold(true), // yellow
+		available: color("10"),            // green
+		taken:     color("9"),             // red
+		forSale:   color("13"),            // magenta
+		reserved:  color("208"),           // orange
+		errored:   color("11"),            // yellow
+	}
+}
+
+// headerLines and footerLines are the lines View draws around the rows.
+const (
+	headerLines = 4
+	footerLines = 3
+)
+
+func (m *Model) pageSize() int {
+	return max(m.height-headerLines-footerLines-len(m.warnings), 1)
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+	rows := m.rows()
+
+	b.WriteString(m.progressLine())
+	b.WriteByte('\n')
+	for _, warning := range m.warnings {
+		b.WriteString(m.styles.errored.Render(warning))
+		b.WriteByte('\n')
+	}
+	b.WriteString(m.viewLine())
+	b.WriteString("\n\n")
+
+	domainWidth := len("DOMAIN")
+	for _, row := range rows {
+		domainWidth = max(domainWidth, len(row.Domain))
+	}
+	domainWidth = min(domainWidth, 48)
+	b.WriteString(m.styles.dim.Render(fmt.Sprintf("    %-*s  %-10s  %-12s  %s", domainWidth, "DOMAIN", "STATUS", "PRICE", "SOURCE")))
+	b.WriteByte('\n')
+
+	page := m.pageSize()
+	end := min(m.offset+page, len(rows))
+	for i := m.offset; i < end; i++ {
+		b.WriteString(m.rowLine(rows[i], domainWidth, i == m.cursor && m.focus == paneTable))
+		b.WriteByte('\n')
+	}
+	for i := end - m.offset; i < page; i++ {
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(m.togglesLine())
+	b.WriteByte('\n')
+	b.WriteString(m.styles.dim.Render(m.help()))
+	// Long lines would wrap and push the table off screen.
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(b.String())
+}
+
+func (m *Model) progressLine() string {
+	done, total := m.Progress()
+	const barWidth = 24
+	filled := 0
+	percent := 100
+	if total > 0 {
+		filled = done * barWidth / total
+		percent = done * 100 / total
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	var available, taken, errored int
+	for _, d := range m.arrived {
+		switch result := m.answers[d]; {
+		case result.Error != nil:
+			errored++
+		case result.Available:
+			available++
+		default:
+			taken++
+		}
+	}
+
+	state := "done"
+	if m.running {
+		state = "checking"
+	}
+	return fmt.Sprintf("%s  %s %3d%%  %d/%d %s   %s  %s  %s  ★ %d",
+		m.styles.title.Render("tldx"), bar, percent, done, total, state,
+		m.styles.available.Render(fmt.Sprintf("%d available", available)),
+		m.styles.taken.Render(fmt.Sprintf("%d taken", taken)),
+		m.styles.errored.Render(fmt.Sprintf("%d errored", errored)),
+		len(m.favorites))
+}
+
+func (m *Model) viewLine() string {
+	filter := m.filter
+	if m.filtering {
+		filter += "▏"
+	}
+	direction := "↑"
+	if m.descending {
+		direction = "↓"
+	}
+	parts := []string{
+		fmt.Sprintf("Filter: %s", filter),
+		fmt.Sprintf("Sort: %s %s", m.sortBy, direction),
+	}
+	if m.onlyAvailable {
+		parts = append(parts, "[available only]")
+	}
+	if m.shortlistOnly {
+		parts = append(parts, "[shortlist]")
+	}
+	return strings.Join(parts, "   ")
+}
+
+func (m *Model) rowLine(result resolver.DomainResult, domainWidth int, current bool) string {
+	marker := "  "
+	if current {
+		marker = "> "
+	}
+	star := "  "
+	if m.favorite(result.Domain) {
+		star = m.styles.selected.Render("★") + " "
+	}
+
+	status, style := m.status(result)
+	price := ""
+	if result.Pricing != nil {
+		price = result.Pricing.String()
+	}
+	source := result.Source
+	if result.Error != nil {
+		source = result.Error.Error()
+	}
+
+	name := result.Domain
+	if len(name) > domainWidth {
+		name = name[:domainWidth-1] + "…"
+	}
+	line := fmt.Sprintf("%-*s  %s  %-12s  %s", domainWidth, name, style.Render(fmt.Sprintf("%-10s", status)), price, source)
+	if current {
+		line = m.styles.cursor.Render(line)
+	}
+	return marker + star + line
+}
+
+func (m *Model) status(result resolver.DomainResult) (string, lipgloss.Style) {
+	switch {
+	case result.Error != nil:
+		return "error", m.styles.errored
+	case result.Available:
+		return "available", m.styles.available
+	case result.ForSale != nil:
+		return "for sale", m.styles.forSale
+	case result.Reserved != nil:
+		return "reserved", m.styles.reserved
+	default:
+		return "taken", m.styles.taken
+	}
+}
+
+// togglesLine lists the toggles by kind, e.g. "Prefixes [x] get [ ] use".
+func (m *Model) togglesLine() string {
+	if len(m.toggles) == 0 {
+		return ""
+	}
+	labels := map[string]string{togglePrefix: "Prefixes", toggleSuffix: "Suffixes", toggleTLD: "TLDs"}
+
+	var b strings.Builder
+	kind := ""
+	for i, t := range m.toggles {
+		if t.kind != kind {
+			if kind != "" {
+				b.WriteString("  ")
+			}
+			kind = t.kind
+			b.WriteString(labels[kind])
+		}
+		box := "[ ]"
+		if t.on {
+			box = "[x]"
+		}
+		item := fmt.Sprintf("%s %s", box, t.value)
+		if m.focus == paneToggles && i == m.toggleCursor {
+			item = m.styles.cursor.Render(item)
+			if !m.styles.cursor.GetReverse() {
+				item = ">" + item
+			}
+		}
+		b.WriteString(" " + item)
+	}
+	return b.String()
+}
+
+func (m *Model) help() string {
+	switch {
+	case m.filtering:
+		return "type to filter · enter keep · esc clear"
+	case m.focus == paneToggles:
+		return "←/→ move · space toggle and re-run · tab back to results · q quit and export"
+	}
+	return "↑/↓ move · space favorite · / filter · s sort · S reverse · a available only · v shortlist · tab toggles · r retry errors · q quit and export · ctrl+c quit"
+}
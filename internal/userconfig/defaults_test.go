@@ -5,8 +5,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brandonyoungdev/tldx/internal/config"
 	"github.com/brandonyoungdev/tldx/internal/userconfig"
@@ -313,6 +315,62 @@ func TestApplyTo_ForSaleDefaults(t *testing.T) {
 	})
 }
 
+func TestApplyTo_DNSPrescreenDefault(t *testing.T) {
+	cfg := &config.TldxConfigOptions{}
+	userconfig.Defaults{DNSPrescreen: true}.ApplyTo(cfg, flagsSet())
+	if !cfg.DNSPrescreen {
+		t.Error("expected dns_prescreen to enable DNSPrescreen")
+	}
+
+	cfg = &config.TldxConfigOptions{}
+	userconfig.Defaults{DNSPrescreen: true}.ApplyTo(cfg, flagsSet("dns-prescreen"))
+	if cfg.DNSPrescreen {
+		t.Error("an explicit --dns-prescreen=false must win over the config file")
+	}
+}
+
+func TestApplyTo_VerifyDefault(t *testing.T) {
+	cfg := &config.TldxConfigOptions{}
+	userconfig.Defaults{Verify: true}.ApplyTo(cfg, flagsSet())
+	if !cfg.Verify {
+		t.Error("expected verify to enable Verify")
+	}
+
+	cfg = &config.TldxConfigOptions{}
+	userconfig.Defaults{Verify: true}.ApplyTo(cfg, flagsSet("verify"))
+	if cfg.Verify {
+		t.Error("an explicit --verify=false must win over the config file")
+	}
+}
+
+func TestApplyTo_DNSServerDefault(t *testing.T) {
+	cfg := &config.TldxConfigOptions{}
+	userconfig.Defaults{DNSServer: "tls://dns.quad9.net"}.ApplyTo(cfg, flagsSet())
+	if cfg.DNSServer != "tls://dns.quad9.net" {
+		t.Errorf("expected dns_server to be applied, got %q", cfg.DNSServer)
+	}
+
+	cfg = &config.TldxConfigOptions{DNSServer: "1.1.1.1"}
+	userconfig.Defaults{DNSServer: "tls://dns.quad9.net"}.ApplyTo(cfg, flagsSet("dns-server"))
+	if cfg.DNSServer != "1.1.1.1" {
+		t.Errorf("--dns-server must win over the config file, got %q", cfg.DNSServer)
+	}
+}
+
+func TestApplyTo_OrderDefault(t *testing.T) {
+	cfg := &config.TldxConfigOptions{}
+	userconfig.Defaults{Order: "score-first"}.ApplyTo(cfg, flagsSet())
+	if cfg.Order != "score-first" {
+		t.Errorf("expected order to be applied, got %q", cfg.Order)
+	}
+
+	cfg = &config.TldxConfigOptions{Order: "random"}
+	userconfig.Defaults{Order: "score-first"}.ApplyTo(cfg, flagsSet("order"))
+	if cfg.Order != "random" {
+		t.Errorf("--order must win over the config file, got %q", cfg.Order)
+	}
+}
+
 func TestApplyTo_CopiesSlicesDefensively(t *testing.T) {
 	d := userconfig.Defaults{TLDs: []string{"se"}, Prefixes: []string{"get"}, Suffixes: []string{"ly"}}
 
@@ -404,3 +462,238 @@ func withFakeUserConfigDir(t *testing.T) string {
 	t.Setenv("XDG_CONFIG_HOME", tmp)
 	return tmp
 }
+
+func TestLoad_ParsesTransport(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	content := `
+[transport]
+proxy = "http://proxy.corp.example:3128"
+whois_proxy = "socks5://proxy.corp.example:1080"
+user_agent = "acme-bot/1.0"
+max_conns_per_host = 4
+idle_conn_timeout = "90s"
+http2_keepalive = "30s"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := userconfig.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	opts := &config.TldxConfigOptions{}
+	cfg.Transport.ApplyTo(opts, flagsSet())
+
+	want := config.TransportOptions{
+		Proxy:           "http://proxy.corp.example:3128",
+		WhoisProxy:      "socks5://proxy.corp.example:1080",
+		UserAgent:       "acme-bot/1.0",
+		MaxConnsPerHost: 4,
+		IdleConnTimeout: 90 * time.Second,
+		HTTP2Keepalive:  30 * time.Second,
+	}
+	if !reflect.DeepEqual(opts.Transport, want) {
+		t.Errorf("transport: got %+v, want %+v", opts.Transport, want)
+	}
+}
+
+func TestTransportApplyTo_ProxyFlagWins(t *testing.T) {
+	opts := &config.TldxConfigOptions{Transport: config.TransportOptions{Proxy: "socks5://127.0.0.1:9050"}}
+	userconfig.Transport{Proxy: "http://proxy.corp.example:3128", UserAgent: "acme-bot/1.0"}.ApplyTo(opts, flagsSet("proxy"))
+
+	if opts.Transport.Proxy != "socks5://127.0.0.1:9050" {
+		t.Errorf("--proxy must win over the config file, got %q", opts.Transport.Proxy)
+	}
+	if opts.Transport.UserAgent != "acme-bot/1.0" {
+		t.Errorf("the rest of the section must still apply, got %+v", opts.Transport)
+	}
+}
+
+func TestLoad_ParsesResolver(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	content := `
+[resolver]
+retries = 5
+initial_backoff = "500ms"
+timeout = "20s"
+concurrency = 8
+adaptive_concurrency = true
+
+[resolver.tld.".DE"]
+concurrency = 2
+timeout = "45s"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := userconfig.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	opts := config.NewTldxContext().Config
+	cfg.Resolver.ApplyTo(opts, flagsSet())
+
+	if opts.MaxRetries != 5 || opts.InitialBackoff != 500*time.Millisecond || opts.ContextTimeout != 20*time.Second || opts.ConcurrencyLimit != 8 {
+		t.Errorf("global tuning not applied: %+v", opts)
+	}
+	if opts.MaxBackoff != 5*time.Second {
+		t.Errorf("unset max_backoff must keep the default, got %s", opts.MaxBackoff)
+	}
+	if !opts.AdaptiveConcurrency {
+		t.Error("expected adaptive_concurrency to be applied")
+	}
+
+	de, ok := opts.TLDTuning["de"]
+	if !ok {
+		t.Fatalf("expected a normalized .de override, got %v", opts.TLDTuning)
+	}
+	if de.ConcurrencyLimit == nil || *de.ConcurrencyLimit != 2 || de.ContextTimeout == nil || *de.ContextTimeout != 45*time.Second {
+		t.Errorf(".de override: got %+v", de)
+	}
+	if de.MaxRetries != nil {
+		t.Error("keys missing from the override must stay unset")
+	}
+}
+
+func TestResolverApplyTo_FlagsWin(t *testing.T) {
+	retries, timeout := 9, time.Minute
+	opts := config.NewTldxContext().Config
+	opts.MaxRetries = 1
+
+	userconfig.Resolver{
+		ResolverTuning: userconfig.ResolverTuning{Retries: &retries, Timeout: &timeout},
+	}.ApplyTo(opts, flagsSet("retries"))
+
+	if opts.MaxRetries != 1 {
+		t.Errorf("--retries must win, got %d", opts.MaxRetries)
+	}
+	if opts.ContextTimeout != time.Minute {
+		t.Errorf("timeout without a flag must apply, got %s", opts.ContextTimeout)
+	}
+}
+
+func TestLoad_ParsesReserved(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	content := `
+[reserved]
+files = ["/etc/tldx/reserved.txt"]
+builtin = false
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := userconfig.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	opts := config.NewTldxContext().Config
+	cfg.Reserved.ApplyTo(opts, flagsSet())
+
+	if !slices.Equal(opts.Reserved.Files, []string{"/etc/tldx/reserved.txt"}) || !opts.Reserved.SkipBuiltin || opts.Reserved.Disabled {
+		t.Errorf("reserved section not applied: %+v", opts.Reserved)
+	}
+}
+
+func TestReservedApplyTo_FlagsWin(t *testing.T) {
+	opts := config.NewTldxContext().Config
+	opts.Reserved.Files = []string{"mine.txt"}
+
+	userconfig.Reserved{Files: []string{"theirs.txt"}, Disabled: true}.ApplyTo(opts, flagsSet("reserved-list", "no-reserved"))
+
+	if !slices.Equal(opts.Reserved.Files, []string{"mine.txt"}) {
+		t.Errorf("--reserved-list must win, got %v", opts.Reserved.Files)
+	}
+	if opts.Reserved.Disabled {
+		t.Error("an explicit --no-reserved=false must win over disabled")
+	}
+}
+
+func TestLoad_ParsesZone(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	content := `
+[zone]
+dir = "/var/lib/tldx/zones"
+confirm = true
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := userconfig.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	opts := config.NewTldxContext().Config
+	cfg.Zone.ApplyTo(opts, flagsSet())
+	if opts.Zone.Dir != "/var/lib/tldx/zones" || !opts.Zone.ConfirmNegatives {
+		t.Errorf("zone section not applied: %+v", opts.Zone)
+	}
+
+	opts = config.NewTldxContext().Config
+	cfg.Zone.ApplyTo(opts, flagsSet("zone-confirm"))
+	if opts.Zone.ConfirmNegatives {
+		t.Error("an explicit --zone-confirm=false must win over confirm")
+	}
+}
+
+func TestLoad_ParsesPrefilter(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	content := `
+[prefilter]
+enabled = true
+mode = "skip"
+size = 5000000
+false_positive_rate = 0.001
+max_age = "168h"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := userconfig.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	opts := config.NewTldxContext().Config
+	cfg.Prefilter.ApplyTo(opts, flagsSet())
+	want := config.PrefilterOptions{Enabled: true, Mode: "skip", Size: 5000000, FalsePositiveRate: 0.001, MaxAge: 168 * time.Hour}
+	if opts.Prefilter != want {
+		t.Errorf("prefilter section not applied: got %+v, want %+v", opts.Prefilter, want)
+	}
+
+	opts = config.NewTldxContext().Config
+	cfg.Prefilter.ApplyTo(opts, flagsSet("prefilter", "prefilter-mode", "prefilter-size", "prefilter-max-age"))
+	if opts.Prefilter.Enabled || opts.Prefilter.Mode != "defer" || opts.Prefilter.Size != 1_000_000 || opts.Prefilter.MaxAge != 30*24*time.Hour {
+		t.Errorf("flags must win, got %+v", opts.Prefilter)
+	}
+}
+
+func TestApplyTo_PerTLDAndKeywordLimits(t *testing.T) {
+	three, five := 3, 5
+	d := userconfig.Defaults{LimitPerTLD: &three, LimitPerKeyword: &five}
+
+	cfg := &config.TldxConfigOptions{}
+	d.ApplyTo(cfg, flagsSet())
+	if cfg.LimitPerTLD != 3 || cfg.LimitPerKeyword != 5 {
+		t.Errorf("expected both limits to be applied, got %d and %d", cfg.LimitPerTLD, cfg.LimitPerKeyword)
+	}
+
+	cfg = &config.TldxConfigOptions{LimitPerTLD: 1}
+	d.ApplyTo(cfg, flagsSet("limit-per-tld"))
+	if cfg.LimitPerTLD != 1 || cfg.LimitPerKeyword != 5 {
+		t.Errorf("--limit-per-tld must win alone, got %d and %d", cfg.LimitPerTLD, cfg.LimitPerKeyword)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/brandonyoungdev/tldx/internal/config"
@@ -18,8 +20,16 @@ const ConfigFileName = "config.toml"
 const LegacyConfigFileName = "presets.toml"
 
 type UserConfig struct {
-	Defaults Defaults               `toml:"defaults"`
-	Presets  map[string]PresetEntry `toml:"presets"`
+	Defaults  Defaults               `toml:"defaults"`
+	Backends  Backends               `toml:"backends,omitempty"`
+	Registrar Registrar              `toml:"registrar,omitempty"`
+	EPP       EPP                    `toml:"epp,omitempty"`
+	Transport Transport              `toml:"transport,omitempty"`
+	Resolver  Resolver               `toml:"resolver,omitempty"`
+	Reserved  Reserved               `toml:"reserved,omitempty"`
+	Zone      Zone                   `toml:"zone,omitempty"`
+	Prefilter Prefilter              `toml:"prefilter,omitempty"`
+	Presets   map[string]PresetEntry `toml:"presets"`
 }
 
 // Defaults are applied to every run unless the matching flag is passed on the
@@ -30,17 +40,263 @@ type Defaults struct {
 	Prefixes  []string `toml:"prefixes,omitempty"`
 	Suffixes  []string `toml:"suffixes,omitempty"`
 	Format    string   `toml:"format,omitempty"`
+	Order     string   `toml:"order,omitempty"`
+	DNSServer string   `toml:"dns_server,omitempty"`
 	// Pointers because omitempty alone does not drop zero ints on save.
 	MaxDomainLength *int `toml:"max_domain_length,omitempty"`
 	Limit           *int `toml:"limit,omitempty"`
+	LimitPerTLD     *int `toml:"limit_per_tld,omitempty"`
+	LimitPerKeyword *int `toml:"limit_per_keyword,omitempty"`
 	OnlyAvailable   bool `toml:"only_available,omitempty"`
 	ForSale         bool `toml:"for_sale,omitempty"`
 	OnlyForSale     bool `toml:"only_for_sale,omitempty"`
-	ShowStats       bool `toml:"show_stats,omitempty"`
-	NoColor         bool `toml:"no_color,omitempty"`
-	Verbose         bool `toml:"verbose,omitempty"`
+	DNSPrescreen    bool `toml:"dns_prescreen,omitempty"`
+	Verify          bool `toml:"verify,omitempty"`
+	Pricing         bool `toml:"pricing,omitempty"`
+	// A pointer for the same reason as Limit.
+	MaxPrice         *float64 `toml:"max_price,omitempty"`
+	MaxPriceCurrency string   `toml:"max_price_currency,omitempty"`
+	ShowStats        bool     `toml:"show_stats,omitempty"`
+	NoColor          bool     `toml:"no_color,omitempty"`
+	Verbose          bool     `toml:"verbose,omitempty"`
 }
 
+// Backends maps a TLD to the availability backends tried for it, in order.
+// The "default" key covers every TLD without an entry of its own. TLD keys may
+// be written with or without the leading dot.
+type Backends map[string][]string
+
+// Registrar holds the credentials for the registrar pricing API. Which keys a
+// provider needs is listed in the config template.
+type Registrar struct {
+	Provider  string `toml:"provider,omitempty"`
+	APIKey    string `toml:"api_key,omitempty"`
+	APISecret string `toml:"api_secret,omitempty"`
+	Username  string `toml:"username,omitempty"`
+	Endpoint  string `toml:"endpoint,omitempty"`
+}
+
+func (r Registrar) ApplyTo(cfg *config.TldxConfigOptions) {
+	cfg.Registrar = config.RegistrarOptions{
+		Provider:  r.Provider,
+		APIKey:    r.APIKey,
+		APISecret: r.APISecret,
+		Username:  r.Username,
+		Endpoint:  r.Endpoint,
+	}
+}
+
+// EPP holds the registry connection for the epp backend. Registries usually
+// require the client certificate on top of the login.
+type EPP struct {
+	Server   string `toml:"server,omitempty"`
+	Username string `toml:"username,omitempty"`
+	Password string `toml:"password,omitempty"`
+	MaxCheck int    `toml:"max_check,omitempty"`
+	CertFile string `toml:"cert_file,omitempty"`
+	KeyFile  string `toml:"key_file,omitempty"`
+	CAFile   string `toml:"ca_file,omitempty"`
+}
+
+func (e EPP) ApplyTo(cfg *config.TldxConfigOptions) {
+	cfg.EPP = config.EPPOptions{
+		Server:   e.Server,
+		Username: e.Username,
+		Password: e.Password,
+		MaxCheck: e.MaxCheck,
+		CertFile: e.CertFile,
+		KeyFile:  e.KeyFile,
+		CAFile:   e.CAFile,
+	}
+}
+
+// Transport configures proxies, TLS and connection pooling for every lookup.
+// Durations are strings such as "90s".
+type Transport struct {
+	Proxy               string        `toml:"proxy,omitempty"`
+	WhoisProxy          string        `toml:"whois_proxy,omitempty"`
+	CAFile              string        `toml:"ca_file,omitempty"`
+	UserAgent           string        `toml:"user_agent,omitempty"`
+	MaxIdleConns        int           `toml:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int           `toml:"max_idle_conns_per_host,omitempty"`
+	MaxConnsPerHost     int           `toml:"max_conns_per_host,omitempty"`
+	IdleConnTimeout     time.Duration `toml:"idle_conn_timeout,omitempty"`
+	HTTP2Keepalive      time.Duration `toml:"http2_keepalive,omitempty"`
+}
+
+// ApplyTo copies the section into cfg. An explicit --proxy wins over proxy.
+func (t Transport) ApplyTo(cfg *config.TldxConfigOptions, isSet func(string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+
+	proxy := cfg.Transport.Proxy
+	if !isSet("proxy") && t.Proxy != "" {
+		proxy = t.Proxy
+	}
+
+	cfg.Transport = config.TransportOptions{
+		Proxy:               proxy,
+		WhoisProxy:          t.WhoisProxy,
+		CAFile:              t.CAFile,
+		UserAgent:           t.UserAgent,
+		MaxIdleConns:        t.MaxIdleConns,
+		MaxIdleConnsPerHost: t.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     t.MaxConnsPerHost,
+		IdleConnTimeout:     t.IdleConnTimeout,
+		HTTP2Keepalive:      t.HTTP2Keepalive,
+	}
+}
+
+// Resolver tunes retries, timeouts and parallelism. Durations are strings such
+// as "1.5s". TLD overrides only need the keys that differ.
+type Resolver struct {
+	ResolverTuning
+	AdaptiveConcurrency bool                      `toml:"adaptive_concurrency,omitempty"`
+	TLD                 map[string]ResolverTuning `toml:"tld,omitempty"`
+}
+
+// ResolverTuning uses pointers so that an explicit zero is told apart from an
+// unset key.
+type ResolverTuning struct {
+	Retries        *int           `toml:"retries,omitempty"`
+	InitialBackoff *time.Duration `toml:"initial_backoff,omitempty"`
+	MaxBackoff     *time.Duration `toml:"max_backoff,omitempty"`
+	BackoffFactor  *float64       `toml:"backoff_factor,omitempty"`
+	Timeout        *time.Duration `toml:"timeout,omitempty"`
+	Concurrency    *int           `toml:"concurrency,omitempty"`
+}
+
+// ApplyTo copies the section into cfg. Explicit flags win over the global
+// keys; per-TLD overrides have no flags and always apply.
+func (r Resolver) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+
+	if !isSet("retries") && r.Retries != nil {
+		cfg.MaxRetries = *r.Retries
+	}
+	if !isSet("initial-backoff") && r.InitialBackoff != nil {
+		cfg.InitialBackoff = *r.InitialBackoff
+	}
+	if !isSet("max-backoff") && r.MaxBackoff != nil {
+		cfg.MaxBackoff = *r.MaxBackoff
+	}
+	if !isSet("backoff-factor") && r.BackoffFactor != nil {
+		cfg.BackoffFactor = *r.BackoffFactor
+	}
+	if !isSet("timeout") && r.Timeout != nil {
+		cfg.ContextTimeout = *r.Timeout
+	}
+	if !isSet("concurrency") && r.Concurrency != nil {
+		cfg.ConcurrencyLimit = *r.Concurrency
+	}
+	if !isSet("adaptive-concurrency") && r.AdaptiveConcurrency {
+		cfg.AdaptiveConcurrency = true
+	}
+
+	for key, t := range r.TLD {
+		if cfg.TLDTuning == nil {
+			cfg.TLDTuning = make(map[string]config.Tuning)
+		}
+		cfg.TLDTuning[strings.ToLower(strings.TrimPrefix(key, "."))] = config.Tuning{
+			MaxRetries:       t.Retries,
+			InitialBackoff:   t.InitialBackoff,
+			MaxBackoff:       t.MaxBackoff,
+			BackoffFactor:    t.BackoffFactor,
+			ContextTimeout:   t.Timeout,
+			ConcurrencyLimit: t.Concurrency,
+		}
+	}
+}
+
+// Reserved configures the reserved-name lists checked when a lookup finds a
+// domain free. Files use the format of the built-in lists.
+type Reserved struct {
+	Files []string `toml:"files,omitempty"`
+	// A pointer so that an explicit false is told apart from an unset key.
+	Builtin  *bool `toml:"builtin,omitempty"`
+	Disabled bool  `toml:"disabled,omitempty"`
+}
+
+// ApplyTo copies the section into cfg. --reserved-list replaces files, and
+// --no-reserved wins over everything.
+func (r Reserved) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+
+	if !isSet("reserved-list") && len(r.Files) > 0 {
+		cfg.Reserved.Files = slices.Clone(r.Files)
+	}
+	if r.Builtin != nil && !*r.Builtin {
+		cfg.Reserved.SkipBuiltin = true
+	}
+	if !isSet("no-reserved") && r.Disabled {
+		cfg.Reserved.Disabled = true
+	}
+}
+
+// Zone configures the zone backend and where "tldx zone import" stores zones.
+type Zone struct {
+	Dir     string `toml:"dir,omitempty"`
+	Confirm bool   `toml:"confirm,omitempty"`
+}
+
+func (z Zone) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+	if z.Dir != "" {
+		cfg.Zone.Dir = z.Dir
+	}
+	if !isSet("zone-confirm") && z.Confirm {
+		cfg.Zone.ConfirmNegatives = true
+	}
+}
+
+// Prefilter configures the filter of known-taken domains shared across runs.
+type Prefilter struct {
+	Enabled bool   `toml:"enabled,omitempty"`
+	Path    string `toml:"path,omitempty"`
+	Mode    string `toml:"mode,omitempty"`
+	Size    int    `toml:"size,omitempty"`
+	// FalsePositiveRate is the share of unseen domains the filter may
+	// mistake for taken, e.g. 0.01.
+	FalsePositiveRate float64 `toml:"false_positive_rate,omitempty"`
+	// MaxAge is how long a filter is kept before starting afresh, e.g.
+	// "720h"; "0s" keeps it forever.
+	MaxAge *time.Duration `toml:"max_age,omitempty"`
+}
+
+func (p Prefilter) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+	if !isSet("prefilter") && p.Enabled {
+		cfg.Prefilter.Enabled = true
+	}
+	if p.Path != "" {
+		cfg.Prefilter.Path = p.Path
+	}
+	if !isSet("prefilter-mode") && p.Mode != "" {
+		cfg.Prefilter.Mode = p.Mode
+	}
+	if !isSet("prefilter-size") && p.Size != 0 {
+		cfg.Prefilter.Size = p.Size
+	}
+	if p.FalsePositiveRate != 0 {
+		cfg.Prefilter.FalsePositiveRate = p.FalsePositiveRate
+	}
+	if !isSet("prefilter-max-age") && p.MaxAge != nil {
+		cfg.Prefilter.MaxAge = *p.MaxAge
+	}
+}
+
+// DefaultBackendsKey names the chain used for TLDs without their own entry.
+const DefaultBackendsKey = "default"
+
 type PresetEntry struct {
 	TLDs []string `toml:"tlds"`
 }
@@ -150,6 +406,15 @@ func (d Defaults) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string)
 	if !isSet("limit") && d.Limit != nil {
 		cfg.Limit = *d.Limit
 	}
+	if !isSet("limit-per-tld") && d.LimitPerTLD != nil {
+		cfg.LimitPerTLD = *d.LimitPerTLD
+	}
+	if !isSet("limit-per-keyword") && d.LimitPerKeyword != nil {
+		cfg.LimitPerKeyword = *d.LimitPerKeyword
+	}
+	if !isSet("order") && d.Order != "" {
+		cfg.Order = d.Order
+	}
 	if !isSet("only-available") && d.OnlyAvailable {
 		cfg.OnlyAvailable = true
 	}
@@ -160,6 +425,25 @@ func (d Defaults) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string)
 	if !isSet("only-for-sale") && d.OnlyForSale {
 		cfg.OnlyForSale = true
 	}
+	if !isSet("dns-prescreen") && d.DNSPrescreen {
+		cfg.DNSPrescreen = true
+	}
+	if !isSet("verify") && d.Verify {
+		cfg.Verify = true
+	}
+	if !isSet("dns-server") && d.DNSServer != "" {
+		cfg.DNSServer = d.DNSServer
+	}
+	if !isSet("pricing") && d.Pricing {
+		cfg.CheckPricing = true
+	}
+	// max_price implies pricing; applied in the root command's PreRunE.
+	if !isSet("max-price") && d.MaxPrice != nil {
+		cfg.MaxPrice = *d.MaxPrice
+	}
+	if !isSet("max-price-currency") && d.MaxPriceCurrency != "" {
+		cfg.MaxPriceCurrency = d.MaxPriceCurrency
+	}
 	if !isSet("show-stats") && d.ShowStats {
 		cfg.ShowStats = true
 	}
@@ -170,3 +454,28 @@ func (d Defaults) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string)
 		cfg.Verbose = true
 	}
 }
+
+// ApplyTo copies the configured chains onto cfg. A --backend flag replaces
+// every configured chain, per-TLD ones included.
+func (b Backends) ApplyTo(cfg *config.TldxConfigOptions, isSet func(flag string) bool) {
+	if isSet == nil {
+		isSet = func(string) bool { return false }
+	}
+	if isSet("backend") || len(b) == 0 {
+		return
+	}
+
+	for key, chain := range b {
+		if len(chain) == 0 {
+			continue
+		}
+		if key == DefaultBackendsKey {
+			cfg.Backends = slices.Clone(chain)
+			continue
+		}
+		if cfg.TLDBackends == nil {
+			cfg.TLDBackends = make(map[string][]string)
+		}
+		cfg.TLDBackends[strings.ToLower(strings.TrimPrefix(key, "."))] = slices.Clone(chain)
+	}
+}
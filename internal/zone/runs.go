@@ -0,0 +1,165 @@
+package zone
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// runBytes bounds the labels an import holds in memory, string headers
+// included. Past it they are sorted and spilled to a run file, and the runs
+// are merged at the end, so a zone of any size imports in bounded memory.
+var runBytes = 64 << 20
+
+// sorter collects labels in any order and hands them back sorted.
+type sorter struct {
+	dir    string
+	labels []string
+	size   int
+	runs   []string
+}
+
+func newSorter(dir string) *sorter {
+	return &sorter{dir: dir}
+}
+
+func (s *sorter) add(label string) error {
+	s.labels = append(s.labels, label)
+	s.size += len(label) + 16
+	if s.size >= runBytes {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill writes the labels held in memory to a new run file, sorted and
+// without repeats.
+func (s *sorter) spill() error {
+	slices.Sort(s.labels)
+	s.labels = slices.Compact(s.labels)
+
+	f, err := os.CreateTemp(s.dir, ".run.*")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f.Name())
+	w := bufio.NewWriterSize(f, 1<<16)
+	for _, label := range s.labels {
+		w.WriteString(label)
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write run: %w", err)
+	}
+
+	clear(s.labels)
+	s.labels, s.size = s.labels[:0], 0
+	return nil
+}
+
+// each calls fn with every label in order. Repeats across runs are passed
+// on; the store writer drops them.
+func (s *sorter) each(fn func(label string) error) error {
+	if len(s.runs) == 0 {
+		slices.Sort(s.labels)
+		for _, label := range slices.Compact(s.labels) {
+			if err := fn(label); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(s.labels) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	return s.merge(fn)
+}
+
+// merge reads every run at once, always taking the least label next.
+func (s *sorter) merge(fn func(label string) error) error {
+	var h runHeap
+	for _, path := range s.runs {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r := &run{scanner: bufio.NewScanner(f)}
+		if err := r.next(); err != nil {
+			return err
+		}
+		if r.ok {
+			h = append(h, r)
+		}
+	}
+	heap.Init(&h)
+
+	for len(h) > 0 {
+		r := h[0]
+		if err := fn(r.label); err != nil {
+			return err
+		}
+		if err := r.next(); err != nil {
+			return err
+		}
+		if r.ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return nil
+}
+
+// cleanup deletes the run files.
+func (s *sorter) cleanup() error {
+	var err error
+	for _, path := range s.runs {
+		if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			err = errors.Join(err, removeErr)
+		}
+	}
+	s.runs = nil
+	return err
+}
+
+// run is one sorted run file being merged.
+type run struct {
+	scanner *bufio.Scanner
+	label   string
+	ok      bool
+}
+
+func (r *run) next() error {
+	r.ok = r.scanner.Scan()
+	if r.ok {
+		r.label = r.scanner.Text()
+		return nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return fmt.Errorf("read run: %w", err)
+	}
+	return nil
+}
+
+type runHeap []*run
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].label < h[j].label }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*run)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,267 @@
+package zone
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A store file holds the sorted labels of a zone, one per line, in blocks
+// compressed on their own. An index of each block's first label follows the
+// blocks, so a lookup decompresses one block and never the whole zone:
+//
+//	storeMagic
+//	block...             deflate of "label\n" lines, about blockSize each
+//	index                per block: uvarint size, uvarint names,
+//	                     uvarint len(first label), first label
+//	index offset         8 bytes, big-endian
+const storeMagic = "tldx zone 1\n"
+
+// blockSize is the uncompressed size a block is cut at.
+const blockSize = 64 << 10
+
+type block struct {
+	first  string
+	offset int64
+	size   int64
+	names  int
+}
+
+// storeWriter writes sorted labels as a store, dropping repeats.
+type storeWriter struct {
+	w      *bufio.Writer
+	offset int64
+	names  int
+	last   string
+
+	buf     bytes.Buffer
+	zbuf    bytes.Buffer
+	zw      *flate.Writer
+	current block
+	blocks  []block
+}
+
+func newStoreWriter(w io.Writer) (*storeWriter, error) {
+	zw, err := flate.NewWriter(nil, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	sw := &storeWriter{w: bufio.NewWriterSize(w, 1<<16), zw: zw}
+	sw.write([]byte(storeMagic))
+	return sw, nil
+}
+
+func (sw *storeWriter) write(p []byte) {
+	sw.w.Write(p)
+	sw.offset += int64(len(p))
+}
+
+// add appends label, which must not sort before the one added last.
+func (sw *storeWriter) add(label string) error {
+	if sw.names > 0 && label == sw.last {
+		return nil
+	}
+	if sw.buf.Len() > 0 && sw.buf.Len()+len(label)+1 > blockSize {
+		if err := sw.flushBlock(); err != nil {
+			return err
+		}
+	}
+	if sw.buf.Len() == 0 {
+		sw.current = block{first: label, offset: sw.offset}
+	}
+	sw.buf.WriteString(label)
+	sw.buf.WriteByte('\n')
+	sw.current.names++
+	sw.names++
+	sw.last = label
+	return nil
+}
+
+func (sw *storeWriter) flushBlock() error {
+	sw.zbuf.Reset()
+	sw.zw.Reset(&sw.zbuf)
+	if _, err := sw.zw.Write(sw.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := sw.zw.Close(); err != nil {
+		return err
+	}
+	sw.current.size = int64(sw.zbuf.Len())
+	sw.write(sw.zbuf.Bytes())
+	sw.blocks = append(sw.blocks, sw.current)
+	sw.buf.Reset()
+	return nil
+}
+
+// close writes the last block, the index and the trailer.
+func (sw *storeWriter) close() error {
+	if sw.buf.Len() > 0 {
+		if err := sw.flushBlock(); err != nil {
+			return err
+		}
+	}
+	indexOffset := sw.offset
+	var entry []byte
+	for _, b := range sw.blocks {
+		entry = binary.AppendUvarint(entry[:0], uint64(b.size))
+		entry = binary.AppendUvarint(entry, uint64(b.names))
+		entry = binary.AppendUvarint(entry, uint64(len(b.first)))
+		entry = append(entry, b.first...)
+		sw.write(entry)
+	}
+	sw.write(binary.BigEndian.AppendUint64(nil, uint64(indexOffset)))
+	return sw.w.Flush()
+}
+
+// Store answers lookups for one imported zone from its file on disk. Only
+// the block index is held in memory, a few bytes per thousand names. A
+// Store is safe for concurrent use; Close it when done.
+type Store struct {
+	Info
+	f      *os.File
+	blocks []block
+}
+
+// Open opens an imported zone. An error matching os.ErrNotExist means the
+// zone was never imported.
+func Open(dir, name string) (*Store, error) {
+	name = Normalize(name)
+	info, err := readInfo(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(storePath(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("zone: %w", err)
+	}
+	blocks, err := readIndex(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("zone: .%s: %w; import it again", name, err)
+	}
+
+	names := 0
+	for _, b := range blocks {
+		names += b.names
+	}
+	if names != info.Names {
+		f.Close()
+		return nil, fmt.Errorf("zone: .%s holds %d names, expected %d; import it again", name, names, info.Names)
+	}
+	return &Store{Info: info, f: f, blocks: blocks}, nil
+}
+
+func readIndex(f *os.File) ([]block, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := st.Size()
+	if size < int64(len(storeMagic))+8 {
+		return nil, fmt.Errorf("store is truncated")
+	}
+	magic := make([]byte, len(storeMagic))
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return nil, err
+	}
+	if string(magic) != storeMagic {
+		return nil, fmt.Errorf("not a zone store")
+	}
+
+	var trailer [8]byte
+	if _, err := f.ReadAt(trailer[:], size-8); err != nil {
+		return nil, err
+	}
+	indexOffset := int64(binary.BigEndian.Uint64(trailer[:]))
+	if indexOffset < int64(len(storeMagic)) || indexOffset > size-8 {
+		return nil, fmt.Errorf("index out of range")
+	}
+	index := make([]byte, size-8-indexOffset)
+	if _, err := f.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+
+	var blocks []block
+	offset := int64(len(storeMagic))
+	r := bytes.NewReader(index)
+	for r.Len() > 0 {
+		blockBytes, err1 := binary.ReadUvarint(r)
+		names, err2 := binary.ReadUvarint(r)
+		firstLen, err3 := binary.ReadUvarint(r)
+		if err1 != nil || err2 != nil || err3 != nil || firstLen > uint64(r.Len()) {
+			return nil, fmt.Errorf("index is corrupt")
+		}
+		first := make([]byte, firstLen)
+		r.Read(first)
+		blocks = append(blocks, block{first: string(first), offset: offset, size: int64(blockBytes), names: int(names)})
+		offset += int64(blockBytes)
+	}
+	if offset != indexOffset {
+		return nil, fmt.Errorf("index does not match the blocks")
+	}
+	return blocks, nil
+}
+
+// Delegated reports whether the zone delegated label, the part of a domain
+// left of the zone.
+func (s *Store) Delegated(label string) (bool, error) {
+	label = strings.ToLower(label)
+	// The last block starting at or before label is the only one that can
+	// hold it.
+	i := sort.Search(len(s.blocks), func(i int) bool { return s.blocks[i].first > label }) - 1
+	if i < 0 {
+		return false, nil
+	}
+
+	found := false
+	err := s.scan(s.blocks[i], func(l string) bool {
+		if l >= label {
+			found = l == label
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// Each calls fn with every delegated label, in order.
+func (s *Store) Each(fn func(label string)) error {
+	for _, b := range s.blocks {
+		err := s.scan(b, func(l string) bool {
+			fn(l)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan calls fn with the labels of b in order until fn returns false.
+func (s *Store) scan(b block, fn func(label string) bool) error {
+	zr := flate.NewReader(io.NewSectionReader(s.f, b.offset, b.size))
+	defer zr.Close()
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		if !fn(scanner.Text()) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("zone: .%s: %w", s.Zone, err)
+	}
+	return nil
+}
+
+// Close closes the store's file.
+func (s *Store) Close() error {
+	return s.f.Close()
+}
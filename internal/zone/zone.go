@@ -0,0 +1,347 @@
+// Package zone indexes the delegated names of a TLD zone file, such as a CZDS
+// download, into a compact local store. Looking a name up in the store tells
+// whether the TLD delegated it on the day of the snapshot, without a network
+// round trip.
+package zone
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info describes an imported zone.
+type Info struct {
+	Zone string `json:"zone"`
+	// Snapshot is when the zone file was generated, as far as it can be told.
+	Snapshot time.Time `json:"snapshot"`
+	Imported time.Time `json:"imported"`
+	Names    int       `json:"names"`
+	Source   string    `json:"source,omitempty"`
+}
+
+// SnapshotDate formats the snapshot as a day, the precision zone files are
+// published at.
+func (i Info) SnapshotDate() string {
+	return i.Snapshot.Format(time.DateOnly)
+}
+
+const (
+	namesExt = ".names"
+	infoExt  = ".json"
+)
+
+// DefaultDir is where zones are stored: $TLDX_ZONE_DIR, else a "zones"
+// directory in the user cache directory.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("TLDX_ZONE_DIR"); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("zone: cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(cache, "tldx", "zones"), nil
+}
+
+// Normalize lowercases a zone name and drops the dots around it.
+func Normalize(name string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(name), "."))
+}
+
+// ImportFile indexes the zone file at path, gzip-compressed or not, into dir.
+// A zero snapshot is taken from the SOA serial when it encodes a date, else
+// from the file's modification time. The file is read once and its names
+// sorted in bounded memory, spilling to dir as needed.
+func ImportFile(dir, name, path string, snapshot time.Time) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("zone: %w", err)
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return Info{}, fmt.Errorf("zone: %s: %w", path, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Info{}, fmt.Errorf("zone: %w", err)
+	}
+
+	name = Normalize(name)
+	labels := newSorter(dir)
+	defer labels.cleanup()
+	serial, err := parse(r, name, labels.add)
+	if err != nil {
+		return Info{}, fmt.Errorf("zone: %s: %w", path, err)
+	}
+
+	if snapshot.IsZero() {
+		snapshot = serialDate(serial)
+	}
+	if snapshot.IsZero() {
+		if st, err := f.Stat(); err == nil {
+			snapshot = st.ModTime()
+		}
+	}
+
+	info := Info{
+		Zone:     name,
+		Snapshot: snapshot.UTC(),
+		Imported: time.Now().UTC(),
+		Source:   filepath.Base(path),
+	}
+	// The names first, so a store is never described by an info file it
+	// does not match.
+	err = writeAtomic(storePath(dir, name), func(w io.Writer) error {
+		sw, err := newStoreWriter(w)
+		if err != nil {
+			return err
+		}
+		if err := labels.each(sw.add); err != nil {
+			return err
+		}
+		if sw.names == 0 {
+			return errNoDelegations
+		}
+		info.Names = sw.names
+		return sw.close()
+	})
+	if errors.Is(err, errNoDelegations) {
+		return Info{}, fmt.Errorf("zone: %s has no delegations under .%s", path, name)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	return info, writeInfo(dir, info)
+}
+
+var errNoDelegations = errors.New("no delegations")
+
+func storePath(dir, name string) string {
+	return filepath.Join(dir, name+namesExt)
+}
+
+// decompress reads gzip input transparently.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// parse passes the second-level labels with NS records under name to add,
+// in the order they appear and with repeats, and returns the SOA serial if
+// there is one on a single line.
+func parse(r io.Reader, name string, add func(label string) error) (serial string, err error) {
+	suffix := "." + name
+	origin := name
+	owner := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(fields[0], "$") {
+			if strings.EqualFold(fields[0], "$ORIGIN") && len(fields) > 1 {
+				origin = Normalize(fields[1])
+			}
+			continue
+		}
+
+		// A line starting with blanks belongs to the previous owner.
+		if line[0] != ' ' && line[0] != '\t' {
+			owner = absolute(fields[0], origin)
+			fields = fields[1:]
+		}
+
+		rrType, rdata := recordType(fields)
+		switch rrType {
+		case "ns":
+			if label, ok := strings.CutSuffix(owner, suffix); ok && label != "" && !strings.Contains(label, ".") {
+				if err := add(label); err != nil {
+					return "", err
+				}
+			}
+		case "soa":
+			if owner == name && len(rdata) >= 3 {
+				serial = rdata[2]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return serial, nil
+}
+
+func absolute(owner, origin string) string {
+	if owner == "@" {
+		return origin
+	}
+	if strings.HasSuffix(owner, ".") {
+		return Normalize(owner)
+	}
+	return Normalize(owner + "." + origin)
+}
+
+// recordType skips the optional TTL and class in front of the type.
+func recordType(fields []string) (string, []string) {
+	for i, f := range fields {
+		f = strings.ToLower(f)
+		if isTTL(f) {
+			continue
+		}
+		switch f {
+		case "in", "ch", "hs", "cs":
+			continue
+		}
+		return f, fields[i+1:]
+	}
+	return "", nil
+}
+
+// isTTL reports whether f is a TTL: seconds, or BIND's units such as 1h or
+// 1h30m. f is lowercase.
+func isTTL(f string) bool {
+	if f == "" || f[0] < '0' || f[0] > '9' {
+		return false
+	}
+	afterDigit := false
+	for i := 0; i < len(f); i++ {
+		switch c := f[i]; {
+		case c >= '0' && c <= '9':
+			afterDigit = true
+		case afterDigit && strings.IndexByte("smhdw", c) >= 0:
+			afterDigit = false
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// serialDate reads a YYYYMMDDnn serial, or one holding Unix seconds as some
+// registries use.
+func serialDate(serial string) time.Time {
+	if len(serial) == 10 {
+		if t, err := time.Parse("20060102", serial[:8]); err == nil && t.Year() >= 1990 {
+			return t
+		}
+	}
+	if secs, err := strconv.ParseInt(serial, 10, 64); err == nil {
+		t := time.Unix(secs, 0).UTC()
+		if t.Year() >= 2000 && t.Before(time.Now().AddDate(1, 0, 0)) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func writeInfo(dir string, info Info) error {
+	meta, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("zone: %w", err)
+	}
+	return writeAtomic(filepath.Join(dir, info.Zone+infoExt), func(w io.Writer) error {
+		_, err := w.Write(append(meta, '\n'))
+		return err
+	})
+}
+
+// writeAtomic replaces path with what write writes, or leaves it alone if
+// write fails.
+func writeAtomic(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("zone: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("zone: %w", err)
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("zone: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("zone: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("zone: %w", err)
+	}
+	return nil
+}
+
+// List describes the zones imported into dir, sorted by name.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zone: %w", err)
+	}
+
+	var out []Info
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), infoExt)
+		if !ok || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := readInfo(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Zone < out[j].Zone })
+	return out, nil
+}
+
+// Remove deletes an imported zone.
+func Remove(dir, name string) error {
+	name = Normalize(name)
+	if _, err := os.Stat(filepath.Join(dir, name+infoExt)); err != nil {
+		return fmt.Errorf("zone: .%s is not imported: %w", name, err)
+	}
+	for _, ext := range []string{infoExt, namesExt} {
+		if err := os.Remove(filepath.Join(dir, name+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("zone: %w", err)
+		}
+	}
+	return nil
+}
+
+func readInfo(dir, name string) (Info, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name+infoExt))
+	if err != nil {
+		return Info{}, fmt.Errorf("zone: %w", err)
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("zone: parse %s: %w", filepath.Join(dir, name+infoExt), err)
+	}
+	return info, nil
+}
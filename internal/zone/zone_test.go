@@ -0,0 +1,254 @@
+package zone
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleZone = `; a CZDS-style zone with a few quirks
+xyz.	900	in	soa	a.nic.xyz. hostmaster.xyz. 2026100103 900 1800 6048000 3600
+xyz.	172800	in	ns	a.nic.xyz.
+acme.xyz.	3600	in	ns	ns1.acme.net.
+acme.xyz.	3600	in	ns	ns2.acme.net.
+ns1.acme.xyz.	3600	in	a	192.0.2.1
+deep.sub.xyz.	3600	in	ns	x.example.
+$ORIGIN xyz.
+Stripe	3600	IN	NS	ns1.stripe.com.
+	3600	IN	NS	ns2.stripe.com.
+@	IN	NS	b.nic.xyz.
+other.com.	3600	in	ns	ns.other.com.
+`
+
+func writeZone(t *testing.T, name, content string, compress bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if !compress {
+		f.WriteString(content)
+		return path
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(content))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parseLabels parses zone under name and returns the labels it found, in
+// order and with repeats.
+func parseLabels(t *testing.T, zone, name string) ([]string, string) {
+	t.Helper()
+	var labels []string
+	serial, err := parse(strings.NewReader(zone), name, func(label string) error {
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return labels, serial
+}
+
+func TestParse(t *testing.T) {
+	labels, serial := parseLabels(t, sampleZone, "xyz")
+	if strings.Join(labels, ",") != "acme,acme,stripe,stripe" {
+		t.Errorf("expected the second-level delegations only, got %v", labels)
+	}
+	if serial != "2026100103" {
+		t.Errorf("expected the SOA serial, got %q", serial)
+	}
+}
+
+func TestParse_UnitTTLs(t *testing.T) {
+	zone := `$ORIGIN example.
+example.	1D	IN	SOA	a.nic.example. hostmaster.example. 2026100103 900 1800 6048000 3600
+acme 1h IN NS ns1.acme.net.
+stripe	1h30m	NS	ns1.stripe.com.
+hooli	2W	in	ns	ns1.hooli.com.
+`
+	labels, serial := parseLabels(t, zone, "example")
+	if strings.Join(labels, ",") != "acme,stripe,hooli" {
+		t.Errorf("expected delegations with unit TTLs to be kept, got %v", labels)
+	}
+	if serial != "2026100103" {
+		t.Errorf("expected the SOA serial, got %q", serial)
+	}
+}
+
+func TestIsTTL(t *testing.T) {
+	for f, want := range map[string]bool{
+		"3600": true, "1h": true, "1h30m": true, "2w": true, "0": true,
+		"": false, "h": false, "h1": false, "ns": false, "in": false, "1x": false, "1hm": false,
+	} {
+		if got := isTTL(f); got != want {
+			t.Errorf("isTTL(%q) = %v, want %v", f, got, want)
+		}
+	}
+}
+
+func TestImportThenOpen(t *testing.T) {
+	dir := t.TempDir()
+	for _, compress := range []bool{true, false} {
+		info, err := ImportFile(dir, ".XYZ", writeZone(t, "xyz.zone.gz", sampleZone, compress), time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Zone != "xyz" || info.Names != 2 || info.SnapshotDate() != "2026-10-01" {
+			t.Errorf("compress=%v: unexpected info %+v", compress, info)
+		}
+	}
+
+	store, err := Open(dir, "xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for label, want := range map[string]bool{"acme": true, "STRIPE": true, "foo": false, "a": false, "zzz": false, "sub": false} {
+		if got, err := store.Delegated(label); err != nil || got != want {
+			t.Errorf("Delegated(%q) = %v, %v, want %v", label, got, err, want)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := List(dir)
+	if err != nil || len(infos) != 1 || infos[0].Zone != "xyz" || infos[0].Source != "xyz.zone.gz" {
+		t.Errorf("List: got %+v, %v", infos, err)
+	}
+
+	if err := Remove(dir, "xyz"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "xyz"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a removed zone to be missing, got %v", err)
+	}
+	if err := Remove(dir, "xyz"); err == nil {
+		t.Error("removing a zone twice must fail")
+	}
+}
+
+func TestImport_SnapshotDate(t *testing.T) {
+	explicit := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	info, err := ImportFile(t.TempDir(), "xyz", writeZone(t, "xyz.zone", sampleZone, false), explicit)
+	if err != nil || !info.Snapshot.Equal(explicit) {
+		t.Errorf("an explicit date must win, got %+v, %v", info, err)
+	}
+
+	// Verisign serials are Unix seconds.
+	unix := strings.Replace(sampleZone, "2026100103", "1760000000", 1)
+	info, err = ImportFile(t.TempDir(), "xyz", writeZone(t, "xyz.zone", unix, false), time.Time{})
+	if err != nil || info.SnapshotDate() != "2025-10-09" {
+		t.Errorf("expected the date of the Unix serial, got %+v, %v", info, err)
+	}
+
+	plain := strings.Replace(sampleZone, "2026100103", "42", 1)
+	path := writeZone(t, "xyz.zone", plain, false)
+	mtime := time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	info, err = ImportFile(t.TempDir(), "xyz", path, time.Time{})
+	if err != nil || info.SnapshotDate() != "2024-12-24" {
+		t.Errorf("expected the file's modification date, got %+v, %v", info, err)
+	}
+}
+
+func TestImport_NoDelegations(t *testing.T) {
+	_, err := ImportFile(t.TempDir(), "net", writeZone(t, "xyz.zone", sampleZone, false), time.Time{})
+	if err == nil || !strings.Contains(err.Error(), "no delegations under .net") {
+		t.Errorf("expected an error for the wrong TLD, got %v", err)
+	}
+}
+
+// TestImport_LargeZone sorts in several runs and stores many blocks, as a
+// .com-sized zone would.
+func TestImport_LargeZone(t *testing.T) {
+	defer func(size int) { runBytes = size }(runBytes)
+	runBytes = 64 << 10
+
+	const n = 30000
+	var zone strings.Builder
+	zone.WriteString("$ORIGIN xyz.\n")
+	// Out of order and with repeats, which every run must merge away.
+	for i := range n {
+		label := fmt.Sprintf("name%05d", (i*7919)%n)
+		fmt.Fprintf(&zone, "%s 1h IN NS ns1.example.\n%s 1h IN NS ns2.example.\n", label, label)
+	}
+
+	dir := t.TempDir()
+	info, err := ImportFile(dir, "xyz", writeZone(t, "xyz.zone.gz", zone.String(), true), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Names != n {
+		t.Errorf("expected %d names, got %d", n, info.Names)
+	}
+
+	store, err := Open(dir, "xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if len(store.blocks) < 2 {
+		t.Errorf("expected the names in several blocks, got %d", len(store.blocks))
+	}
+	for _, label := range []string{"name00000", "name12345", "name29999"} {
+		if ok, err := store.Delegated(label); err != nil || !ok {
+			t.Errorf("Delegated(%q) = %v, %v, want true", label, ok, err)
+		}
+	}
+	for _, label := range []string{"name", "name000000", "name30000", "a", "zzz"} {
+		if ok, err := store.Delegated(label); err != nil || ok {
+			t.Errorf("Delegated(%q) = %v, %v, want false", label, ok, err)
+		}
+	}
+
+	var labels []string
+	if err := store.Each(func(label string) { labels = append(labels, label) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != n || !slices.IsSorted(labels) {
+		t.Errorf("expected %d sorted labels, got %d", n, len(labels))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected only the store and its info to be left, got %v", entries)
+	}
+}
+
+func TestOpen_DetectsACorruptStore(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ImportFile(dir, "xyz", writeZone(t, "xyz.zone", sampleZone, false), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xyz.json"), []byte(`{"zone":"xyz","names":5}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "xyz"); err == nil || !strings.Contains(err.Error(), "import it again") {
+		t.Errorf("expected a count mismatch, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "xyz"+namesExt), []byte("acme\nstripe\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "xyz"); err == nil || !strings.Contains(err.Error(), "import it again") {
+		t.Errorf("expected a store in another format to be rejected, got %v", err)
+	}
+}